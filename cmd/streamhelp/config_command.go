@@ -0,0 +1,30 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func newConfigCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the configuration",
+	}
+
+	command.AddCommand(&cobra.Command{
+		Use:   "print",
+		Short: "Print the effective configuration with secrets masked",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r := loadConfig()
+
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(r.Config.Masked())
+		},
+	})
+
+	return command
+}
@@ -1,27 +1,35 @@
 package main
 
 import (
-	"fmt"
-	"streamhelper-backend/internal/config"
+	"os"
+
+	"github.com/spf13/cobra"
 )
 
 func main() {
-	viperConfig := config.NewViper()
-	log := config.NewLogger(viperConfig)
-	db := config.NewDatabase(viperConfig, log)
-	validate := config.NewValidator(viperConfig)
-	app := config.NewFiber(viperConfig)
-	config.Bootstrap(&config.BootstrapConfig{
-		DB: db,
-		App: app,
-		Log: log,
-		Validate: validate,
-		Config: viperConfig,
-	})
-	webPort := viperConfig.GetInt("web.port")
-	err := app.Listen(fmt.Sprintf(":%d", webPort))
-	if err != nil {
-		log.Fatalf("Failed to start server :%v", err)
+	root := &cobra.Command{
+		Use:           "streamhelp",
+		Short:         "StreamHelp backend server and operations tool",
+		SilenceUsage:  true,
+		SilenceErrors: false,
+		// running the binary without a command keeps starting the server
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServe()
+		},
 	}
 
-}
\ No newline at end of file
+	root.AddCommand(
+		newServeCommand(),
+		newMigrateCommand(),
+		newSeedCommand(),
+		newUserCommand(),
+		newTokenCommand(),
+		newPaymentCommand(),
+		newConfigCommand(),
+		newWorkerCommand(),
+	)
+
+	if err := root.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"streamhelper-backend/internal/config"
+	"streamhelper-backend/internal/migration"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const migrationsDir = "db/migrations"
+
+func newMigrateCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "migrate",
+		Short: "Manage the database schema",
+	}
+
+	command.AddCommand(
+		&cobra.Command{
+			Use:   "up [N]",
+			Short: "Apply all or the next N pending migrations",
+			Args:  cobra.MaximumNArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				steps, err := parseSteps(args)
+				if err != nil {
+					return err
+				}
+				r := loadConfig().connect()
+				defer r.close()
+
+				applied, err := config.NewMigrator(r.DB, r.Log).Up(cmd.Context(), steps)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Applied %d migration(s)\n", applied)
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:   "down [N]",
+			Short: "Roll back the last N migrations (default 1)",
+			Args:  cobra.MaximumNArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				steps, err := parseSteps(args)
+				if err != nil {
+					return err
+				}
+				r := loadConfig().connect()
+				defer r.close()
+
+				rolledBack, err := config.NewMigrator(r.DB, r.Log).Down(cmd.Context(), steps)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Rolled back %d migration(s)\n", rolledBack)
+				return nil
+			},
+		},
+		&cobra.Command{
+			Use:   "status",
+			Short: "List migrations and whether they are applied",
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				r := loadConfig().connect()
+				defer r.close()
+
+				statuses, err := config.NewMigrator(r.DB, r.Log).Status(cmd.Context())
+				if err != nil {
+					return err
+				}
+
+				writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+				fmt.Fprintln(writer, "VERSION\tNAME\tAPPLIED AT")
+				for _, status := range statuses {
+					appliedAt := "pending"
+					if status.Applied {
+						appliedAt = time.UnixMilli(status.AppliedAt).Format(time.RFC3339)
+					}
+					fmt.Fprintf(writer, "%06d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+				}
+				return writer.Flush()
+			},
+		},
+		&cobra.Command{
+			Use:   "create <name>",
+			Short: "Create an empty up/down migration pair in " + migrationsDir,
+			Args:  cobra.ExactArgs(1),
+			// only touches the source tree, it needs no database
+			RunE: func(cmd *cobra.Command, args []string) error {
+				files, err := migration.Create(migrationsDir, args[0])
+				if err != nil {
+					return err
+				}
+				for _, file := range files {
+					fmt.Println(file)
+				}
+				return nil
+			},
+		},
+	)
+
+	return command
+}
+
+func parseSteps(args []string) (int, error) {
+	if len(args) == 0 {
+		return 0, nil
+	}
+
+	steps, err := strconv.Atoi(args[0])
+	if err != nil || steps < 1 {
+		return 0, fmt.Errorf("invalid number of steps %q", args[0])
+	}
+	return steps, nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"streamhelper-backend/internal/config"
+	"streamhelper-backend/internal/payment"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+func newPaymentCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "payment",
+		Short: "Work with the payment provider",
+	}
+
+	var status, serverURL string
+	simulate := &cobra.Command{
+		Use:   "simulate <donation-id>",
+		Short: "Send a signed simulator callback for a donation to a running server",
+		Long: "Moves the simulated charge of a donation to --status and posts the callback the\n" +
+			"provider would send to the server at --url. Requires payment.provider \"simulator\".",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r := loadConfig()
+			if r.Config.Payment.Provider != "simulator" {
+				return fmt.Errorf("payment.provider is %q, simulate only works with the simulator", r.Config.Payment.Provider)
+			}
+			if serverURL == "" {
+				serverURL = fmt.Sprintf("http://localhost:%d", r.Config.Web.Port)
+			}
+
+			redisClient := config.NewRedis(r.Config)
+			defer redisClient.Close()
+			simulator := config.NewPaymentSimulator(r.Config, redisClient, r.Log)
+
+			body, signature, err := simulator.Notify(cmd.Context(), args[0], status)
+			if err != nil {
+				return err
+			}
+
+			endpoint := strings.TrimSuffix(serverURL, "/") + "/api/payments/" + simulator.Name() + "/notifications"
+			request, err := http.NewRequestWithContext(cmd.Context(), http.MethodPost, endpoint, bytes.NewReader(body))
+			if err != nil {
+				return err
+			}
+			request.Header.Set("Content-Type", "application/json")
+			request.Header.Set(payment.SimulatorSignatureHeader, signature)
+
+			response, err := http.DefaultClient.Do(request)
+			if err != nil {
+				return err
+			}
+			defer response.Body.Close()
+
+			if response.StatusCode != http.StatusOK {
+				payload, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+				return fmt.Errorf("server rejected the callback with %s: %s", response.Status, payload)
+			}
+
+			fmt.Printf("Donation %s is now %s\n", args[0], status)
+			return nil
+		},
+	}
+	simulate.Flags().StringVar(&status, "status", payment.StatusPaid, "status to simulate: paid, failed, expired or refunded")
+	simulate.Flags().StringVar(&serverURL, "url", "", "base URL of the server, defaults to localhost on web.port")
+
+	command.AddCommand(simulate)
+
+	return command
+}
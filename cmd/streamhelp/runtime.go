@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"streamhelper-backend/internal/config"
+	"streamhelper-backend/internal/i18n"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+// runtime is the wiring every command shares: the same config loading, the
+// same connections and the same Bootstrap as the HTTP server.
+type runtime struct {
+	Viper      *viper.Viper
+	Validate   *validator.Validate
+	Config     *config.AppConfig
+	Log        *logrus.Logger
+	DB         *gorm.DB
+	Redis      *redis.Client
+	Translator *i18n.Translator
+	App        *fiber.App
+	Lifecycle  *config.Lifecycle
+}
+
+// loadConfig reads and validates config.json without opening connections.
+func loadConfig() *runtime {
+	viperConfig := config.NewViper()
+	validate := config.NewValidator(viperConfig)
+	appConfig := config.NewAppConfig(viperConfig, validate)
+
+	return &runtime{
+		Viper:    viperConfig,
+		Validate: validate,
+		Config:   appConfig,
+		Log:      config.NewLogger(appConfig),
+	}
+}
+
+// connect opens the database and Redis and registers them with the
+// lifecycle, already started, so close releases them in order.
+func (r *runtime) connect() *runtime {
+	r.DB = config.NewDatabase(r.Config, r.Log)
+	r.Redis = config.NewRedis(r.Config)
+	r.Lifecycle = config.NewLifecycle(r.Log)
+	r.Lifecycle.Append(config.DatabaseHook(r.DB))
+	r.Lifecycle.Append(config.RedisHook(r.Redis))
+	if err := r.Lifecycle.Start(context.Background()); err != nil {
+		r.Log.Fatalf("Failed to start connections : %v", err)
+	}
+
+	return r
+}
+
+func (r *runtime) bootstrap(reloader *config.ConfigReloader, runWorkers bool) *config.Application {
+	if r.Translator == nil {
+		r.Translator = config.NewTranslator(r.Config, r.Validate, r.Log)
+	}
+	if r.App == nil {
+		r.App = config.NewFiber(r.Config, r.Translator)
+	}
+
+	return config.Bootstrap(&config.BootstrapConfig{
+		DB:         r.DB,
+		Redis:      r.Redis,
+		App:        r.App,
+		Log:        r.Log,
+		Validate:   r.Validate,
+		Translator: r.Translator,
+		Config:     r.Config,
+		Reloader:   reloader,
+		Lifecycle:  r.Lifecycle,
+		RunWorkers: runWorkers,
+	})
+}
+
+// close stops everything registered with the lifecycle.
+func (r *runtime) close() {
+	if r.Lifecycle == nil {
+		return
+	}
+	if err := r.Lifecycle.Stop(context.Background()); err != nil {
+		r.Log.Warnf("Failed to close connections : %v", err)
+	}
+}
+
+// checkSchema refuses to continue when migrations are pending.
+func (r *runtime) checkSchema(ctx context.Context) {
+	pending, err := config.NewMigrator(r.DB, r.Log).Pending(ctx)
+	if err != nil {
+		r.Log.Fatalf("Failed to check database schema version : %v", err)
+	}
+	if len(pending) > 0 {
+		r.Log.Fatalf("Database schema is behind by %d migration(s), run \"streamhelp migrate up\" first", len(pending))
+	}
+}
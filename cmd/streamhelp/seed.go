@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"streamhelper-backend/db"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/fixture"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+func newSeedCommand() *cobra.Command {
+	var id, name, password, file string
+	var list bool
+	command := &cobra.Command{
+		Use:   "seed [dataset]",
+		Short: "Create the initial admin account or load a fixture dataset",
+		Long: "Without arguments seed creates the admin account if it does not exist.\n" +
+			"With a dataset name (see --list) or --file it loads that fixture instead.\n" +
+			"Seeding is idempotent, records that already exist are skipped.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if list {
+				datasets, err := fixture.Datasets(fixturesFS())
+				if err != nil {
+					return err
+				}
+				for _, dataset := range datasets {
+					fmt.Println(dataset)
+				}
+				return nil
+			}
+
+			var data *fixture.Fixture
+			var err error
+			switch {
+			case file != "" && len(args) > 0:
+				return errors.New("give either a dataset or --file, not both")
+			case file != "":
+				var content []byte
+				if content, err = os.ReadFile(file); err == nil {
+					data, err = fixture.Parse(file, content)
+				}
+			case len(args) > 0:
+				data, err = fixture.ReadDataset(fixturesFS(), args[0])
+			}
+			if err != nil {
+				return err
+			}
+
+			r := loadConfig().connect()
+			defer r.close()
+			application := r.bootstrap(nil, false)
+
+			if data != nil {
+				loader := fixture.NewLoader(r.Log, application.UserUseCase, application.ChannelUseCase, application.DonationUseCase,
+					application.GoalUseCase, application.LeaderboardUseCase)
+				result, err := loader.Load(cmd.Context(), data)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Created %d record(s), %d already existed\n", result.Created, result.Skipped)
+				return nil
+			}
+
+			generated := password == ""
+			if generated {
+				password = util.RandomToken(12)
+			}
+
+			_, err = application.UserUseCase.Create(cmd.Context(), &model.RegisterUserRequest{
+				ID:       id,
+				Name:     name,
+				Password: password,
+			})
+			switch {
+			case errors.Is(err, model.ErrUserAlreadyExists):
+				// seeding twice must not fail nor touch the existing password
+				generated = false
+				fmt.Printf("User %s already exists\n", id)
+			case err != nil:
+				return err
+			default:
+				fmt.Printf("Created user %s\n", id)
+			}
+
+			if _, err := application.UserUseCase.SetRole(cmd.Context(), &model.SetUserRoleRequest{
+				ID:   id,
+				Role: entity.RoleAdmin,
+			}); err != nil {
+				return err
+			}
+
+			if generated {
+				fmt.Printf("Generated password: %s\n", password)
+			}
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&id, "admin-id", "admin", "admin user ID")
+	command.Flags().StringVar(&name, "admin-name", "Administrator", "admin display name")
+	command.Flags().StringVar(&password, "admin-password", "", "admin password, generated when empty")
+	command.Flags().StringVarP(&file, "file", "f", "", "load a YAML or JSON fixture file")
+	command.Flags().BoolVar(&list, "list", false, "list the built-in datasets")
+
+	return command
+}
+
+func fixturesFS() fs.FS {
+	fixtures, err := fs.Sub(db.Fixtures, "fixtures")
+	if err != nil {
+		// the embed pattern guarantees the directory exists
+		panic(err)
+	}
+	return fixtures
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"streamhelper-backend/internal/config"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newServeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Start the HTTP server",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServe()
+		},
+	}
+}
+
+func runServe() error {
+	r := loadConfig()
+	tracerProvider := config.NewTracerProvider(r.Config, r.Log)
+	r.connect()
+	r.Lifecycle.Append(config.TracerProviderHook(tracerProvider))
+
+	// schema changes are applied by "streamhelp migrate up", never implicitly
+	r.checkSchema(context.Background())
+
+	// hooks stop in reverse order: readiness flips first, then workers stop,
+	// in-flight requests drain and the connections close last
+	serverErr := make(chan error, 1)
+	r.Translator = config.NewTranslator(r.Config, r.Validate, r.Log)
+	r.App = config.NewFiber(r.Config, r.Translator)
+	r.Lifecycle.Append(config.Hook{
+		Name: "http",
+		OnStart: func(ctx context.Context) error {
+			go func() {
+				serverErr <- r.App.Listen(fmt.Sprintf(":%d", r.Config.Web.Port))
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			return r.App.ShutdownWithTimeout(time.Duration(r.Config.Web.ShutdownTimeout) * time.Second)
+		},
+	})
+
+	reloader := config.NewConfigReloader(r.Viper, r.Validate, r.Log, r.Config)
+	r.bootstrap(reloader, r.Config.Worker.Embedded)
+	reloader.Watch()
+
+	return runUntilSignal(r, serverErr)
+}
+
+// runUntilSignal starts the lifecycle and blocks until SIGINT/SIGTERM or a
+// fatal server error, then stops everything within the shutdown timeout.
+func runUntilSignal(r *runtime, serverErr <-chan error) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := r.Lifecycle.Start(ctx); err != nil {
+		r.Log.Fatalf("Failed to start subsystems : %v", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		r.Log.Info("Shutdown signal received")
+	case err := <-serverErr:
+		if err != nil {
+			r.Log.Errorf("Failed to start server :%v", err)
+		}
+	}
+	stop()
+
+	timeout := time.Duration(r.Config.Web.ShutdownTimeout) * time.Second
+	stopCtx, cancel := context.WithTimeout(context.Background(), 2*timeout)
+	defer cancel()
+	if err := r.Lifecycle.Stop(stopCtx); err != nil {
+		r.Log.Errorf("Failed to stop subsystems cleanly : %v", err)
+		return err
+	}
+
+	r.Log.Info("Stopped")
+	return nil
+}
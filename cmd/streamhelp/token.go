@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"streamhelper-backend/internal/model"
+
+	"github.com/spf13/cobra"
+)
+
+func newTokenCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "token",
+		Short: "Manage access tokens",
+	}
+
+	request := new(model.RevokeTokenRequest)
+	revoke := &cobra.Command{
+		Use:   "revoke",
+		Short: "Revoke one token, or every token of a user",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (request.ID == "") == (request.Token == "") {
+				return errors.New("exactly one of --user or --token is required")
+			}
+
+			r := loadConfig().connect()
+			defer r.close()
+			application := r.bootstrap(nil, false)
+
+			revoked, err := application.UserUseCase.RevokeTokens(cmd.Context(), request)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Revoked %d token(s)\n", revoked)
+			return nil
+		},
+	}
+	revoke.Flags().StringVar(&request.ID, "user", "", "revoke every token of this user ID")
+	revoke.Flags().StringVar(&request.Token, "token", "", "revoke this token")
+
+	command.AddCommand(revoke)
+
+	return command
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/util"
+
+	"github.com/spf13/cobra"
+)
+
+func newUserCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "user",
+		Short: "Manage user accounts",
+	}
+
+	command.AddCommand(
+		newUserCreateCommand(),
+		newUserDisableCommand(),
+		newUserResetPasswordCommand(),
+		newUserPromoteCommand(),
+	)
+
+	return command
+}
+
+func newUserCreateCommand() *cobra.Command {
+	request := new(model.RegisterUserRequest)
+	command := &cobra.Command{
+		Use:   "create",
+		Short: "Create a user, generating a password when none is given",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r := loadConfig().connect()
+			defer r.close()
+			application := r.bootstrap(nil, false)
+
+			generated := request.Password == ""
+			if generated {
+				request.Password = util.RandomToken(12)
+			}
+
+			response, err := application.UserUseCase.Create(cmd.Context(), request)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Created user %s\n", response.ID)
+			if generated {
+				fmt.Printf("Generated password: %s\n", request.Password)
+			}
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&request.ID, "id", "", "user ID")
+	command.Flags().StringVar(&request.Name, "name", "", "display name")
+	command.Flags().StringVar(&request.Password, "password", "", "password, generated when empty")
+	command.Flags().StringVar(&request.Language, "language", "", "preferred language (id or en)")
+	_ = command.MarkFlagRequired("id")
+	_ = command.MarkFlagRequired("name")
+
+	return command
+}
+
+func newUserDisableCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "disable <id>",
+		Short: "Disable a user and revoke all of their tokens",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r := loadConfig().connect()
+			defer r.close()
+			application := r.bootstrap(nil, false)
+
+			response, err := application.UserUseCase.Disable(cmd.Context(), &model.DisableUserRequest{ID: args[0]})
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Disabled user %s\n", response.ID)
+			return nil
+		},
+	}
+}
+
+func newUserResetPasswordCommand() *cobra.Command {
+	var password string
+	command := &cobra.Command{
+		Use:   "reset-password <id>",
+		Short: "Set a new password and revoke all tokens of a user",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r := loadConfig().connect()
+			defer r.close()
+			application := r.bootstrap(nil, false)
+
+			generated := password == ""
+			if generated {
+				password = util.RandomToken(12)
+			}
+
+			response, err := application.UserUseCase.ResetPassword(cmd.Context(), &model.ResetPasswordRequest{
+				ID:       args[0],
+				Password: password,
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Reset password of user %s\n", response.ID)
+			if generated {
+				fmt.Printf("Generated password: %s\n", password)
+			}
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&password, "password", "", "new password, generated when empty")
+
+	return command
+}
+
+func newUserPromoteCommand() *cobra.Command {
+	var role string
+	command := &cobra.Command{
+		Use:   "promote <id>",
+		Short: "Change the role of a user",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r := loadConfig().connect()
+			defer r.close()
+			application := r.bootstrap(nil, false)
+
+			response, err := application.UserUseCase.SetRole(cmd.Context(), &model.SetUserRoleRequest{
+				ID:   args[0],
+				Role: role,
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("User %s is now %s\n", response.ID, response.Role)
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&role, "role", entity.RoleAdmin, "role to assign (user or admin)")
+
+	return command
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"streamhelper-backend/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+func newWorkerCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "worker",
+		Short: "Run the background workers without the HTTP server",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r := loadConfig()
+			tracerProvider := config.NewTracerProvider(r.Config, r.Log)
+			r.connect()
+			r.Lifecycle.Append(config.TracerProviderHook(tracerProvider))
+			r.checkSchema(context.Background())
+
+			reloader := config.NewConfigReloader(r.Viper, r.Validate, r.Log, r.Config)
+			r.bootstrap(reloader, true)
+			reloader.Watch()
+
+			return runUntilSignal(r, nil)
+		},
+	}
+}
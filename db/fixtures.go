@@ -0,0 +1,8 @@
+package db
+
+import "embed"
+
+// Fixtures holds the named seed datasets loaded by "streamhelp seed".
+//
+//go:embed fixtures/*.yaml
+var Fixtures embed.FS
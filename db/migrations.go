@@ -0,0 +1,9 @@
+package db
+
+import "embed"
+
+// Migrations holds the versioned SQL files so the binary can migrate a
+// database without the source tree next to it.
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
@@ -1,4 +1,4 @@
-CREATE TABLE users
+CREATE TABLE IF NOT EXISTS users
 (
     id         VARCHAR(100) NOT NULL,
     name       VARCHAR(100) NOT NULL,
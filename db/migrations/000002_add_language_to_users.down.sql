@@ -0,0 +1,2 @@
+ALTER TABLE users
+    DROP COLUMN language;
\ No newline at end of file
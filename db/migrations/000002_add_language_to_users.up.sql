@@ -0,0 +1,2 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS language VARCHAR(5) NULL;
\ No newline at end of file
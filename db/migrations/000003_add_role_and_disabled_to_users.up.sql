@@ -0,0 +1,3 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user',
+    ADD COLUMN IF NOT EXISTS disabled_at BIGINT NOT NULL DEFAULT 0;
\ No newline at end of file
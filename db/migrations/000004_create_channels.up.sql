@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS channels
+(
+    id               VARCHAR(36)  NOT NULL,
+    user_id          VARCHAR(100) NOT NULL,
+    slug             VARCHAR(50)  NOT NULL,
+    display_name     VARCHAR(100) NOT NULL,
+    bio              TEXT         NOT NULL DEFAULT '',
+    avatar_url       VARCHAR(255) NOT NULL DEFAULT '',
+    social_links     JSONB        NOT NULL DEFAULT '[]',
+    platform_handles JSONB        NOT NULL DEFAULT '{}',
+    created_at       BIGINT       NOT NULL,
+    updated_at       BIGINT       NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_channels_user_id FOREIGN KEY (user_id) REFERENCES users (id),
+    CONSTRAINT idx_channels_user_id UNIQUE (user_id),
+    CONSTRAINT idx_channels_slug UNIQUE (slug)
+);
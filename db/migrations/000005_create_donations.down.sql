@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS donations;
+
+ALTER TABLE channels
+    DROP COLUMN max_donation,
+    DROP COLUMN min_donation,
+    DROP COLUMN currency;
@@ -0,0 +1,23 @@
+ALTER TABLE channels
+    ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT 'IDR',
+    ADD COLUMN IF NOT EXISTS min_donation BIGINT NOT NULL DEFAULT 1000,
+    ADD COLUMN IF NOT EXISTS max_donation BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS donations
+(
+    id         VARCHAR(36)  NOT NULL,
+    channel_id VARCHAR(36)  NOT NULL,
+    donor_name VARCHAR(50)  NOT NULL,
+    message    VARCHAR(255) NOT NULL DEFAULT '',
+    amount     BIGINT       NOT NULL,
+    currency   VARCHAR(3)   NOT NULL,
+    status     VARCHAR(20)  NOT NULL,
+    anonymous  BOOLEAN      NOT NULL DEFAULT FALSE,
+    paid_at    BIGINT       NOT NULL DEFAULT 0,
+    created_at BIGINT       NOT NULL,
+    updated_at BIGINT       NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_donations_channel_id FOREIGN KEY (channel_id) REFERENCES channels (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_donations_channel_id_created_at ON donations (channel_id, created_at);
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS payments
+(
+    id           VARCHAR(36)  NOT NULL,
+    donation_id  VARCHAR(36)  NOT NULL,
+    provider     VARCHAR(20)  NOT NULL,
+    reference    VARCHAR(100) NOT NULL DEFAULT '',
+    method       VARCHAR(20)  NOT NULL,
+    bank         VARCHAR(10)  NOT NULL DEFAULT '',
+    status       VARCHAR(20)  NOT NULL,
+    amount       BIGINT       NOT NULL,
+    currency     VARCHAR(3)   NOT NULL,
+    qr_string    TEXT         NOT NULL DEFAULT '',
+    redirect_url TEXT         NOT NULL DEFAULT '',
+    va_number    VARCHAR(50)  NOT NULL DEFAULT '',
+    expires_at   BIGINT       NOT NULL DEFAULT 0,
+    created_at   BIGINT       NOT NULL,
+    updated_at   BIGINT       NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_payments_donation_id FOREIGN KEY (donation_id) REFERENCES donations (id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_donation_id ON payments (donation_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_channels_overlay_key;
+
+ALTER TABLE channels
+    DROP COLUMN overlay_key;
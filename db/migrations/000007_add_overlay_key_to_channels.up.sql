@@ -0,0 +1,9 @@
+ALTER TABLE channels
+    ADD COLUMN IF NOT EXISTS overlay_key VARCHAR(100) NOT NULL DEFAULT '';
+
+-- existing channels get a random key, new ones get theirs from the application
+UPDATE channels
+SET overlay_key = replace(gen_random_uuid()::text, '-', '') || replace(gen_random_uuid()::text, '-', '')
+WHERE overlay_key = '';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_channels_overlay_key ON channels (overlay_key);
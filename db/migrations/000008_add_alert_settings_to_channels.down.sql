@@ -0,0 +1,3 @@
+ALTER TABLE channels
+    DROP COLUMN alert_moderation,
+    DROP COLUMN alert_duration;
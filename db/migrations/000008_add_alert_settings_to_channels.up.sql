@@ -0,0 +1,3 @@
+ALTER TABLE channels
+    ADD COLUMN IF NOT EXISTS alert_moderation BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS alert_duration INT NOT NULL DEFAULT 5;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS alert_templates
+(
+    id         VARCHAR(36)  NOT NULL,
+    channel_id VARCHAR(36)  NOT NULL,
+    name       VARCHAR(50)  NOT NULL,
+    event_type VARCHAR(50)  NOT NULL,
+    min_amount BIGINT       NOT NULL DEFAULT 0,
+    text       VARCHAR(500) NOT NULL,
+    image_url  VARCHAR(255) NOT NULL DEFAULT '',
+    sound_url  VARCHAR(255) NOT NULL DEFAULT '',
+    duration   INT          NOT NULL DEFAULT 0,
+    animation  VARCHAR(20)  NOT NULL DEFAULT 'fade',
+    created_at BIGINT       NOT NULL,
+    updated_at BIGINT       NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_alert_templates_channel_id FOREIGN KEY (channel_id) REFERENCES channels (id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_alert_templates_channel_id_tier ON alert_templates (channel_id, event_type, min_amount);
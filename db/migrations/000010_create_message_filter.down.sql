@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS filtered_messages;
+DROP TABLE IF EXISTS blocked_terms;
+
+ALTER TABLE donations
+    DROP COLUMN held;
+
+ALTER TABLE channels
+    DROP COLUMN filter_action;
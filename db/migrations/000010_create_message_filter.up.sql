@@ -0,0 +1,34 @@
+ALTER TABLE channels
+    ADD COLUMN IF NOT EXISTS filter_action VARCHAR(10) NOT NULL DEFAULT 'mask';
+
+ALTER TABLE donations
+    ADD COLUMN IF NOT EXISTS held BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS blocked_terms
+(
+    id         VARCHAR(36)  NOT NULL,
+    channel_id VARCHAR(36)  NOT NULL,
+    kind       VARCHAR(10)  NOT NULL,
+    pattern    VARCHAR(100) NOT NULL,
+    created_at BIGINT       NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_blocked_terms_channel_id FOREIGN KEY (channel_id) REFERENCES channels (id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_blocked_terms_channel_id_kind_pattern ON blocked_terms (channel_id, kind, pattern);
+
+CREATE TABLE IF NOT EXISTS filtered_messages
+(
+    id          VARCHAR(36)  NOT NULL,
+    channel_id  VARCHAR(36)  NOT NULL,
+    donation_id VARCHAR(36)  NOT NULL DEFAULT '',
+    action      VARCHAR(10)  NOT NULL,
+    original    VARCHAR(255) NOT NULL,
+    filtered    VARCHAR(255) NOT NULL,
+    matches     TEXT         NOT NULL,
+    created_at  BIGINT       NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_filtered_messages_channel_id FOREIGN KEY (channel_id) REFERENCES channels (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_filtered_messages_channel_id_created_at ON filtered_messages (channel_id, created_at);
@@ -0,0 +1,5 @@
+ALTER TABLE channels
+    DROP COLUMN tts_enabled,
+    DROP COLUMN tts_voice,
+    DROP COLUMN tts_language,
+    DROP COLUMN tts_max_length;
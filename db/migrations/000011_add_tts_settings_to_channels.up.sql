@@ -0,0 +1,5 @@
+ALTER TABLE channels
+    ADD COLUMN IF NOT EXISTS tts_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS tts_voice VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS tts_language VARCHAR(35) NOT NULL DEFAULT 'id-ID',
+    ADD COLUMN IF NOT EXISTS tts_max_length INT NOT NULL DEFAULT 200;
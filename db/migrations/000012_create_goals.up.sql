@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS goals
+(
+    id            VARCHAR(36)  NOT NULL,
+    channel_id    VARCHAR(36)  NOT NULL,
+    title         VARCHAR(100) NOT NULL,
+    target_amount BIGINT       NOT NULL,
+    currency      VARCHAR(3)   NOT NULL,
+    sources       TEXT         NOT NULL,
+    starts_at     BIGINT       NOT NULL,
+    ends_at       BIGINT       NOT NULL DEFAULT 0,
+    amount        BIGINT       NOT NULL DEFAULT 0,
+    completed_at  BIGINT       NOT NULL DEFAULT 0,
+    created_at    BIGINT       NOT NULL,
+    updated_at    BIGINT       NOT NULL,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_goals_channel_id FOREIGN KEY (channel_id) REFERENCES channels (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_goals_channel_id_ends_at ON goals (channel_id, ends_at);
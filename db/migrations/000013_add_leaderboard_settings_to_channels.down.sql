@@ -0,0 +1,3 @@
+ALTER TABLE channels
+    DROP COLUMN overlay_rankings,
+    DROP COLUMN stream_started_at;
@@ -0,0 +1,3 @@
+ALTER TABLE channels
+    ADD COLUMN IF NOT EXISTS overlay_rankings BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS stream_started_at BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,440 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"streamhelper-backend/internal/tracing"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Statuses of an alert. An alert waits in review until approved when the
+// channel moderates alerts, then in the queue until it is shown.
+const (
+	StatusReview   = "review"
+	StatusQueued   = "queued"
+	StatusShowing  = "showing"
+	StatusShown    = "shown"
+	StatusSkipped  = "skipped"
+	StatusRejected = "rejected"
+)
+
+var (
+	ErrNotFound = errors.New("alert: not found")
+	// ErrConflict is returned when an alert is not in a status the
+	// operation applies to, e.g. approving an alert that is already queued.
+	ErrConflict = errors.New("alert: not allowed in current status")
+)
+
+// Alert is one thing to show on the overlay, most often a paid donation. ID
+// is the ID of its source so the same donation is never queued twice.
+type Alert struct {
+	ID        string          `json:"id"`
+	ChannelID string          `json:"channel_id"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	// Duration is how long the overlay shows the alert, in milliseconds.
+	Duration  int64  `json:"duration"`
+	Status    string `json:"status"`
+	CreatedAt int64  `json:"created_at"`
+	// Display is how the overlay shows the alert, rendered from the
+	// channel's alert template when the alert is queued.
+	Display *Display `json:"display,omitempty"`
+}
+
+// Display is an alert as it appears on screen. Text is plain text, the
+// overlay must not interpret it as HTML.
+type Display struct {
+	TemplateID string `json:"template_id,omitempty"`
+	Text       string `json:"text"`
+	ImageURL   string `json:"image_url,omitempty"`
+	SoundURL   string `json:"sound_url,omitempty"`
+	Animation  string `json:"animation"`
+	// SpeechURL is the donation message read aloud, played after the sound.
+	SpeechURL string `json:"speech_url,omitempty"`
+}
+
+// State is a snapshot of a channel's queue. Current is the alert on screen
+// until Until, in unix milliseconds.
+type State struct {
+	Paused  bool
+	Current *Alert
+	Until   int64
+	Review  []Alert
+	Queue   []Alert
+	History []Alert
+}
+
+// Queue keeps the alert queue of every channel in Redis, so it survives
+// restarts and is shared by every instance. Each change is a Lua script and
+// therefore atomic, two instances advancing the same channel never show the
+// same alert twice.
+type Queue struct {
+	Redis *redis.Client
+	// HistoryLimit is how many finished alerts are kept for replay.
+	HistoryLimit int64
+}
+
+func NewQueue(redisClient *redis.Client, historyLimit int64) *Queue {
+	return &Queue{
+		Redis:        redisClient,
+		HistoryLimit: historyLimit,
+	}
+}
+
+// finishScript moves an alert to the history and forgets the oldest ones.
+// It is prepended to the scripts that need it.
+const finishScript = `
+local function finish(id, status)
+	redis.call('HSET', KEYS[3], id, status)
+	redis.call('LREM', KEYS[6], 0, id)
+	redis.call('LPUSH', KEYS[6], id)
+	local limit = tonumber(ARGV[1])
+	local expired = redis.call('LRANGE', KEYS[6], limit, -1)
+	for _, old in ipairs(expired) do
+		redis.call('HDEL', KEYS[2], old)
+		redis.call('HDEL', KEYS[3], old)
+	end
+	redis.call('LTRIM', KEYS[6], 0, limit - 1)
+end
+`
+
+// every script receives the same keys, see Queue.keys
+var enqueueScript = redis.NewScript(finishScript + `
+if redis.call('HSETNX', KEYS[2], ARGV[2], ARGV[3]) == 0 then
+	return redis.call('HGET', KEYS[3], ARGV[2])
+end
+if ARGV[4] == '1' then
+	redis.call('HSET', KEYS[3], ARGV[2], 'review')
+	redis.call('RPUSH', KEYS[4], ARGV[2])
+	return 'review'
+end
+redis.call('HSET', KEYS[3], ARGV[2], 'queued')
+redis.call('RPUSH', KEYS[5], ARGV[2])
+redis.call('SADD', KEYS[7], ARGV[5])
+return 'queued'
+`)
+
+var approveScript = redis.NewScript(finishScript + `
+local status = redis.call('HGET', KEYS[3], ARGV[2])
+if not status then return redis.error_reply('NOTFOUND alert not found') end
+if status ~= 'review' then return redis.error_reply('CONFLICT alert not allowed in current status') end
+redis.call('LREM', KEYS[4], 0, ARGV[2])
+if ARGV[3] == '1' then
+	redis.call('HSET', KEYS[3], ARGV[2], 'queued')
+	redis.call('RPUSH', KEYS[5], ARGV[2])
+	redis.call('SADD', KEYS[7], ARGV[4])
+	return 'queued'
+end
+finish(ARGV[2], 'rejected')
+return 'rejected'
+`)
+
+var skipScript = redis.NewScript(finishScript + `
+local status = redis.call('HGET', KEYS[3], ARGV[2])
+if not status then return redis.error_reply('NOTFOUND alert not found') end
+if status == 'showing' then
+	redis.call('HDEL', KEYS[1], 'current', 'until')
+elseif status == 'queued' then
+	redis.call('LREM', KEYS[5], 0, ARGV[2])
+elseif status == 'review' then
+	redis.call('LREM', KEYS[4], 0, ARGV[2])
+else
+	return redis.error_reply('CONFLICT alert not allowed in current status')
+end
+finish(ARGV[2], 'skipped')
+return status
+`)
+
+var replayScript = redis.NewScript(finishScript + `
+local status = redis.call('HGET', KEYS[3], ARGV[2])
+if not status then return redis.error_reply('NOTFOUND alert not found') end
+if status ~= 'shown' and status ~= 'skipped' then return redis.error_reply('CONFLICT alert not allowed in current status') end
+redis.call('LREM', KEYS[6], 0, ARGV[2])
+redis.call('HSET', KEYS[3], ARGV[2], 'queued')
+redis.call('LPUSH', KEYS[5], ARGV[2])
+redis.call('SADD', KEYS[7], ARGV[3])
+return 'queued'
+`)
+
+// advanceScript finishes the current alert once its time is up and starts
+// the next one unless the queue is paused. It returns the started alert.
+var advanceScript = redis.NewScript(finishScript + `
+local now = tonumber(ARGV[2])
+local current = redis.call('HGET', KEYS[1], 'current')
+if current then
+	if now < tonumber(redis.call('HGET', KEYS[1], 'until') or '0') then
+		return false
+	end
+	redis.call('HDEL', KEYS[1], 'current', 'until')
+	finish(current, 'shown')
+end
+if redis.call('HGET', KEYS[1], 'paused') == '1' then
+	redis.call('SREM', KEYS[7], ARGV[3])
+	return false
+end
+local id = redis.call('LPOP', KEYS[5])
+if not id then
+	redis.call('SREM', KEYS[7], ARGV[3])
+	return false
+end
+local item = redis.call('HGET', KEYS[2], id)
+local duration = tonumber(cjson.decode(item)['duration'])
+redis.call('HSET', KEYS[3], id, 'showing')
+redis.call('HSET', KEYS[1], 'current', id, 'until', now + duration)
+return item
+`)
+
+// Enqueue adds an alert to the channel's queue, or to review when it must be
+// approved first, and returns the status it got. Enqueueing an alert that is
+// already known changes nothing.
+func (q *Queue) Enqueue(ctx context.Context, alert *Alert, review bool) (string, error) {
+	ctx, span := tracing.Start(ctx, "Queue.Enqueue")
+	defer span.End()
+
+	if alert.CreatedAt == 0 {
+		alert.CreatedAt = time.Now().UnixMilli()
+	}
+	alert.Status = ""
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		return "", err
+	}
+
+	status, err := enqueueScript.Run(ctx, q.Redis, q.keys(alert.ChannelID),
+		q.HistoryLimit, alert.ID, payload, flag(review), alert.ChannelID).Text()
+	if err != nil {
+		tracing.RecordError(span, err)
+		return "", err
+	}
+	return status, nil
+}
+
+// Approve moves an alert from review to the end of the queue.
+func (q *Queue) Approve(ctx context.Context, channelID string, id string) error {
+	return q.run(ctx, approveScript, channelID, id, flag(true), channelID)
+}
+
+// Reject drops an alert in review.
+func (q *Queue) Reject(ctx context.Context, channelID string, id string) error {
+	return q.run(ctx, approveScript, channelID, id, flag(false), channelID)
+}
+
+// Skip takes an alert off the screen, out of the queue or out of review and
+// returns the status it had.
+func (q *Queue) Skip(ctx context.Context, channelID string, id string) (string, error) {
+	ctx, span := tracing.Start(ctx, "Queue.Skip")
+	defer span.End()
+
+	status, err := skipScript.Run(ctx, q.Redis, q.keys(channelID), q.HistoryLimit, id).Text()
+	if err != nil {
+		tracing.RecordError(span, err)
+		return "", scriptError(err)
+	}
+	return status, nil
+}
+
+// Replay puts a finished alert back at the front of the queue.
+func (q *Queue) Replay(ctx context.Context, channelID string, id string) error {
+	return q.run(ctx, replayScript, channelID, id, channelID)
+}
+
+// Pause keeps the current alert on screen until its time is up but starts no
+// new one until Resume.
+func (q *Queue) Pause(ctx context.Context, channelID string) error {
+	return q.Redis.HSet(ctx, stateKey(channelID), "paused", "1").Err()
+}
+
+func (q *Queue) Resume(ctx context.Context, channelID string) error {
+	pipe := q.Redis.TxPipeline()
+	pipe.HSet(ctx, stateKey(channelID), "paused", "0")
+	pipe.SAdd(ctx, activeKey, channelID)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Active returns the channels that may have an alert to start or finish.
+func (q *Queue) Active(ctx context.Context) ([]string, error) {
+	return q.Redis.SMembers(ctx, activeKey).Result()
+}
+
+// Advance finishes the channel's current alert when its time is up and
+// returns the next alert it started, or nil.
+func (q *Queue) Advance(ctx context.Context, channelID string, now time.Time) (*Alert, error) {
+	ctx, span := tracing.Start(ctx, "Queue.Advance")
+	defer span.End()
+
+	payload, err := advanceScript.Run(ctx, q.Redis, q.keys(channelID),
+		q.HistoryLimit, now.UnixMilli(), channelID).Text()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	alert := new(Alert)
+	if err := json.Unmarshal([]byte(payload), alert); err != nil {
+		return nil, err
+	}
+	alert.Status = StatusShowing
+	return alert, nil
+}
+
+// Get returns the alert with its current status.
+func (q *Queue) Get(ctx context.Context, channelID string, id string) (*Alert, error) {
+	alerts, err := q.load(ctx, channelID, []string{id})
+	if err != nil {
+		return nil, err
+	}
+	if len(alerts) == 0 {
+		return nil, ErrNotFound
+	}
+	return &alerts[0], nil
+}
+
+// State returns the channel's queue, with at most limit alerts per list.
+func (q *Queue) State(ctx context.Context, channelID string, limit int64) (*State, error) {
+	ctx, span := tracing.Start(ctx, "Queue.State")
+	defer span.End()
+
+	pipe := q.Redis.TxPipeline()
+	stateCmd := pipe.HGetAll(ctx, stateKey(channelID))
+	reviewCmd := pipe.LRange(ctx, reviewKey(channelID), 0, limit-1)
+	queueCmd := pipe.LRange(ctx, queueKey(channelID), 0, limit-1)
+	historyCmd := pipe.LRange(ctx, historyKey(channelID), 0, limit-1)
+	if _, err := pipe.Exec(ctx); err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	values := stateCmd.Val()
+	state := &State{Paused: values["paused"] == "1"}
+	state.Until, _ = strconv.ParseInt(values["until"], 10, 64)
+
+	var err error
+	if current := values["current"]; current != "" {
+		alerts, err := q.load(ctx, channelID, []string{current})
+		if err != nil {
+			return nil, err
+		}
+		if len(alerts) > 0 {
+			state.Current = &alerts[0]
+		}
+	}
+	if state.Review, err = q.load(ctx, channelID, reviewCmd.Val()); err != nil {
+		return nil, err
+	}
+	if state.Queue, err = q.load(ctx, channelID, queueCmd.Val()); err != nil {
+		return nil, err
+	}
+	if state.History, err = q.load(ctx, channelID, historyCmd.Val()); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// load returns the alerts with the given IDs in order, skipping unknown ones.
+func (q *Queue) load(ctx context.Context, channelID string, ids []string) ([]Alert, error) {
+	if len(ids) == 0 {
+		return []Alert{}, nil
+	}
+
+	pipe := q.Redis.Pipeline()
+	itemsCmd := pipe.HMGet(ctx, itemsKey(channelID), ids...)
+	statusCmd := pipe.HMGet(ctx, statusKey(channelID), ids...)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	statuses := statusCmd.Val()
+	alerts := make([]Alert, 0, len(ids))
+	for i, item := range itemsCmd.Val() {
+		payload, ok := item.(string)
+		if !ok {
+			continue
+		}
+		alert := Alert{}
+		if err := json.Unmarshal([]byte(payload), &alert); err != nil {
+			return nil, err
+		}
+		alert.Status, _ = statuses[i].(string)
+		alerts = append(alerts, alert)
+	}
+	return alerts, nil
+}
+
+func (q *Queue) run(ctx context.Context, script *redis.Script, channelID string, args ...any) error {
+	ctx, span := tracing.Start(ctx, "Queue.Run")
+	defer span.End()
+
+	err := script.Run(ctx, q.Redis, q.keys(channelID), append([]any{q.HistoryLimit}, args...)...).Err()
+	if err != nil {
+		tracing.RecordError(span, err)
+		return scriptError(err)
+	}
+	return nil
+}
+
+// keys are the same for every script, in this order.
+func (q *Queue) keys(channelID string) []string {
+	return []string{
+		stateKey(channelID),
+		itemsKey(channelID),
+		statusKey(channelID),
+		reviewKey(channelID),
+		queueKey(channelID),
+		historyKey(channelID),
+		activeKey,
+	}
+}
+
+// scriptError maps the error codes the scripts reply with.
+func scriptError(err error) error {
+	switch {
+	case strings.HasPrefix(err.Error(), "NOTFOUND"):
+		return ErrNotFound
+	case strings.HasPrefix(err.Error(), "CONFLICT"):
+		return ErrConflict
+	}
+	return err
+}
+
+func flag(value bool) string {
+	if value {
+		return "1"
+	}
+	return "0"
+}
+
+// activeKey lists the channels the scheduler has to look at.
+const activeKey = "alerts:active"
+
+func stateKey(channelID string) string {
+	return "alerts:" + channelID + ":state"
+}
+
+func itemsKey(channelID string) string {
+	return "alerts:" + channelID + ":items"
+}
+
+func statusKey(channelID string) string {
+	return "alerts:" + channelID + ":status"
+}
+
+func reviewKey(channelID string) string {
+	return "alerts:" + channelID + ":review"
+}
+
+func queueKey(channelID string) string {
+	return "alerts:" + channelID + ":queue"
+}
+
+func historyKey(channelID string) string {
+	return "alerts:" + channelID + ":history"
+}
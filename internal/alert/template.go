@@ -0,0 +1,119 @@
+package alert
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Variables an alert template may refer to as {{name}}.
+const (
+	VariableDonor    = "donor"
+	VariableAmount   = "amount"
+	VariableCurrency = "currency"
+	VariableMessage  = "message"
+)
+
+// MaxRenderedLength bounds the text a template produces, whatever the values.
+const MaxRenderedLength = 1000
+
+var ErrTemplateSyntax = errors.New("alert: invalid template")
+
+var variables = map[string]bool{
+	VariableDonor:    true,
+	VariableAmount:   true,
+	VariableCurrency: true,
+	VariableMessage:  true,
+}
+
+// Template is a parsed alert text. It only substitutes known variables, there
+// are no functions, conditions or loops, so evaluating a template written by
+// a streamer can neither fail nor run anything on the server. Values are
+// inserted as they are, the overlay must display the text as plain text.
+type Template struct {
+	// parts alternate between literal text and variable names, starting
+	// with text.
+	parts []string
+}
+
+// ParseTemplate parses text like "{{donor}} tipped {{amount}}!". Spaces
+// inside the braces are allowed, unknown variables and unclosed braces are
+// not.
+func ParseTemplate(text string) (*Template, error) {
+	template := &Template{}
+
+	rest := text
+	for {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			if strings.Contains(rest, "}}") {
+				return nil, fmt.Errorf("%w: unexpected }}", ErrTemplateSyntax)
+			}
+			template.parts = append(template.parts, rest)
+			return template, nil
+		}
+
+		literal := rest[:start]
+		if strings.Contains(literal, "}}") {
+			return nil, fmt.Errorf("%w: unexpected }}", ErrTemplateSyntax)
+		}
+
+		end := strings.Index(rest[start:], "}}")
+		if end < 0 {
+			return nil, fmt.Errorf("%w: unclosed {{", ErrTemplateSyntax)
+		}
+		name := strings.TrimSpace(rest[start+2 : start+end])
+		if !variables[name] {
+			return nil, fmt.Errorf("%w: unknown variable %q", ErrTemplateSyntax, name)
+		}
+
+		template.parts = append(template.parts, literal, name)
+		rest = rest[start+end+2:]
+	}
+}
+
+// Execute renders the template, missing values render as empty text. The
+// result is cut at MaxRenderedLength runes.
+func (t *Template) Execute(values map[string]string) string {
+	var builder strings.Builder
+	for i, part := range t.parts {
+		if i%2 == 0 {
+			builder.WriteString(part)
+		} else {
+			builder.WriteString(values[part])
+		}
+	}
+
+	rendered := []rune(builder.String())
+	if len(rendered) > MaxRenderedLength {
+		rendered = rendered[:MaxRenderedLength]
+	}
+	return string(rendered)
+}
+
+// FormatAmount formats an amount the way viewers read it, "Rp10.000" for
+// rupiah and "USD 10,000" for other currencies, which are in whole units.
+func FormatAmount(amount int64, currency string) string {
+	if strings.EqualFold(currency, "IDR") {
+		return "Rp" + groupThousands(amount, ".")
+	}
+	return strings.ToUpper(currency) + " " + groupThousands(amount, ",")
+}
+
+func groupThousands(amount int64, separator string) string {
+	digits := strconv.FormatInt(amount, 10)
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+
+	var builder strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			builder.WriteString(separator)
+		}
+		builder.WriteRune(digit)
+	}
+	return sign + builder.String()
+}
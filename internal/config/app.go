@@ -1,59 +1,271 @@
 package config
 
 import (
+	"context"
+	"time"
+	"sync/atomic"
+	"streamhelper-backend/internal/alert"
 	"streamhelper-backend/internal/delivery/http"
 	"streamhelper-backend/internal/delivery/http/middleware"
 	"streamhelper-backend/internal/delivery/http/route"
-	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/event"
+	"streamhelper-backend/internal/i18n"
+	"streamhelper-backend/internal/leaderboard"
+	"streamhelper-backend/internal/metrics"
+	"streamhelper-backend/internal/moderation"
 	"streamhelper-backend/internal/repository"
+	"streamhelper-backend/internal/speech"
+	"streamhelper-backend/internal/tracing"
 	"streamhelper-backend/internal/usecase"
 	"streamhelper-backend/internal/util"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
-	"github.com/spf13/viper"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 type BootstrapConfig struct {
 	DB 			*gorm.DB
+	Redis		*redis.Client
 	App 		*fiber.App
 	Log			*logrus.Logger
 	Validate	*validator.Validate
-	Config 		*viper.Viper
+	Translator	*i18n.Translator
+	Config 		*AppConfig
+	Reloader	*ConfigReloader
+	Lifecycle	*Lifecycle
+	// RunWorkers adds the background workers to Lifecycle. serve runs them
+	// in-process unless disabled, the worker command runs nothing else.
+	RunWorkers	bool
 }
 
-func Bootstrap(config *BootstrapConfig) {
+// Application exposes what Bootstrap wired, so CLI commands reuse exactly
+// the same dependencies as the HTTP server.
+type Application struct {
+	UserUseCase		*usecase.UserUseCase
+	ChannelUseCase	*usecase.ChannelUseCase
+	DonationUseCase	*usecase.DonationUseCase
+	PaymentUseCase	*usecase.PaymentUseCase
+	OverlayUseCase	*usecase.OverlayUseCase
+	AlertUseCase	*usecase.AlertUseCase
+	AlertTemplateUseCase	*usecase.AlertTemplateUseCase
+	MessageFilterUseCase	*usecase.MessageFilterUseCase
+	SpeechUseCase	*usecase.SpeechUseCase
+	GoalUseCase	*usecase.GoalUseCase
+	LeaderboardUseCase	*usecase.LeaderboardUseCase
+	EventHub		*event.Hub
+	HealthUseCase	*usecase.HealthUseCase
+	TokenUtil		*util.TokenUtil
+	Workers			[]Hook
+}
+
+func Bootstrap(config *BootstrapConfig) *Application {
+	// setup metrics
+	appMetrics := metrics.NewMetrics()
+	if err := config.DB.Use(metrics.NewGormPlugin(appMetrics)); err != nil {
+		config.Log.Fatalf("Failed to register gorm metrics : %v", err)
+	}
+	if sqlDB, err := config.DB.DB(); err == nil {
+		appMetrics.RegisterDBStats(sqlDB)
+	}
+	config.Redis.AddHook(metrics.NewRedisHook(appMetrics))
+	appMetrics.RegisterRedisStats(config.Redis)
+
+	// setup tracing, the provider itself is installed globally by the caller
+	if err := config.DB.Use(tracing.NewGormPlugin()); err != nil {
+		config.Log.Fatalf("Failed to register gorm tracing : %v", err)
+	}
+	config.Redis.AddHook(tracing.NewRedisHook())
+
 	//setup repository
 	userRepository := repository.NewUserRepository(config.Log)
+	channelRepository := repository.NewChannelRepository(config.Log)
+	donationRepository := repository.NewDonationRepository(config.Log)
+	paymentRepository := repository.NewPaymentRepository(config.Log)
+	alertTemplateRepository := repository.NewAlertTemplateRepository(config.Log)
+	blockedTermRepository := repository.NewBlockedTermRepository(config.Log)
+	filteredMessageRepository := repository.NewFilteredMessageRepository(config.Log)
+	goalRepository := repository.NewGoalRepository(config.Log)
+	leaderboardRepository := repository.NewLeaderboardRepository(config.Log)
 
-	redisClient := redis.NewClient(&redis.Options{
-		Addr: "localhost:6379",
-		DB: 0,
-	})
-
-	tokenUtil := util.NewTokenUtil("benar, benar, rahasia", redisClient)
+	tokenUtil := util.NewTokenUtil(config.Config.Jwt.Secret, config.Redis)
+	passwordUtil := util.NewPasswordUtil(bcrypt.DefaultCost)
+	txManager := repository.NewTransactionManager(config.DB)
+	paymentProvider := NewPaymentProvider(config.Config, config.Redis, config.Log)
+	paymentExpiry := time.Duration(config.Config.Payment.Expiry) * time.Minute
+	eventHub := event.NewHub(config.Redis, config.Log, config.Config.Overlay.History)
+	alertQueue := alert.NewQueue(config.Redis, config.Config.Alert.History)
+	synthesizer := NewSynthesizer(config.Config)
+	leaderboardStore := leaderboard.NewStore(config.Redis)
+	leaderboardLocation, err := time.LoadLocation(config.Config.Leaderboard.Timezone)
+	if err != nil {
+		config.Log.Fatalf("Failed to load leaderboard timezone : %v", err)
+	}
+	speechStorage := speech.NewFileStorage(config.Config.Speech.Dir, config.Config.Speech.BaseURL)
+	speechQueue := speech.NewQueue(config.Redis, time.Duration(config.Config.Speech.Timeout)*time.Second)
 
 	// setup use cases
-	userUseCase := usecase.NewUserUserCase(config.DB, config.Log, config.Validate, userRepository, tokenUtil)
+	userUseCase := usecase.NewUserUserCase(txManager, config.Log, config.Validate, userRepository, tokenUtil, passwordUtil, appMetrics)
+	channelUseCase := usecase.NewChannelUseCase(txManager, config.Log, config.Validate, channelRepository)
+	messageFilterUseCase := usecase.NewMessageFilterUseCase(txManager, config.Log, config.Validate, channelRepository,
+		blockedTermRepository, filteredMessageRepository, moderation.DefaultWords)
+	donationUseCase := usecase.NewDonationUseCase(txManager, config.Log, config.Validate, channelRepository, donationRepository,
+		paymentRepository, filteredMessageRepository, paymentProvider, messageFilterUseCase, paymentExpiry)
+	alertUseCase := usecase.NewAlertUseCase(txManager, config.Log, config.Validate, channelRepository, alertTemplateRepository,
+		alertQueue, eventHub)
+	alertTemplateUseCase := usecase.NewAlertTemplateUseCase(txManager, config.Log, config.Validate, channelRepository,
+		alertTemplateRepository)
+	speechUseCase := usecase.NewSpeechUseCase(txManager, config.Log, config.Validate, channelRepository, donationRepository,
+		synthesizer, speechStorage, speechQueue, alertUseCase, config.Config.Speech.Batch, config.Config.Speech.Attempts)
+	goalUseCase := usecase.NewGoalUseCase(txManager, config.Log, config.Validate, channelRepository, donationRepository,
+		goalRepository, eventHub)
+	leaderboardUseCase := usecase.NewLeaderboardUseCase(txManager, config.Log, config.Validate, channelRepository,
+		donationRepository, leaderboardRepository, leaderboardStore, eventHub, leaderboardLocation,
+		config.Config.Leaderboard.Top)
+	paymentUseCase := usecase.NewPaymentUseCase(txManager, config.Log, config.Validate, channelRepository, donationRepository,
+		paymentRepository, paymentProvider, eventHub, alertUseCase, speechUseCase, goalUseCase, leaderboardUseCase,
+		appMetrics)
+	overlayUseCase := usecase.NewOverlayUseCase(txManager, config.Log, config.Validate, channelRepository, eventHub,
+		config.Config.Overlay.ReplayLimit, config.Config.Overlay.Buffer)
+	healthUseCase := usecase.NewHealthUseCase(config.Log)
+	healthUseCase.Register("postgres", usecase.DatabaseHealthCheck(config.DB))
+	healthUseCase.Register("redis", usecase.RedisHealthCheck(tokenUtil.Redis))
 	
 	// setup controller
 	userController := http.NewUserController(userUseCase, config.Log)
+	channelController := http.NewChannelController(channelUseCase, config.Log)
+	donationController := http.NewDonationController(donationUseCase, config.Log)
+	paymentController := http.NewPaymentController(paymentUseCase, config.Log)
+	overlayController := http.NewOverlayController(overlayUseCase, config.Log,
+		time.Duration(config.Config.Overlay.HeartbeatInterval)*time.Second)
+	alertController := http.NewAlertController(alertUseCase, config.Log)
+	alertTemplateController := http.NewAlertTemplateController(alertTemplateUseCase, config.Log)
+	messageFilterController := http.NewMessageFilterController(messageFilterUseCase, config.Log)
+	speechController := http.NewSpeechController(speechUseCase, config.Log)
+	goalController := http.NewGoalController(goalUseCase, config.Log)
+	leaderboardController := http.NewLeaderboardController(leaderboardUseCase, config.Log)
+	healthController := http.NewHealthController(healthUseCase, config.Log)
 
 	// setup middleware
-	authMiddleware := middleware.NewAuth(userUseCase, tokenUtil)
+	authMiddleware := middleware.NewAuth(userUseCase, tokenUtil, config.Log)
+	metricsMiddleware := middleware.NewMetrics(appMetrics)
+	metricsAuthMiddleware := middleware.NewMetricsAuth(config.Config.Metrics.Token)
+	tracingMiddleware := middleware.NewTracing()
+	requestIDMiddleware := middleware.NewRequestID()
+	localeMiddleware := middleware.NewLocale(config.Translator)
+
+	var rateLimitConfig atomic.Pointer[middleware.RateLimitConfig]
+	rateLimitConfig.Store(NewRateLimitConfig(config.Config))
+	rateLimitMiddleware := middleware.NewRateLimit(util.NewRateLimiter(config.Redis), tokenUtil, config.Log, rateLimitConfig.Load)
+	accessLogMiddleware := func(ctx *fiber.Ctx) error { return ctx.Next() }
+	if config.Config.Log.Access.Enabled {
+		accessLogMiddleware = middleware.NewAccessLog(config.Log, middleware.AccessLogConfig{
+			SampleRate: config.Config.Log.Access.SampleRate,
+			Redact:     config.Config.Log.Access.Redact,
+			Body:       config.Config.Log.Access.Body,
+		})
+	}
 
-	err := config.DB.AutoMigrate(&entity.User{})
-    if err != nil {
-        config.Log.Fatalf("Gagal migrasi database: %v", err)
-    }
 	routeConfig := route.RouteConfig{
 		App: config.App,
 		UserController: userController,
+		ChannelController: channelController,
+		DonationController: donationController,
+		PaymentController: paymentController,
+		OverlayController: overlayController,
+		AlertController: alertController,
+		AlertTemplateController: alertTemplateController,
+		MessageFilterController: messageFilterController,
+		SpeechController: speechController,
+		GoalController: goalController,
+		LeaderboardController: leaderboardController,
+		HealthController: healthController,
 		AuthMiddleware: authMiddleware,
+		MetricsMiddleware: metricsMiddleware,
+		TracingMiddleware: tracingMiddleware,
+		RequestIDMiddleware: requestIDMiddleware,
+		LocaleMiddleware: localeMiddleware,
+		RateLimitMiddleware: rateLimitMiddleware,
+		AccessLogMiddleware: accessLogMiddleware,
+		MetricsAuthMiddleware: metricsAuthMiddleware,
+		MetricsHandler: adaptor.HTTPHandler(promhttp.HandlerFor(appMetrics.Registry, promhttp.HandlerOpts{})),
 	}
 
 	routeConfig.Setup()
+
+	application := &Application{
+		UserUseCase: userUseCase,
+		ChannelUseCase: channelUseCase,
+		DonationUseCase: donationUseCase,
+		PaymentUseCase: paymentUseCase,
+		OverlayUseCase: overlayUseCase,
+		AlertUseCase: alertUseCase,
+		AlertTemplateUseCase: alertTemplateUseCase,
+		MessageFilterUseCase: messageFilterUseCase,
+		SpeechUseCase: speechUseCase,
+		GoalUseCase: goalUseCase,
+		LeaderboardUseCase: leaderboardUseCase,
+		EventHub: eventHub,
+		HealthUseCase: healthUseCase,
+		TokenUtil: tokenUtil,
+		Workers: []Hook{
+			NewTickerWorker("alert-scheduler", time.Duration(config.Config.Alert.TickInterval)*time.Millisecond,
+				config.Log, alertUseCase.Tick),
+			NewTickerWorker("speech", time.Duration(config.Config.Speech.TickInterval)*time.Millisecond,
+				config.Log, speechUseCase.Tick),
+		},
+	}
+
+	if config.Lifecycle != nil && config.RunWorkers {
+		for _, worker := range application.Workers {
+			config.Lifecycle.Append(worker)
+		}
+	}
+
+	// closing the hub ends every overlay connection, clients reconnect to
+	// another instance and resume where they left off
+	if config.Lifecycle != nil {
+		config.Lifecycle.Append(Hook{
+			Name: "event-hub",
+			OnStop: func(ctx context.Context) error {
+				return eventHub.Close()
+			},
+		})
+	}
+
+	// registered last so readiness flips to false before anything else stops,
+	// the drain delay gives the load balancer time to stop routing here
+	if config.Lifecycle != nil {
+		drainDelay := time.Duration(config.Config.Web.DrainDelay) * time.Second
+		config.Lifecycle.Append(Hook{
+			Name: "readiness",
+			OnStart: func(ctx context.Context) error {
+				healthUseCase.SetReady(true)
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				healthUseCase.SetReady(false)
+				select {
+				case <-time.After(drainDelay):
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			},
+		})
+	}
+
+	if config.Reloader != nil {
+		config.Reloader.OnReload(func(appConfig *AppConfig) {
+			config.Log.SetLevel(logrus.Level(appConfig.Log.Level))
+			rateLimitConfig.Store(NewRateLimitConfig(appConfig))
+		})
+	}
+
+	return application
 }
\ No newline at end of file
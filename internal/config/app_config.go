@@ -0,0 +1,323 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// AppConfig is the typed view of config.json. It is decoded once at startup
+// and handed to every constructor instead of reading keys from viper ad hoc.
+type AppConfig struct {
+	App      AppSection      `mapstructure:"app"`
+	Web      WebSection      `mapstructure:"web"`
+	Log      LogSection      `mapstructure:"log"`
+	Database DatabaseSection `mapstructure:"database"`
+	Redis    RedisSection    `mapstructure:"redis"`
+	Jwt      JwtSection      `mapstructure:"jwt"`
+	Tracing  TracingSection  `mapstructure:"tracing"`
+	Metrics  MetricsSection  `mapstructure:"metrics"`
+	I18n      I18nSection      `mapstructure:"i18n"`
+	RateLimit RateLimitSection `mapstructure:"rate_limit"`
+	Worker    WorkerSection    `mapstructure:"worker"`
+	Payment   PaymentSection   `mapstructure:"payment"`
+	Overlay   OverlaySection   `mapstructure:"overlay"`
+	Alert     AlertSection     `mapstructure:"alert"`
+	Speech    SpeechSection    `mapstructure:"tts"`
+	Leaderboard LeaderboardSection `mapstructure:"leaderboard"`
+}
+
+type AppSection struct {
+	Name string `mapstructure:"name" validate:"required,max=100"`
+}
+
+type WorkerSection struct {
+	// Embedded runs the background workers inside "serve". Disable it when
+	// workers are deployed separately with "streamhelp worker".
+	Embedded bool `mapstructure:"embedded"`
+}
+
+type WebSection struct {
+	Prefork         bool `mapstructure:"prefork"`
+	Port            int  `mapstructure:"port" validate:"required,min=1,max=65535"`
+	ShutdownTimeout int  `mapstructure:"shutdown_timeout" validate:"min=0"`
+	// DrainDelay is how many seconds the server keeps serving after readiness
+	// turned false, so the load balancer notices before connections close.
+	DrainDelay int `mapstructure:"drain_delay" validate:"min=0"`
+	// ProblemDetails always renders errors as RFC 7807 problem+json instead
+	// of only when the client asks for it in Accept.
+	ProblemDetails bool `mapstructure:"problem_details"`
+	// ProxyHeader names the header a reverse proxy puts the client IP in,
+	// such as X-Real-IP. It is only believed on requests coming from one of
+	// TrustedProxies, given as IPs or CIDR ranges.
+	ProxyHeader    string   `mapstructure:"proxy_header"`
+	TrustedProxies []string `mapstructure:"trusted_proxies" validate:"dive,ip|cidr"`
+}
+
+type LogSection struct {
+	Level  int              `mapstructure:"level" validate:"min=0,max=6"`
+	Access AccessLogSection `mapstructure:"access"`
+}
+
+type AccessLogSection struct {
+	Enabled    bool     `mapstructure:"enabled"`
+	SampleRate float64  `mapstructure:"sample_rate" validate:"min=0,max=1"`
+	Redact     []string `mapstructure:"redact"`
+	Body       bool     `mapstructure:"body"`
+}
+
+type DatabaseSection struct {
+	DBName         string `mapstructure:"dbname" validate:"required"`
+	User           string `mapstructure:"user" validate:"required"`
+	Password       string `mapstructure:"password"`
+	Host           string `mapstructure:"host" validate:"required"`
+	Port           int    `mapstructure:"port" validate:"required,min=1,max=65535"`
+	SSLMode        string `mapstructure:"sslmode" validate:"required"`
+	ConnectTimeout int    `mapstructure:"connect_timeout" validate:"min=0"`
+	Timezone       string `mapstructure:"timezone" validate:"required"`
+}
+
+type RedisSection struct {
+	Addr     string `mapstructure:"addr" validate:"required"`
+	Password string `mapstructure:"password"`
+	DB       int    `mapstructure:"db" validate:"min=0"`
+}
+
+type JwtSection struct {
+	Secret string `mapstructure:"secret" validate:"required"`
+}
+
+type I18nSection struct {
+	DefaultLanguage string `mapstructure:"default_language" validate:"required,oneof=id en"`
+}
+
+type RateLimitSection struct {
+	Enabled  bool                    `mapstructure:"enabled"`
+	Default  RateLimitRuleSection    `mapstructure:"default"`
+	Policies []RateLimitPolicySection `mapstructure:"policies" validate:"dive"`
+	APIKeys  []string                `mapstructure:"api_keys"`
+}
+
+// RateLimitRuleSection limits are requests per window, 0 means unlimited.
+type RateLimitRuleSection struct {
+	Window        int `mapstructure:"window" validate:"min=0"`
+	Anonymous     int `mapstructure:"anonymous" validate:"min=0"`
+	Authenticated int `mapstructure:"authenticated" validate:"min=0"`
+	APIKey        int `mapstructure:"api_key" validate:"min=0"`
+}
+
+type RateLimitPolicySection struct {
+	Name                 string `mapstructure:"name" validate:"required"`
+	Method               string `mapstructure:"method"`
+	Path                 string `mapstructure:"path" validate:"required"`
+	RateLimitRuleSection `mapstructure:",squash"`
+}
+
+type PaymentSection struct {
+	// Provider charges donations. "simulator" never leaves the machine and
+	// is paid with "streamhelp payment simulate".
+	Provider string `mapstructure:"provider" validate:"required,oneof=midtrans simulator"`
+	// Expiry is how many minutes a donor has to pay.
+	Expiry    int                     `mapstructure:"expiry" validate:"min=1"`
+	Midtrans  MidtransSection         `mapstructure:"midtrans"`
+	Simulator PaymentSimulatorSection `mapstructure:"simulator"`
+}
+
+type MidtransSection struct {
+	ServerKey string `mapstructure:"server_key"`
+	BaseURL   string `mapstructure:"base_url" validate:"omitempty,url"`
+	// Timeout of one API call in seconds.
+	Timeout int `mapstructure:"timeout" validate:"min=0"`
+}
+
+type PaymentSimulatorSection struct {
+	Secret string `mapstructure:"secret"`
+}
+
+type OverlaySection struct {
+	// HeartbeatInterval is how many seconds pass between two pings.
+	HeartbeatInterval int `mapstructure:"heartbeat_interval" validate:"min=1"`
+	// History is how many events per channel are kept for replay.
+	History int64 `mapstructure:"history" validate:"min=1"`
+	// ReplayLimit caps how many missed events a reconnecting overlay gets.
+	ReplayLimit int64 `mapstructure:"replay_limit" validate:"min=1"`
+	// Buffer is how many events an overlay may fall behind before it is
+	// disconnected and has to resume.
+	Buffer int `mapstructure:"buffer" validate:"min=1"`
+}
+
+type AlertSection struct {
+	// TickInterval is how many milliseconds pass between two runs of the
+	// alert scheduler, the precision of alert durations.
+	TickInterval int `mapstructure:"tick_interval" validate:"min=10"`
+	// History is how many finished alerts per channel can be replayed.
+	History int64 `mapstructure:"history" validate:"min=1"`
+}
+
+type SpeechSection struct {
+	// Engine reads donation messages aloud. "stub" never leaves the machine
+	// and only produces silence.
+	Engine string `mapstructure:"engine" validate:"required,oneof=stub"`
+	// Dir is where audio is stored, BaseURL where overlays fetch it from,
+	// normally the API's /api/speech.
+	Dir     string `mapstructure:"dir" validate:"required"`
+	BaseURL string `mapstructure:"base_url" validate:"required"`
+	// TickInterval is how many milliseconds pass between two runs of the
+	// speech worker, which takes on up to Batch jobs each time.
+	TickInterval int `mapstructure:"tick_interval" validate:"min=10"`
+	Batch        int `mapstructure:"batch" validate:"min=1"`
+	// Attempts is how often a job is tried before its alert is shown
+	// without audio.
+	Attempts int `mapstructure:"attempts" validate:"min=1"`
+	// Timeout is how many seconds a job may take before another worker
+	// takes it over.
+	Timeout int `mapstructure:"timeout" validate:"min=1"`
+}
+
+type LeaderboardSection struct {
+	// Timezone is where weekly boards, starting on Monday, and monthly
+	// boards begin at midnight.
+	Timezone string `mapstructure:"timezone" validate:"required,timezone"`
+	// Top is how many donors are sent to overlays when a ranking changes.
+	Top int64 `mapstructure:"top" validate:"min=1,max=100"`
+}
+
+type MetricsSection struct {
+	// Token is the bearer token scrapers send to read /metrics. Metrics are
+	// not served while it is empty.
+	Token string `mapstructure:"token"`
+}
+
+type TracingSection struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	Exporter    string  `mapstructure:"exporter" validate:"required_if=Enabled true,omitempty,oneof=otlp stdout"`
+	Endpoint    string  `mapstructure:"endpoint" validate:"required_if=Exporter otlp"`
+	Insecure    bool    `mapstructure:"insecure"`
+	SampleRatio float64 `mapstructure:"sample_ratio" validate:"min=0,max=1"`
+}
+
+func NewAppConfig(viper *viper.Viper, validate *validator.Validate) *AppConfig {
+	config, err := decodeAppConfig(viper, validate)
+	if err != nil {
+		panic(fmt.Errorf("Fatal error config file : %s \n", err))
+	}
+
+	return config
+}
+
+func decodeAppConfig(viper *viper.Viper, validate *validator.Validate) (*AppConfig, error) {
+	config := new(AppConfig)
+	if err := viper.Unmarshal(config); err != nil {
+		return nil, err
+	}
+
+	if err := validate.Struct(config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// ConfigReloader watches the config file and publishes a freshly decoded
+// AppConfig to its listeners. Only settings that are safe to change at
+// runtime (log level, rate limits) should be applied by listeners; the rest
+// take effect on the next restart.
+type ConfigReloader struct {
+	Viper    *viper.Viper
+	Validate *validator.Validate
+	Log      *logrus.Logger
+
+	current   atomic.Pointer[AppConfig]
+	mu        sync.Mutex
+	listeners []func(config *AppConfig)
+}
+
+func NewConfigReloader(viper *viper.Viper, validate *validator.Validate, log *logrus.Logger, config *AppConfig) *ConfigReloader {
+	reloader := &ConfigReloader{
+		Viper:    viper,
+		Validate: validate,
+		Log:      log,
+	}
+	reloader.current.Store(config)
+
+	return reloader
+}
+
+// Current returns the most recently loaded configuration.
+func (r *ConfigReloader) Current() *AppConfig {
+	return r.current.Load()
+}
+
+// OnReload registers a listener that is called after a valid config change.
+func (r *ConfigReloader) OnReload(listener func(config *AppConfig)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.listeners = append(r.listeners, listener)
+}
+
+// Watch starts watching the config file for changes.
+func (r *ConfigReloader) Watch() {
+	r.Viper.OnConfigChange(func(event fsnotify.Event) {
+		r.Reload()
+	})
+	r.Viper.WatchConfig()
+}
+
+// Reload decodes and validates the config again. An invalid file is logged
+// and ignored so a typo never takes down a running server.
+func (r *ConfigReloader) Reload() {
+	config, err := decodeAppConfig(r.Viper, r.Validate)
+	if err != nil {
+		r.Log.Warnf("Ignoring invalid config change : %+v", err)
+		return
+	}
+
+	r.current.Store(config)
+	r.Log.Infof("Config reloaded")
+
+	r.mu.Lock()
+	listeners := append([]func(config *AppConfig){}, r.listeners...)
+	r.mu.Unlock()
+
+	for _, listener := range listeners {
+		listener(config)
+	}
+}
+
+const maskedValue = "********"
+
+// Masked returns a copy that is safe to print, with every secret replaced.
+func (c AppConfig) Masked() AppConfig {
+	if c.Database.Password != "" {
+		c.Database.Password = maskedValue
+	}
+	if c.Redis.Password != "" {
+		c.Redis.Password = maskedValue
+	}
+	if c.Jwt.Secret != "" {
+		c.Jwt.Secret = maskedValue
+	}
+	if c.Payment.Midtrans.ServerKey != "" {
+		c.Payment.Midtrans.ServerKey = maskedValue
+	}
+	if c.Payment.Simulator.Secret != "" {
+		c.Payment.Simulator.Secret = maskedValue
+	}
+	if c.Metrics.Token != "" {
+		c.Metrics.Token = maskedValue
+	}
+
+	apiKeys := make([]string, len(c.RateLimit.APIKeys))
+	for i := range apiKeys {
+		apiKeys[i] = maskedValue
+	}
+	c.RateLimit.APIKeys = apiKeys
+
+	return c
+}
@@ -1,29 +1,125 @@
 package config
 
 import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"streamhelper-backend/internal/i18n"
+	"streamhelper-backend/internal/model"
+
 	"github.com/gofiber/fiber/v2"
-	"github.com/spf13/viper"
 )
 
-func NewFiber(config *viper.Viper) *fiber.App {
+const mimeProblemJSON = "application/problem+json"
+
+func NewFiber(config *AppConfig, translator *i18n.Translator) *fiber.App {
 	app := fiber.New(fiber.Config{
-		AppName: viper.GetString("app.name"),
-		Prefork: viper.GetBool("web.prefork"),
-		ErrorHandler: NewErrorHandler(),
+		AppName: config.App.Name,
+		Prefork: config.Web.Prefork,
+		ErrorHandler: NewErrorHandler(config, translator),
+		ProxyHeader: config.Web.ProxyHeader,
+		EnableTrustedProxyCheck: config.Web.ProxyHeader != "",
+		TrustedProxies: config.Web.TrustedProxies,
+		EnableIPValidation: true,
 	})
 	return app
 }
 
 
-func NewErrorHandler() fiber.ErrorHandler {
+func NewErrorHandler(config *AppConfig, translator *i18n.Translator) fiber.ErrorHandler {
 	return func (ctx *fiber.Ctx, err error) error {
-		code := fiber.StatusInternalServerError
-		if e, ok := err.(*fiber.Error); ok {
-			code = e.Code
+		appError := translate(translator, ctx, ToAppError(err))
+
+		if config.Web.ProblemDetails || acceptsProblemJSON(ctx) {
+			requestID, _ := ctx.Locals("request_id").(string)
+			ctx.Set(fiber.HeaderContentType, mimeProblemJSON)
+			return ctx.Status(appError.Status).JSON(model.ProblemDetail{
+				Type:      "about:blank",
+				Title:     http.StatusText(appError.Status),
+				Status:    appError.Status,
+				Detail:    appError.Message,
+				Instance:  ctx.OriginalURL(),
+				Code:      appError.Code,
+				Fields:    appError.Fields,
+				RequestID: requestID,
+			}, mimeProblemJSON)
+		}
+
+		body := fiber.Map{
+			"errors" : appError.Message,
+			"code" : appError.Code,
+		}
+		if len(appError.Fields) > 0 {
+			body["fields"] = appError.Fields
+		}
+
+		return  ctx.Status(appError.Status).JSON(body)
+	}
+}
+
+// ToAppError maps any error returned by a handler to an AppError. Errors
+// that are neither AppError nor fiber.Error are internal and their message is
+// not exposed.
+func ToAppError(err error) *model.AppError {
+	var appError *model.AppError
+	if errors.As(err, &appError) {
+		return appError
+	}
+
+	var fiberError *fiber.Error
+	if errors.As(err, &fiberError) {
+		return model.NewAppError(fiberError.Code, codeForStatus(fiberError.Code), fiberError.Message)
+	}
+
+	return model.NewAppError(fiber.StatusInternalServerError, model.ErrCodeInternal, "Internal Server Error").Wrap(err)
+}
+
+// translate returns a copy of appError with the message and field messages
+// in the language negotiated for this request.
+func translate(translator *i18n.Translator, ctx *fiber.Ctx, appError *model.AppError) *model.AppError {
+	locale, _ := ctx.Locals("locale").(string)
+	locale = translator.Resolve(locale)
+
+	translated := *appError
+	translated.Message = translator.Message(locale, appError.Code, appError.Message)
+	if len(appError.Validation) == len(appError.Fields) && len(appError.Fields) > 0 {
+		messages := translator.Validation(locale, appError.Validation)
+		translated.Fields = make([]model.FieldError, len(appError.Fields))
+		for i, field := range appError.Fields {
+			field.Message = messages[i]
+			translated.Fields[i] = field
 		}
+	}
 
-		return  ctx.Status(code).JSON(fiber.Map{
-			"errors" : err.Error(),
-		})
+	ctx.Set(fiber.HeaderContentLanguage, locale)
+	return &translated
+}
+
+func codeForStatus(status int) string {
+	switch status {
+	case fiber.StatusBadRequest:
+		return model.ErrCodeBadRequest
+	case fiber.StatusUnauthorized:
+		return model.ErrCodeUnauthorized
+	case fiber.StatusForbidden:
+		return model.ErrCodeForbidden
+	case fiber.StatusNotFound:
+		return model.ErrCodeNotFound
+	case fiber.StatusConflict:
+		return model.ErrCodeConflict
+	case fiber.StatusTooManyRequests:
+		return model.ErrCodeTooManyRequests
+	case fiber.StatusServiceUnavailable:
+		return model.ErrCodeServiceUnavailable
+	}
+
+	if status >= fiber.StatusInternalServerError {
+		return model.ErrCodeInternal
 	}
+	return model.ErrCodeBadRequest
+}
+
+func acceptsProblemJSON(ctx *fiber.Ctx) bool {
+	return strings.Contains(ctx.Get(fiber.HeaderAccept), mimeProblemJSON)
 }
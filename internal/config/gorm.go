@@ -1,29 +1,35 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/sirupsen/logrus"
-	"github.com/spf13/viper"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
-func NewDatabase(viper *viper.Viper, log *logrus.Logger) *gorm.DB {
-	dbname := viper.GetString("database.dbname")
-    user := viper.GetString("database.user")
-    password := viper.GetString("database.password")
-    sslmode := viper.GetString("database.sslmode")
-    timezone := viper.GetString("database.timezone")
-    host := viper.GetString("database.host")
-    port := viper.GetString("database.port")
-    timeout := viper.GetString("database.connect_timeout")
-	
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s connect_timeout=%s TimeZone=%s",
-        host, user, password, dbname, port, sslmode, timeout, timezone)
-	db , err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+func NewDatabase(config *AppConfig, log *logrus.Logger) *gorm.DB {
+	database := config.Database
+
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s connect_timeout=%d TimeZone=%s",
+		database.Host, database.User, database.Password, database.DBName, database.Port,
+		database.SSLMode, database.ConnectTimeout, database.Timezone)
+	db , err := gorm.Open(postgres.Open(dsn), NewGormConfig(log))
+
+	if err != nil {
+		log.Fatalf("failed to connect database : %v ", err)
+	}
+
+	return db
+}
+
+// NewGormConfig routes GORM's query log through logrus. It is shared with the
+// test harness, which opens its own dialector.
+func NewGormConfig(log *logrus.Logger) *gorm.Config {
+	return &gorm.Config{
 		Logger: logger.New(&logrusWriter{Logger: log}, logger.Config{
 			SlowThreshold: time.Second * 5,
 			Colorful: false,
@@ -31,13 +37,7 @@ func NewDatabase(viper *viper.Viper, log *logrus.Logger) *gorm.DB {
 			ParameterizedQueries: true,
 			LogLevel: logger.Info,
 		}),
-	})
-
-	if err != nil {
-		log.Fatalf("failed to connect database : %v ", err)
 	}
-
-	return db
 }
 
 type logrusWriter struct {
@@ -46,4 +46,18 @@ type logrusWriter struct {
 
 func (l *logrusWriter) Printf(message string, args ...interface{}) {
 	l.Logger.Tracef(message, args...)
-}
\ No newline at end of file
+}
+
+// DatabaseHook closes the connection pool when the lifecycle stops.
+func DatabaseHook(db *gorm.DB) Hook {
+	return Hook{
+		Name: "database",
+		OnStop: func(ctx context.Context) error {
+			sqlDB, err := db.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.Close()
+		},
+	}
+}
@@ -0,0 +1,17 @@
+package config
+
+import (
+	"streamhelper-backend/internal/i18n"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+func NewTranslator(config *AppConfig, validate *validator.Validate, log *logrus.Logger) *i18n.Translator {
+	translator, err := i18n.NewTranslator(validate, config.I18n.DefaultLanguage)
+	if err != nil {
+		log.Fatalf("Failed to register validator translations : %v", err)
+	}
+
+	return translator
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Hook is a named pair of start/stop functions for a subsystem. Either
+// function may be nil.
+type Hook struct {
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+// Lifecycle starts hooks in registration order and stops them in reverse, so
+// something registered after its dependencies is always stopped before them.
+type Lifecycle struct {
+	Log *logrus.Logger
+
+	mu      sync.Mutex
+	hooks   []Hook
+	started int
+}
+
+func NewLifecycle(log *logrus.Logger) *Lifecycle {
+	return &Lifecycle{
+		Log: log,
+	}
+}
+
+func (l *Lifecycle) Append(hook Hook) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.hooks = append(l.hooks, hook)
+}
+
+// Start runs every OnStart function. When one fails, the hooks that already
+// started are stopped again before the error is returned.
+func (l *Lifecycle) Start(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for l.started < len(l.hooks) {
+		hook := l.hooks[l.started]
+		if hook.OnStart != nil {
+			l.Log.Debugf("Starting %s", hook.Name)
+			if err := hook.OnStart(ctx); err != nil {
+				stopErr := l.stop(ctx)
+				return errors.Join(fmt.Errorf("start %s : %w", hook.Name, err), stopErr)
+			}
+		}
+		l.started++
+	}
+
+	return nil
+}
+
+// Stop runs the OnStop function of every started hook in reverse order. All
+// hooks are given a chance to stop even if an earlier one fails.
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.stop(ctx)
+}
+
+func (l *Lifecycle) stop(ctx context.Context) error {
+	var errs []error
+	for ; l.started > 0; l.started-- {
+		hook := l.hooks[l.started-1]
+		if hook.OnStop == nil {
+			continue
+		}
+
+		l.Log.Debugf("Stopping %s", hook.Name)
+		if err := hook.OnStop(ctx); err != nil {
+			l.Log.Warnf("Failed to stop %s : %+v", hook.Name, err)
+			errs = append(errs, fmt.Errorf("stop %s : %w", hook.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -1,15 +1,19 @@
 package config
 
 import (
+	"streamhelper-backend/internal/tracing"
+	"streamhelper-backend/internal/util"
+
 	"github.com/sirupsen/logrus"
-	"github.com/spf13/viper"
 )
 
-func NewLogger(viper *viper.Viper) *logrus.Logger{
+func NewLogger(config *AppConfig) *logrus.Logger{
 	log := logrus.New()
 
-	log.SetLevel(logrus.Level(viper.GetInt32("log.level")))
+	log.SetLevel(logrus.Level(config.Log.Level))
 	log.SetFormatter(&logrus.JSONFormatter{})
+	log.AddHook(tracing.NewLogrusHook())
+	log.AddHook(util.NewContextFieldsHook())
 
 	return log
-}
\ No newline at end of file
+}
@@ -0,0 +1,19 @@
+package config
+
+import (
+	"io/fs"
+	"streamhelper-backend/db"
+	"streamhelper-backend/internal/migration"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+func NewMigrator(database *gorm.DB, log *logrus.Logger) *migration.Migrator {
+	files, err := fs.Sub(db.Migrations, "migrations")
+	if err != nil {
+		log.Fatalf("Failed to load embedded migrations : %v", err)
+	}
+
+	return migration.NewMigrator(database, log, files)
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"streamhelper-backend/internal/payment"
+	"streamhelper-backend/internal/usecase"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// NewPaymentProvider returns the provider selected by payment.provider.
+func NewPaymentProvider(config *AppConfig, redisClient *redis.Client, log *logrus.Logger) usecase.PaymentProvider {
+	switch config.Payment.Provider {
+	case "midtrans":
+		if config.Payment.Midtrans.ServerKey == "" {
+			log.Fatalf("payment.midtrans.server_key is required for the midtrans provider")
+		}
+		baseURL := config.Payment.Midtrans.BaseURL
+		if baseURL == "" {
+			baseURL = payment.MidtransSandboxURL
+		}
+		return payment.NewMidtrans(config.Payment.Midtrans.ServerKey, baseURL,
+			time.Duration(config.Payment.Midtrans.Timeout)*time.Second)
+	default:
+		return NewPaymentSimulator(config, redisClient, log)
+	}
+}
+
+// NewPaymentSimulator is used by the simulator provider and by the CLI that
+// fires its callbacks, both must agree on the secret.
+func NewPaymentSimulator(config *AppConfig, redisClient *redis.Client, log *logrus.Logger) *payment.Simulator {
+	if config.Payment.Simulator.Secret == "" {
+		log.Fatalf("payment.simulator.secret is required for the simulator provider")
+	}
+	return payment.NewSimulator(config.Payment.Simulator.Secret, redisClient)
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"streamhelper-backend/internal/delivery/http/middleware"
+	"time"
+)
+
+// NewRateLimitConfig converts the config file section into the form the
+// rate-limit middleware works with.
+func NewRateLimitConfig(config *AppConfig) *middleware.RateLimitConfig {
+	section := config.RateLimit
+	rateLimitConfig := &middleware.RateLimitConfig{
+		Enabled: section.Enabled,
+		Default: newRateLimitRule(section.Default),
+		APIKeys: section.APIKeys,
+	}
+
+	for _, policy := range section.Policies {
+		rateLimitConfig.Policies = append(rateLimitConfig.Policies, middleware.RateLimitPolicy{
+			Name:   policy.Name,
+			Method: policy.Method,
+			Path:   policy.Path,
+			Rule:   newRateLimitRule(policy.RateLimitRuleSection),
+		})
+	}
+
+	return rateLimitConfig
+}
+
+func newRateLimitRule(section RateLimitRuleSection) middleware.RateLimitRule {
+	return middleware.RateLimitRule{
+		Window:        time.Duration(section.Window) * time.Second,
+		Anonymous:     section.Anonymous,
+		Authenticated: section.Authenticated,
+		APIKey:        section.APIKey,
+	}
+}
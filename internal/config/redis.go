@@ -0,0 +1,25 @@
+package config
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func NewRedis(config *AppConfig) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     config.Redis.Addr,
+		Password: config.Redis.Password,
+		DB:       config.Redis.DB,
+	})
+}
+
+// RedisHook closes the client when the lifecycle stops.
+func RedisHook(client *redis.Client) Hook {
+	return Hook{
+		Name: "redis",
+		OnStop: func(ctx context.Context) error {
+			return client.Close()
+		},
+	}
+}
@@ -0,0 +1,15 @@
+package config
+
+import (
+	"streamhelper-backend/internal/speech"
+	"streamhelper-backend/internal/usecase"
+)
+
+// NewSynthesizer returns the engine selected by tts.engine. Only the offline
+// stub exists so far, engines calling a speech service are added here.
+func NewSynthesizer(config *AppConfig) usecase.Synthesizer {
+	switch config.Speech.Engine {
+	default:
+		return speech.NewStub()
+	}
+}
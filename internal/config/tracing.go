@@ -0,0 +1,71 @@
+package config
+
+import (
+	"context"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// NewTracerProvider installs the global tracer provider and the W3C
+// trace-context propagator. With tracing disabled spans are still created
+// but never sampled, so instrumentation code needs no special casing.
+func NewTracerProvider(config *AppConfig, log *logrus.Logger) *sdktrace.TracerProvider {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	tracing := config.Tracing
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(config.App.Name))),
+	}
+
+	if !tracing.Enabled {
+		options = append(options, sdktrace.WithSampler(sdktrace.NeverSample()))
+	} else {
+		exporter, err := newSpanExporter(tracing)
+		if err != nil {
+			log.Fatalf("Failed to create trace exporter : %v", err)
+		}
+		options = append(options,
+			sdktrace.WithBatcher(exporter),
+			sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(tracing.SampleRatio))),
+		)
+	}
+
+	provider := sdktrace.NewTracerProvider(options...)
+	otel.SetTracerProvider(provider)
+
+	return provider
+}
+
+func newSpanExporter(tracing TracingSection) (sdktrace.SpanExporter, error) {
+	if tracing.Exporter == "stdout" {
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	}
+
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(tracing.Endpoint)}
+	if tracing.Insecure {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	// the exporter connects lazily, so a missing collector never blocks startup
+	return otlptracehttp.New(context.Background(), options...)
+}
+
+// TracerProviderHook flushes buffered spans when the lifecycle stops.
+func TracerProviderHook(provider *sdktrace.TracerProvider) Hook {
+	return Hook{
+		Name: "tracing",
+		OnStop: func(ctx context.Context) error {
+			return provider.Shutdown(ctx)
+		},
+	}
+}
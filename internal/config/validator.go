@@ -1,10 +1,62 @@
 package config
 
 import (
+	"net/url"
+	"reflect"
+	"regexp"
+	"streamhelper-backend/internal/alert"
+	"strings"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/spf13/viper"
 )
 
 func NewValidator(viper *viper.Viper) *validator.Validate {
-	return validator.New()
-}
\ No newline at end of file
+	validate := validator.New()
+
+	// report fields by their JSON name, which is what API clients know
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" || name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	if err := validate.RegisterValidation("slug", validateSlug); err != nil {
+		panic(err)
+	}
+	if err := validate.RegisterValidation("alert_template", validateAlertTemplate); err != nil {
+		panic(err)
+	}
+	if err := validate.RegisterValidation("asset_url", validateAssetURL); err != nil {
+		panic(err)
+	}
+
+	return validate
+}
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// validateSlug accepts lowercase URL path segments of 3 to 50 characters.
+func validateSlug(field validator.FieldLevel) bool {
+	slug := field.Field().String()
+	return len(slug) >= 3 && len(slug) <= 50 && slugPattern.MatchString(slug)
+}
+
+// validateAlertTemplate accepts text that alert.ParseTemplate can parse.
+func validateAlertTemplate(field validator.FieldLevel) bool {
+	_, err := alert.ParseTemplate(field.Field().String())
+	return err == nil
+}
+
+// validateAssetURL accepts nothing, or an absolute http or https URL the
+// overlay can load an image or sound from.
+func validateAssetURL(field validator.FieldLevel) bool {
+	value := field.Field().String()
+	if value == "" {
+		return true
+	}
+	parsed, err := url.Parse(value)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
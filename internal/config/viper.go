@@ -13,6 +13,13 @@ func NewViper() *viper.Viper{
 	config.SetConfigType("json")
 	config.AddConfigPath(".././")
 	config.AddConfigPath("./")
+	config.SetDefault("web.shutdown_timeout", 15)
+	config.SetDefault("web.drain_delay", 5)
+	config.SetDefault("i18n.default_language", "id")
+	config.SetDefault("worker.embedded", true)
+	config.SetDefault("log.access.sample_rate", 1.0)
+	config.SetDefault("log.access.redact", []string{"password", "token", "authorization", "key"})
+	config.SetDefault("log.access.body", false)
 	err := config.ReadInConfig()
 	if err != nil {
 		panic(fmt.Errorf("Fatal error config file : %s \n", err))
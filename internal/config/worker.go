@@ -0,0 +1,52 @@
+package config
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// NewTickerWorker returns a hook that runs tick every interval between start
+// and stop. A failing tick is logged and retried on the next one. OnStop
+// waits for a running tick to return.
+func NewTickerWorker(name string, interval time.Duration, log *logrus.Logger, tick func(ctx context.Context, now time.Time) error) Hook {
+	var cancel context.CancelFunc
+	done := make(chan struct{})
+
+	return Hook{
+		Name: name,
+		OnStart: func(ctx context.Context) error {
+			var workerCtx context.Context
+			workerCtx, cancel = context.WithCancel(context.Background())
+
+			go func() {
+				defer close(done)
+
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-workerCtx.Done():
+						return
+					case now := <-ticker.C:
+						if err := tick(workerCtx, now); err != nil {
+							log.Warnf("Worker %s failed : %+v", name, err)
+						}
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+}
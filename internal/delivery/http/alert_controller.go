@@ -0,0 +1,111 @@
+package http
+
+import (
+	"context"
+	"streamhelper-backend/internal/delivery/http/middleware"
+	"streamhelper-backend/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type AlertController struct {
+	Log     *logrus.Logger
+	UseCase AlertUseCase
+}
+
+func NewAlertController(useCase AlertUseCase, logger *logrus.Logger) *AlertController {
+	return &AlertController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+func (c *AlertController) Get(ctx *fiber.Ctx) error {
+	return c.queue(ctx, c.UseCase.Get, "Failed to get alert queue")
+}
+
+func (c *AlertController) Pause(ctx *fiber.Ctx) error {
+	return c.queue(ctx, c.UseCase.Pause, "Failed to pause alert queue")
+}
+
+func (c *AlertController) Resume(ctx *fiber.Ctx) error {
+	return c.queue(ctx, c.UseCase.Resume, "Failed to resume alert queue")
+}
+
+func (c *AlertController) Approve(ctx *fiber.Ctx) error {
+	return c.act(ctx, c.UseCase.Approve, "Failed to approve alert")
+}
+
+func (c *AlertController) Reject(ctx *fiber.Ctx) error {
+	return c.act(ctx, c.UseCase.Reject, "Failed to reject alert")
+}
+
+func (c *AlertController) Skip(ctx *fiber.Ctx) error {
+	return c.act(ctx, c.UseCase.Skip, "Failed to skip alert")
+}
+
+func (c *AlertController) Replay(ctx *fiber.Ctx) error {
+	return c.act(ctx, c.UseCase.Replay, "Failed to replay alert")
+}
+
+func (c *AlertController) GetSettings(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.GetAlertQueueRequest{UserID: auth.ID}
+	response, err := c.UseCase.GetSettings(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to get alert settings")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.AlertSettingsResponse]{Data: response})
+}
+
+func (c *AlertController) UpdateSettings(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.UpdateAlertSettingsRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	request.UserID = auth.ID
+	response, err := c.UseCase.UpdateSettings(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to update alert settings")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.AlertSettingsResponse]{Data: response})
+}
+
+func (c *AlertController) queue(ctx *fiber.Ctx, call func(context.Context, *model.GetAlertQueueRequest) (*model.AlertQueueResponse, error), message string) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.GetAlertQueueRequest{UserID: auth.ID}
+	response, err := call(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warn(message)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.AlertQueueResponse]{Data: response})
+}
+
+func (c *AlertController) act(ctx *fiber.Ctx, call func(context.Context, *model.AlertActionRequest) (*model.AlertResponse, error), message string) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.AlertActionRequest{
+		UserID:  auth.ID,
+		AlertID: ctx.Params("alertId"),
+	}
+	response, err := call(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warn(message)
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.AlertResponse]{Data: response})
+}
@@ -0,0 +1,124 @@
+package http
+
+import (
+	"streamhelper-backend/internal/delivery/http/middleware"
+	"streamhelper-backend/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type AlertTemplateController struct {
+	Log     *logrus.Logger
+	UseCase AlertTemplateUseCase
+}
+
+func NewAlertTemplateController(useCase AlertTemplateUseCase, logger *logrus.Logger) *AlertTemplateController {
+	return &AlertTemplateController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+func (c *AlertTemplateController) Create(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.CreateAlertTemplateRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	request.UserID = auth.ID
+	response, err := c.UseCase.Create(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to create alert template")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.AlertTemplateResponse]{Data: response})
+}
+
+func (c *AlertTemplateController) Update(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.UpdateAlertTemplateRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	request.UserID = auth.ID
+	request.TemplateID = ctx.Params("templateId")
+	response, err := c.UseCase.Update(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to update alert template")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.AlertTemplateResponse]{Data: response})
+}
+
+func (c *AlertTemplateController) Delete(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.GetAlertTemplateRequest{
+		UserID:     auth.ID,
+		TemplateID: ctx.Params("templateId"),
+	}
+	response, err := c.UseCase.Delete(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to delete alert template")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[bool]{Data: response})
+}
+
+func (c *AlertTemplateController) Get(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.GetAlertTemplateRequest{
+		UserID:     auth.ID,
+		TemplateID: ctx.Params("templateId"),
+	}
+	response, err := c.UseCase.Get(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to get alert template")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.AlertTemplateResponse]{Data: response})
+}
+
+func (c *AlertTemplateController) List(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.ListAlertTemplateRequest{UserID: auth.ID}
+	response, err := c.UseCase.List(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to list alert templates")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[[]model.AlertTemplateResponse]{Data: response})
+}
+
+func (c *AlertTemplateController) Preview(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.PreviewAlertTemplateRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	request.UserID = auth.ID
+	response, err := c.UseCase.Preview(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to preview alert template")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.AlertDisplay]{Data: response})
+}
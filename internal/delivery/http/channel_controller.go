@@ -0,0 +1,89 @@
+package http
+
+import (
+	"streamhelper-backend/internal/delivery/http/middleware"
+	"streamhelper-backend/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type ChannelController struct {
+	Log     *logrus.Logger
+	UseCase ChannelUseCase
+}
+
+func NewChannelController(useCase ChannelUseCase, logger *logrus.Logger) *ChannelController {
+	return &ChannelController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+func (c *ChannelController) Create(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.CreateChannelRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	request.UserID = auth.ID
+	response, err := c.UseCase.Create(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to create channel")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.ChannelResponse]{Data: response})
+}
+
+func (c *ChannelController) Get(ctx *fiber.Ctx) error {
+	request := &model.GetChannelRequest{
+		Slug: ctx.Params("slug"),
+	}
+
+	response, err := c.UseCase.Get(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to get channel")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.ChannelResponse]{Data: response})
+}
+
+func (c *ChannelController) Current(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.GetCurrentChannelRequest{
+		UserID: auth.ID,
+	}
+
+	response, err := c.UseCase.Current(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to get current channel")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.ChannelResponse]{Data: response})
+}
+
+func (c *ChannelController) Update(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.UpdateChannelRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	request.UserID = auth.ID
+	response, err := c.UseCase.Update(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to update channel")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.ChannelResponse]{Data: response})
+}
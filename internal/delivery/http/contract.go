@@ -0,0 +1,120 @@
+package http
+
+import (
+	"context"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/usecase"
+)
+
+// UserUseCase is what UserController calls, implemented by
+// usecase.UserUseCase.
+type UserUseCase interface {
+	Create(ctx context.Context, request *model.RegisterUserRequest) (*model.UserResponse, error)
+	Login(ctx context.Context, request *model.LoginUserRequest) (*model.UserResponse, error)
+	Current(ctx context.Context, request *model.GetUserRequest) (*model.UserResponse, error)
+	Logout(ctx context.Context, request *model.LogoutUserRequest) (bool, error)
+	Update(ctx context.Context, request *model.UpdateUserRequest) (*model.UserResponse, error)
+}
+
+// ChannelUseCase is what ChannelController calls, implemented by
+// usecase.ChannelUseCase.
+type ChannelUseCase interface {
+	Create(ctx context.Context, request *model.CreateChannelRequest) (*model.ChannelResponse, error)
+	Update(ctx context.Context, request *model.UpdateChannelRequest) (*model.ChannelResponse, error)
+	Get(ctx context.Context, request *model.GetChannelRequest) (*model.ChannelResponse, error)
+	Current(ctx context.Context, request *model.GetCurrentChannelRequest) (*model.ChannelResponse, error)
+}
+
+// DonationUseCase is what DonationController calls, implemented by
+// usecase.DonationUseCase.
+type DonationUseCase interface {
+	Create(ctx context.Context, request *model.CreateDonationRequest) (*model.DonationResponse, error)
+	Search(ctx context.Context, request *model.SearchDonationRequest) (*model.PageResponse[model.DonationResponse], error)
+}
+
+// PaymentUseCase is what PaymentController calls, implemented by
+// usecase.PaymentUseCase.
+type PaymentUseCase interface {
+	Notify(ctx context.Context, request *model.PaymentNotificationRequest) (bool, error)
+	Get(ctx context.Context, request *model.GetDonationPaymentRequest) (*model.DonationResponse, error)
+	Refund(ctx context.Context, request *model.RefundDonationRequest) (*model.DonationResponse, error)
+}
+
+// OverlayUseCase is what OverlayController calls, implemented by
+// usecase.OverlayUseCase.
+type OverlayUseCase interface {
+	Get(ctx context.Context, request *model.GetOverlayRequest) (*model.OverlayResponse, error)
+	RotateKey(ctx context.Context, request *model.GetOverlayRequest) (*model.OverlayResponse, error)
+	Connect(ctx context.Context, request *model.ConnectOverlayRequest) (*usecase.OverlaySession, error)
+	Feed(ctx context.Context, request *model.GetEventFeedRequest) (*usecase.OverlaySession, error)
+	Ack(ctx context.Context, request *model.AckOverlayRequest) error
+}
+
+// AlertUseCase is what AlertController calls, implemented by
+// usecase.AlertUseCase.
+type AlertUseCase interface {
+	Get(ctx context.Context, request *model.GetAlertQueueRequest) (*model.AlertQueueResponse, error)
+	Pause(ctx context.Context, request *model.GetAlertQueueRequest) (*model.AlertQueueResponse, error)
+	Resume(ctx context.Context, request *model.GetAlertQueueRequest) (*model.AlertQueueResponse, error)
+	Approve(ctx context.Context, request *model.AlertActionRequest) (*model.AlertResponse, error)
+	Reject(ctx context.Context, request *model.AlertActionRequest) (*model.AlertResponse, error)
+	Skip(ctx context.Context, request *model.AlertActionRequest) (*model.AlertResponse, error)
+	Replay(ctx context.Context, request *model.AlertActionRequest) (*model.AlertResponse, error)
+	GetSettings(ctx context.Context, request *model.GetAlertQueueRequest) (*model.AlertSettingsResponse, error)
+	UpdateSettings(ctx context.Context, request *model.UpdateAlertSettingsRequest) (*model.AlertSettingsResponse, error)
+}
+
+// AlertTemplateUseCase is what AlertTemplateController calls, implemented by
+// usecase.AlertTemplateUseCase.
+type AlertTemplateUseCase interface {
+	Create(ctx context.Context, request *model.CreateAlertTemplateRequest) (*model.AlertTemplateResponse, error)
+	Update(ctx context.Context, request *model.UpdateAlertTemplateRequest) (*model.AlertTemplateResponse, error)
+	Delete(ctx context.Context, request *model.GetAlertTemplateRequest) (bool, error)
+	Get(ctx context.Context, request *model.GetAlertTemplateRequest) (*model.AlertTemplateResponse, error)
+	List(ctx context.Context, request *model.ListAlertTemplateRequest) ([]model.AlertTemplateResponse, error)
+	Preview(ctx context.Context, request *model.PreviewAlertTemplateRequest) (*model.AlertDisplay, error)
+}
+
+// MessageFilterUseCase is what MessageFilterController calls, implemented
+// by usecase.MessageFilterUseCase.
+type MessageFilterUseCase interface {
+	Check(ctx context.Context, request *model.CheckMessageRequest) (*model.FilterMessageResponse, error)
+	GetSettings(ctx context.Context, request *model.GetMessageFilterRequest) (*model.MessageFilterSettingsResponse, error)
+	UpdateSettings(ctx context.Context, request *model.UpdateMessageFilterSettingsRequest) (*model.MessageFilterSettingsResponse, error)
+	ListTerms(ctx context.Context, request *model.GetMessageFilterRequest) ([]model.BlockedTermResponse, error)
+	CreateTerm(ctx context.Context, request *model.CreateBlockedTermRequest) (*model.BlockedTermResponse, error)
+	DeleteTerm(ctx context.Context, request *model.DeleteBlockedTermRequest) (bool, error)
+	SearchAudit(ctx context.Context, request *model.SearchFilteredMessageRequest) (*model.PageResponse[model.FilteredMessageResponse], error)
+}
+
+// SpeechUseCase is what SpeechController calls, implemented by
+// usecase.SpeechUseCase.
+type SpeechUseCase interface {
+	GetSettings(ctx context.Context, request *model.GetSpeechSettingsRequest) (*model.SpeechSettingsResponse, error)
+	UpdateSettings(ctx context.Context, request *model.UpdateSpeechSettingsRequest) (*model.SpeechSettingsResponse, error)
+	ListVoices(ctx context.Context, request *model.GetSpeechSettingsRequest) ([]model.SpeechVoiceResponse, error)
+	Audio(ctx context.Context, request *model.GetSpeechAudioRequest) (*model.SpeechAudioResponse, error)
+}
+
+// GoalUseCase is what GoalController calls, implemented by usecase.GoalUseCase.
+type GoalUseCase interface {
+	Create(ctx context.Context, request *model.CreateGoalRequest) (*model.GoalResponse, error)
+	Update(ctx context.Context, request *model.UpdateGoalRequest) (*model.GoalResponse, error)
+	End(ctx context.Context, request *model.GetGoalRequest) (*model.GoalResponse, error)
+	Delete(ctx context.Context, request *model.GetGoalRequest) (bool, error)
+	Get(ctx context.Context, request *model.GetGoalRequest) (*model.GoalResponse, error)
+	List(ctx context.Context, request *model.ListGoalRequest) ([]model.GoalResponse, error)
+	History(ctx context.Context, request *model.SearchGoalHistoryRequest) (*model.PageResponse[model.GoalResponse], error)
+	ListChannel(ctx context.Context, request *model.ListChannelGoalRequest) ([]model.GoalResponse, error)
+}
+
+// LeaderboardUseCase is what LeaderboardController calls, implemented by
+// usecase.LeaderboardUseCase.
+type LeaderboardUseCase interface {
+	GetSettings(ctx context.Context, request *model.GetLeaderboardSettingsRequest) (*model.LeaderboardSettingsResponse, error)
+	UpdateSettings(ctx context.Context, request *model.UpdateLeaderboardSettingsRequest) (*model.LeaderboardSettingsResponse, error)
+	Get(ctx context.Context, request *model.GetLeaderboardRequest) (*model.LeaderboardResponse, error)
+	GetChannel(ctx context.Context, request *model.GetChannelLeaderboardRequest) (*model.LeaderboardResponse, error)
+	StartStream(ctx context.Context, request *model.StartStreamRequest) (*model.LeaderboardResponse, error)
+	Rebuild(ctx context.Context, request *model.RebuildLeaderboardRequest) (bool, error)
+}
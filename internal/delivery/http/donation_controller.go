@@ -0,0 +1,60 @@
+package http
+
+import (
+	"streamhelper-backend/internal/delivery/http/middleware"
+	"streamhelper-backend/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type DonationController struct {
+	Log     *logrus.Logger
+	UseCase DonationUseCase
+}
+
+func NewDonationController(useCase DonationUseCase, logger *logrus.Logger) *DonationController {
+	return &DonationController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+func (c *DonationController) Create(ctx *fiber.Ctx) error {
+	request := new(model.CreateDonationRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	request.Slug = ctx.Params("slug")
+	response, err := c.UseCase.Create(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to create donation")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.DonationResponse]{Data: response})
+}
+
+func (c *DonationController) List(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.SearchDonationRequest{
+		UserID: auth.ID,
+		Status: ctx.Query("status"),
+		Page:   ctx.QueryInt("page", 1),
+		Size:   ctx.QueryInt("size", 10),
+	}
+
+	response, err := c.UseCase.Search(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to search donations")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[[]model.DonationResponse]{
+		Data:   response.Data,
+		Paging: &response.PageMetadata,
+	})
+}
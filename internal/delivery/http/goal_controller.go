@@ -0,0 +1,153 @@
+package http
+
+import (
+	"streamhelper-backend/internal/delivery/http/middleware"
+	"streamhelper-backend/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type GoalController struct {
+	Log     *logrus.Logger
+	UseCase GoalUseCase
+}
+
+func NewGoalController(useCase GoalUseCase, logger *logrus.Logger) *GoalController {
+	return &GoalController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+func (c *GoalController) Create(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.CreateGoalRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	request.UserID = auth.ID
+	response, err := c.UseCase.Create(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to create goal")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.GoalResponse]{Data: response})
+}
+
+func (c *GoalController) Update(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.UpdateGoalRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	request.UserID = auth.ID
+	request.GoalID = ctx.Params("goalId")
+	response, err := c.UseCase.Update(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to update goal")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.GoalResponse]{Data: response})
+}
+
+func (c *GoalController) End(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.GetGoalRequest{
+		UserID: auth.ID,
+		GoalID: ctx.Params("goalId"),
+	}
+	response, err := c.UseCase.End(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to end goal")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.GoalResponse]{Data: response})
+}
+
+func (c *GoalController) Delete(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.GetGoalRequest{
+		UserID: auth.ID,
+		GoalID: ctx.Params("goalId"),
+	}
+	response, err := c.UseCase.Delete(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to delete goal")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[bool]{Data: response})
+}
+
+func (c *GoalController) Get(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.GetGoalRequest{
+		UserID: auth.ID,
+		GoalID: ctx.Params("goalId"),
+	}
+	response, err := c.UseCase.Get(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to get goal")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.GoalResponse]{Data: response})
+}
+
+func (c *GoalController) List(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.ListGoalRequest{UserID: auth.ID}
+	response, err := c.UseCase.List(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to list goals")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[[]model.GoalResponse]{Data: response})
+}
+
+func (c *GoalController) History(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.SearchGoalHistoryRequest{
+		UserID: auth.ID,
+		Page:   ctx.QueryInt("page", 1),
+		Size:   ctx.QueryInt("size", 10),
+	}
+
+	response, err := c.UseCase.History(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to search goal history")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[[]model.GoalResponse]{
+		Data:   response.Data,
+		Paging: &response.PageMetadata,
+	})
+}
+
+func (c *GoalController) ListChannel(ctx *fiber.Ctx) error {
+	request := &model.ListChannelGoalRequest{Slug: ctx.Params("slug")}
+	response, err := c.UseCase.ListChannel(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to list channel goals")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[[]model.GoalResponse]{Data: response})
+}
@@ -0,0 +1,37 @@
+package http
+
+import (
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/usecase"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type HealthController struct {
+	Log     *logrus.Logger
+	UseCase *usecase.HealthUseCase
+}
+
+func NewHealthController(useCase *usecase.HealthUseCase, logger *logrus.Logger) *HealthController {
+	return &HealthController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+func (c *HealthController) Liveness(ctx *fiber.Ctx) error {
+	response := c.UseCase.Liveness(ctx.UserContext())
+	return ctx.JSON(model.WebResponse[*model.HealthResponse]{Data: response})
+}
+
+func (c *HealthController) Readiness(ctx *fiber.Ctx) error {
+	response := c.UseCase.Readiness(ctx.UserContext())
+
+	status := fiber.StatusOK
+	if response.Status != usecase.HealthStatusUp {
+		status = fiber.StatusServiceUnavailable
+	}
+
+	return ctx.Status(status).JSON(model.WebResponse[*model.HealthResponse]{Data: response})
+}
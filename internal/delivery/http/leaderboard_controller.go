@@ -0,0 +1,121 @@
+package http
+
+import (
+	"streamhelper-backend/internal/delivery/http/middleware"
+	"streamhelper-backend/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type LeaderboardController struct {
+	Log     *logrus.Logger
+	UseCase LeaderboardUseCase
+}
+
+func NewLeaderboardController(useCase LeaderboardUseCase, logger *logrus.Logger) *LeaderboardController {
+	return &LeaderboardController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+func (c *LeaderboardController) GetSettings(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.GetLeaderboardSettingsRequest{UserID: auth.ID}
+	response, err := c.UseCase.GetSettings(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to get leaderboard settings")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.LeaderboardSettingsResponse]{Data: response})
+}
+
+func (c *LeaderboardController) UpdateSettings(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.UpdateLeaderboardSettingsRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	request.UserID = auth.ID
+	response, err := c.UseCase.UpdateSettings(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to update leaderboard settings")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.LeaderboardSettingsResponse]{Data: response})
+}
+
+func (c *LeaderboardController) Get(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.GetLeaderboardRequest{
+		UserID: auth.ID,
+		Board:  ctx.Params("board"),
+		Page:   ctx.QueryInt("page", 1),
+		Size:   ctx.QueryInt("size", 10),
+	}
+
+	response, err := c.UseCase.Get(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to get leaderboard")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.LeaderboardResponse]{
+		Data:   response,
+		Paging: &response.Paging,
+	})
+}
+
+func (c *LeaderboardController) GetChannel(ctx *fiber.Ctx) error {
+	request := &model.GetChannelLeaderboardRequest{
+		Slug:  ctx.Params("slug"),
+		Board: ctx.Params("board"),
+		Page:  ctx.QueryInt("page", 1),
+		Size:  ctx.QueryInt("size", 10),
+	}
+
+	response, err := c.UseCase.GetChannel(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to get channel leaderboard")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.LeaderboardResponse]{
+		Data:   response,
+		Paging: &response.Paging,
+	})
+}
+
+func (c *LeaderboardController) StartStream(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.StartStreamRequest{UserID: auth.ID}
+	response, err := c.UseCase.StartStream(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to start stream leaderboard")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.LeaderboardResponse]{Data: response})
+}
+
+func (c *LeaderboardController) Rebuild(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.RebuildLeaderboardRequest{UserID: auth.ID}
+	response, err := c.UseCase.Rebuild(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to rebuild leaderboards")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[bool]{Data: response})
+}
@@ -0,0 +1,142 @@
+package http
+
+import (
+	"streamhelper-backend/internal/delivery/http/middleware"
+	"streamhelper-backend/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type MessageFilterController struct {
+	Log     *logrus.Logger
+	UseCase MessageFilterUseCase
+}
+
+func NewMessageFilterController(useCase MessageFilterUseCase, logger *logrus.Logger) *MessageFilterController {
+	return &MessageFilterController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+func (c *MessageFilterController) GetSettings(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.GetMessageFilterRequest{UserID: auth.ID}
+	response, err := c.UseCase.GetSettings(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to get message filter settings")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.MessageFilterSettingsResponse]{Data: response})
+}
+
+func (c *MessageFilterController) UpdateSettings(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.UpdateMessageFilterSettingsRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	request.UserID = auth.ID
+	response, err := c.UseCase.UpdateSettings(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to update message filter settings")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.MessageFilterSettingsResponse]{Data: response})
+}
+
+func (c *MessageFilterController) Check(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.CheckMessageRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	request.UserID = auth.ID
+	response, err := c.UseCase.Check(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to check message")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.FilterMessageResponse]{Data: response})
+}
+
+func (c *MessageFilterController) ListTerms(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.GetMessageFilterRequest{UserID: auth.ID}
+	response, err := c.UseCase.ListTerms(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to list blocked terms")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[[]model.BlockedTermResponse]{Data: response})
+}
+
+func (c *MessageFilterController) CreateTerm(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.CreateBlockedTermRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	request.UserID = auth.ID
+	response, err := c.UseCase.CreateTerm(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to create blocked term")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.BlockedTermResponse]{Data: response})
+}
+
+func (c *MessageFilterController) DeleteTerm(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.DeleteBlockedTermRequest{
+		UserID: auth.ID,
+		TermID: ctx.Params("termId"),
+	}
+	response, err := c.UseCase.DeleteTerm(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to delete blocked term")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[bool]{Data: response})
+}
+
+func (c *MessageFilterController) SearchAudit(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.SearchFilteredMessageRequest{
+		UserID: auth.ID,
+		Action: ctx.Query("action"),
+		Page:   ctx.QueryInt("page", 1),
+		Size:   ctx.QueryInt("size", 10),
+	}
+
+	response, err := c.UseCase.SearchAudit(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to search filtered messages")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[[]model.FilteredMessageResponse]{
+		Data:   response.Data,
+		Paging: &response.PageMetadata,
+	})
+}
@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const redacted = "[REDACTED]"
+
+type AccessLogConfig struct {
+	// SampleRate is the fraction of successful requests that are logged.
+	// Client and server errors are always logged.
+	SampleRate float64
+	// Redact lists query parameter and JSON body keys whose values must never
+	// reach the logs, compared case-insensitively.
+	Redact []string
+	// Body logs request bodies too. Redact cannot know every sensitive key,
+	// so it stays off unless asked for while debugging.
+	Body bool
+}
+
+func NewAccessLog(log *logrus.Logger, config AccessLogConfig) fiber.Handler {
+	redact := make(map[string]bool, len(config.Redact))
+	for _, key := range config.Redact {
+		redact[strings.ToLower(key)] = true
+	}
+
+	return func(ctx *fiber.Ctx) error {
+		start := time.Now()
+
+		if err := ctx.Next(); err != nil {
+			if err := ctx.App().ErrorHandler(ctx, err); err != nil {
+				_ = ctx.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		status := ctx.Response().StatusCode()
+		if status < fiber.StatusBadRequest && rand.Float64() >= config.SampleRate {
+			return nil
+		}
+
+		fields := logrus.Fields{
+			"route":      ctx.Route().Path,
+			"status":     status,
+			"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+			"user_agent": ctx.Get(fiber.HeaderUserAgent),
+		}
+		// reading a streamed body would wait for the stream to end
+		if !ctx.Response().IsBodyStream() {
+			fields["bytes_out"] = len(ctx.Response().Body())
+		}
+		if query := string(ctx.Request().URI().QueryString()); query != "" {
+			fields["query"] = redactQuery(query, redact)
+		}
+		if config.Body && len(ctx.Body()) > 0 {
+			fields["body"] = redactBody(ctx.Body(), redact)
+		}
+
+		entry := log.WithContext(ctx.UserContext()).WithFields(fields)
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			entry.Error("access")
+		case status >= fiber.StatusBadRequest:
+			entry.Warn("access")
+		default:
+			entry.Info("access")
+		}
+
+		return nil
+	}
+}
+
+func redactQuery(query string, redact map[string]bool) string {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return redacted
+	}
+
+	for key := range values {
+		if redact[strings.ToLower(key)] {
+			values[key] = []string{redacted}
+		}
+	}
+	return values.Encode()
+}
+
+func redactBody(body []byte, redact map[string]bool) any {
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		// not JSON, so we cannot tell what is sensitive in it
+		return redacted
+	}
+
+	return redactValue(value, redact)
+}
+
+func redactValue(value any, redact map[string]bool) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if redact[strings.ToLower(key)] {
+				v[key] = redacted
+			} else {
+				v[key] = redactValue(item, redact)
+			}
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = redactValue(item, redact)
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -2,25 +2,33 @@ package middleware
 
 import (
 	"streamhelper-backend/internal/model"
-	"streamhelper-backend/internal/usecase"
 	"streamhelper-backend/internal/util"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
 )
 
-func NewAuth(userUserCase *usecase.UserUseCase, tokenUtli *util.TokenUtil) fiber.Handler{
+func NewAuth(userUserCase UserLanguageProvider, tokenParser TokenParser, log *logrus.Logger) fiber.Handler{
 	return func(ctx *fiber.Ctx) error  {
 		request := &model.VerifyUserRequest{Token: ctx.Get("Authorization", "NOT_FOUND")}
-		userUserCase.Log.Debugf("Authorization :%s", request.Token )
 
-		auth , err := tokenUtli.ParseToken(ctx.UserContext(), request.Token)
+		auth , err := tokenParser.ParseToken(ctx.UserContext(), request.Token)
 		if err != nil {
-			userUserCase.Log.Warnf("Failed find user by token : %+v", err)
+			log.WithContext(ctx.UserContext()).Warnf("Failed find user by token : %+v", err)
 			return fiber.ErrUnauthorized
 		}
 
-		userUserCase.Log.Debugf("User : %+v", auth.ID)
+		util.AddLogField(ctx.UserContext(), "user_id", auth.ID)
+		log.WithContext(ctx.UserContext()).Debugf("User : %+v", auth.ID)
 		ctx.Locals("auth", auth)
+
+		// an explicit Accept-Language wins over the profile preference
+		if !localeNegotiated(ctx) {
+			if language, err := userUserCase.Language(ctx.UserContext(), auth.ID); err == nil && language != "" {
+				SetLocale(ctx, language)
+			}
+		}
+
 		return ctx.Next()
 	}
 }
@@ -0,0 +1,16 @@
+package middleware
+
+import (
+	"context"
+	"streamhelper-backend/internal/model"
+)
+
+// TokenParser resolves an access token to the caller it was issued to.
+type TokenParser interface {
+	ParseToken(ctx context.Context, token string) (*model.Auth, error)
+}
+
+// UserLanguageProvider returns the language stored on a user's profile.
+type UserLanguageProvider interface {
+	Language(ctx context.Context, id string) (string, error)
+}
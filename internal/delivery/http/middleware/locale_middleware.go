@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"streamhelper-backend/internal/i18n"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// NewLocale negotiates the response language from Accept-Language. When the
+// header names nothing we support, the auth middleware may still switch to
+// the user's preferred language later in the chain.
+func NewLocale(translator *i18n.Translator) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		language := translator.Negotiate(ctx.Get(fiber.HeaderAcceptLanguage))
+		ctx.Locals("locale_negotiated", language != "")
+		SetLocale(ctx, translator.Resolve(language))
+
+		return ctx.Next()
+	}
+}
+
+func GetLocale(ctx *fiber.Ctx) string {
+	locale, _ := ctx.Locals("locale").(string)
+	return locale
+}
+
+func SetLocale(ctx *fiber.Ctx, locale string) {
+	ctx.Locals("locale", locale)
+	ctx.Set(fiber.HeaderContentLanguage, locale)
+}
+
+func localeNegotiated(ctx *fiber.Ctx) bool {
+	negotiated, _ := ctx.Locals("locale_negotiated").(bool)
+	return negotiated
+}
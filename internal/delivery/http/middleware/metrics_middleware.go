@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"strconv"
+	"streamhelper-backend/internal/metrics"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func NewMetrics(m *metrics.Metrics) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		start := time.Now()
+
+		// run the error handler here so the recorded status matches what the
+		// client receives, the same way fiber's logger middleware does
+		if err := ctx.Next(); err != nil {
+			if err := ctx.App().ErrorHandler(ctx, err); err != nil {
+				_ = ctx.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		route := ctx.Route().Path
+		status := strconv.Itoa(ctx.Response().StatusCode())
+		if status == "404" && route == "/" {
+			route = "unmatched"
+		}
+
+		m.HTTPRequests.WithLabelValues(ctx.Method(), route, status).Inc()
+		m.HTTPRequestDuration.WithLabelValues(ctx.Method(), route, status).Observe(time.Since(start).Seconds())
+		return nil
+	}
+}
+
+// NewMetricsAuth lets through requests carrying token as a bearer token.
+// Without a token nobody may read the metrics and they are reported missing.
+func NewMetricsAuth(token string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		if token == "" {
+			return fiber.ErrNotFound
+		}
+
+		bearer, ok := strings.CutPrefix(ctx.Get(fiber.HeaderAuthorization), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			return fiber.ErrUnauthorized
+		}
+		return ctx.Next()
+	}
+}
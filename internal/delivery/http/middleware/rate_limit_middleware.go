@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/util"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	CallerAnonymous     = "anonymous"
+	CallerAuthenticated = "authenticated"
+	CallerAPIKey        = "api_key"
+
+	HeaderAPIKey = "X-API-Key"
+)
+
+// RateLimitRule holds the number of requests allowed per window for each
+// kind of caller. A limit of 0 means that caller is not limited.
+type RateLimitRule struct {
+	Window        time.Duration
+	Anonymous     int
+	Authenticated int
+	APIKey        int
+}
+
+// RateLimitPolicy applies a rule to one route. Path segments starting with
+// ":" match any single segment and a trailing "*" matches the rest.
+type RateLimitPolicy struct {
+	Name   string
+	Method string
+	Path   string
+	Rule   RateLimitRule
+}
+
+type RateLimitConfig struct {
+	Enabled  bool
+	Default  RateLimitRule
+	Policies []RateLimitPolicy
+	APIKeys  []string
+}
+
+// NewRateLimit enforces the policy returned by current on every request.
+// current is read per request so policies can be hot reloaded.
+func NewRateLimit(limiter *util.RateLimiter, tokenParser TokenParser, log *logrus.Logger, current func() *RateLimitConfig) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		config := current()
+		if config == nil || !config.Enabled {
+			return ctx.Next()
+		}
+
+		name, rule := matchPolicy(config, ctx.Method(), ctx.Path())
+		caller, identity := identifyCaller(ctx, config, tokenParser)
+
+		limit := rule.Anonymous
+		switch caller {
+		case CallerAuthenticated:
+			limit = rule.Authenticated
+		case CallerAPIKey:
+			limit = rule.APIKey
+		}
+		if limit <= 0 || rule.Window <= 0 {
+			return ctx.Next()
+		}
+
+		key := fmt.Sprintf("ratelimit:%s:%s:%s", name, caller, identity)
+		result, err := limiter.Allow(ctx.UserContext(), key, limit, rule.Window)
+		if err != nil {
+			// fail open, an unavailable Redis must not take the API down
+			log.WithContext(ctx.UserContext()).Warnf("Failed to check rate limit : %+v", err)
+			return ctx.Next()
+		}
+
+		reset := int(math.Ceil(result.Reset.Seconds()))
+		ctx.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit, int(rule.Window.Seconds())))
+		ctx.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		ctx.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		ctx.Set("RateLimit-Reset", strconv.Itoa(reset))
+
+		if !result.Allowed {
+			ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(reset))
+			log.WithContext(ctx.UserContext()).Warnf("Rate limit %s exceeded by %s caller", name, caller)
+			return model.ErrTooManyRequests
+		}
+
+		return ctx.Next()
+	}
+}
+
+func matchPolicy(config *RateLimitConfig, method string, path string) (string, RateLimitRule) {
+	for _, policy := range config.Policies {
+		if policy.Method != "" && !strings.EqualFold(policy.Method, method) {
+			continue
+		}
+		if matchPath(policy.Path, path) {
+			return policy.Name, policy.Rule
+		}
+	}
+	return "default", config.Default
+}
+
+func matchPath(pattern string, path string) bool {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i, segment := range patternSegments {
+		if segment == "*" {
+			return true
+		}
+		if i >= len(pathSegments) {
+			return false
+		}
+		if !strings.HasPrefix(segment, ":") && segment != pathSegments[i] {
+			return false
+		}
+	}
+	return len(patternSegments) == len(pathSegments)
+}
+
+// identifyCaller classifies the request. Unknown API keys and invalid tokens
+// fall back to anonymous, keyed by IP, so they cannot buy a larger budget.
+// Behind a reverse proxy the IP is the client's only when web.proxy_header
+// and web.trusted_proxies are configured.
+func identifyCaller(ctx *fiber.Ctx, config *RateLimitConfig, tokenParser TokenParser) (string, string) {
+	if apiKey := ctx.Get(HeaderAPIKey); apiKey != "" {
+		for _, known := range config.APIKeys {
+			if subtle.ConstantTimeCompare([]byte(known), []byte(apiKey)) == 1 {
+				sum := sha256.Sum256([]byte(apiKey))
+				return CallerAPIKey, hex.EncodeToString(sum[:8])
+			}
+		}
+	}
+
+	if token := ctx.Get(fiber.HeaderAuthorization); token != "" {
+		if auth, err := tokenParser.ParseToken(ctx.UserContext(), token); err == nil {
+			return CallerAuthenticated, auth.ID
+		}
+	}
+
+	return CallerAnonymous, ctx.IP()
+}
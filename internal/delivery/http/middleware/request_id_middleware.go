@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"streamhelper-backend/internal/util"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const maxRequestIDLength = 128
+
+// NewRequestID honours an incoming X-Request-ID (so a trace started at the
+// load balancer keeps its ID) or generates a new one, echoes it back and
+// seeds the request context with the fields every log line should carry.
+func NewRequestID() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		requestID := ctx.Get(fiber.HeaderXRequestID)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		ctx.Set(fiber.HeaderXRequestID, requestID)
+		ctx.Locals("request_id", requestID)
+		ctx.SetUserContext(util.WithLogFields(ctx.UserContext(), logrus.Fields{
+			"request_id": requestID,
+			"method":     ctx.Method(),
+			"path":       ctx.Path(),
+			"ip":         ctx.IP(),
+		}))
+
+		return ctx.Next()
+	}
+}
+
+func GetRequestID(ctx *fiber.Ctx) string {
+	requestID, _ := ctx.Locals("request_id").(string)
+	return requestID
+}
+
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range requestID {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
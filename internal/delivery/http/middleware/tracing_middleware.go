@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"fmt"
+	"streamhelper-backend/internal/tracing"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewTracing starts a server span for every request, continuing the trace of
+// an incoming W3C traceparent header when there is one.
+func NewTracing() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		carrier := &headerCarrier{ctx: ctx}
+		parent := otel.GetTextMapPropagator().Extract(ctx.UserContext(), carrier)
+
+		spanCtx, span := tracing.Start(parent, fmt.Sprintf("HTTP %s", ctx.Method()),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(ctx.Method()),
+				semconv.URLPath(ctx.Path()),
+				semconv.ClientAddress(ctx.IP()),
+				semconv.UserAgentOriginal(ctx.Get(fiber.HeaderUserAgent)),
+			))
+		defer span.End()
+
+		ctx.SetUserContext(spanCtx)
+		otel.GetTextMapPropagator().Inject(spanCtx, &responseCarrier{ctx: ctx})
+
+		err := ctx.Next()
+		if err != nil {
+			if err := ctx.App().ErrorHandler(ctx, err); err != nil {
+				_ = ctx.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		route := ctx.Route().Path
+		status := ctx.Response().StatusCode()
+		span.SetName(fmt.Sprintf("%s %s", ctx.Method(), route))
+		span.SetAttributes(semconv.HTTPRoute(route), semconv.HTTPResponseStatusCode(status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", status))
+		}
+		if err != nil {
+			span.RecordError(err)
+		}
+
+		return nil
+	}
+}
+
+type headerCarrier struct {
+	ctx *fiber.Ctx
+}
+
+func (c *headerCarrier) Get(key string) string {
+	return c.ctx.Get(key)
+}
+
+func (c *headerCarrier) Set(key string, value string) {
+	c.ctx.Request().Header.Set(key, value)
+}
+
+func (c *headerCarrier) Keys() []string {
+	keys := make([]string, 0)
+	c.ctx.Request().Header.VisitAll(func(key, value []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
+
+type responseCarrier struct {
+	ctx *fiber.Ctx
+}
+
+func (c *responseCarrier) Get(key string) string {
+	return string(c.ctx.Response().Header.Peek(key))
+}
+
+func (c *responseCarrier) Set(key string, value string) {
+	c.ctx.Set(key, value)
+}
+
+func (c *responseCarrier) Keys() []string {
+	keys := make([]string, 0)
+	c.ctx.Response().Header.VisitAll(func(key, value []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
@@ -0,0 +1,361 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"streamhelper-backend/internal/delivery/http/middleware"
+	"streamhelper-backend/internal/event"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/usecase"
+	"time"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// overlaySessionKey carries the session from the upgrade request to the
+// WebSocket handler.
+const overlaySessionKey = "overlaySession"
+
+// overlayWriteWait bounds every write to a WebSocket or event stream, a
+// client that cannot take a message in that time is gone.
+const overlayWriteWait = 10 * time.Second
+
+// streamRetry is how long EventSource waits before reconnecting.
+const streamRetry = 3 * time.Second
+
+type OverlayController struct {
+	Log     *logrus.Logger
+	UseCase OverlayUseCase
+	// HeartbeatInterval is how often the server pings. A client that
+	// misses two pings in a row is disconnected.
+	HeartbeatInterval time.Duration
+
+	websocket fiber.Handler
+}
+
+func NewOverlayController(useCase OverlayUseCase, logger *logrus.Logger, heartbeatInterval time.Duration) *OverlayController {
+	controller := &OverlayController{
+		Log:               logger,
+		UseCase:           useCase,
+		HeartbeatInterval: heartbeatInterval,
+	}
+	controller.websocket = websocket.New(controller.serve)
+
+	return controller
+}
+
+func (c *OverlayController) Get(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.GetOverlayRequest{UserID: auth.ID}
+	response, err := c.UseCase.Get(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to get overlay")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.OverlayResponse]{Data: response})
+}
+
+func (c *OverlayController) RotateKey(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.GetOverlayRequest{UserID: auth.ID}
+	response, err := c.UseCase.RotateKey(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to rotate overlay key")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.OverlayResponse]{Data: response})
+}
+
+// Connect authenticates the overlay before upgrading, so a wrong key is a
+// plain HTTP error, then hands the session to the WebSocket handler.
+func (c *OverlayController) Connect(ctx *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(ctx) {
+		return fiber.ErrUpgradeRequired
+	}
+
+	request := &model.ConnectOverlayRequest{
+		Key:         ctx.Query("key"),
+		Client:      ctx.Query("client", "default"),
+		LastEventID: ctx.Query("last_event_id"),
+	}
+
+	session, err := c.UseCase.Connect(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to connect overlay")
+		return err
+	}
+
+	ctx.Locals(overlaySessionKey, session)
+	if err := c.websocket(ctx); err != nil {
+		session.Subscription.Close()
+		return err
+	}
+
+	return nil
+}
+
+// Stream is the Server-Sent Events fallback of Connect for browser sources
+// and proxies that block WebSockets. EventSource resumes with the
+// Last-Event-ID header on its own.
+func (c *OverlayController) Stream(ctx *fiber.Ctx) error {
+	request := &model.ConnectOverlayRequest{
+		Key:         ctx.Query("key"),
+		Client:      ctx.Query("client", "default"),
+		LastEventID: ctx.Get("Last-Event-ID", ctx.Query("last_event_id")),
+	}
+
+	session, err := c.UseCase.Connect(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to connect overlay stream")
+		return err
+	}
+
+	return c.stream(ctx, session)
+}
+
+// Feed streams the events of the user's channel to the dashboard as
+// Server-Sent Events.
+func (c *OverlayController) Feed(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.GetEventFeedRequest{
+		UserID:      auth.ID,
+		LastEventID: ctx.Get("Last-Event-ID", ctx.Query("last_event_id")),
+	}
+
+	session, err := c.UseCase.Feed(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to open event feed")
+		return err
+	}
+
+	return c.stream(ctx, session)
+}
+
+// serve writes the session's events until the client or the hub goes away.
+// Reads happen on a second goroutine, writes only here.
+func (c *OverlayController) serve(conn *websocket.Conn) {
+	session := conn.Locals(overlaySessionKey).(*usecase.OverlaySession)
+	defer session.Subscription.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	err := c.write(conn, &model.OverlayMessage{
+		Op:                model.OverlayOpHello,
+		ChannelID:         session.ChannelID,
+		LastEventID:       session.LastEventID,
+		HeartbeatInterval: c.HeartbeatInterval.Milliseconds(),
+		Gap:               session.Gap,
+	})
+	if err != nil {
+		return
+	}
+
+	lastEventID := session.LastEventID
+	for i := range session.Replay {
+		if err := c.writeEvent(conn, &session.Replay[i]); err != nil {
+			return
+		}
+		lastEventID = session.Replay[i].ID
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.read(ctx, conn, session)
+	}()
+
+	ticker := time.NewTicker(c.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case liveEvent, ok := <-session.Subscription.Events():
+			if !ok {
+				c.closeSubscription(conn, session.Subscription.Err())
+				return
+			}
+			if lastEventID != "" && !event.After(liveEvent.ID, lastEventID) {
+				continue
+			}
+			if err := c.writeEvent(conn, &liveEvent); err != nil {
+				return
+			}
+			lastEventID = liveEvent.ID
+		case now := <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, now.Add(overlayWriteWait)); err != nil {
+				return
+			}
+			if err := c.write(conn, &model.OverlayMessage{Op: model.OverlayOpHeartbeat, Time: now.UnixMilli()}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// read handles acknowledgements. Any message or pong proves the client is
+// alive and extends the read deadline.
+func (c *OverlayController) read(ctx context.Context, conn *websocket.Conn, session *usecase.OverlaySession) {
+	timeout := 2*c.HeartbeatInterval + overlayWriteWait
+	_ = conn.SetReadDeadline(time.Now().Add(timeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(timeout))
+	})
+
+	for {
+		_, payload, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(timeout))
+
+		message := new(model.OverlayMessage)
+		if err := json.Unmarshal(payload, message); err != nil {
+			c.Log.WithContext(ctx).Warnf("Failed to parse overlay message : %+v", err)
+			continue
+		}
+
+		if message.Op == model.OverlayOpAck {
+			err := c.UseCase.Ack(ctx, &model.AckOverlayRequest{
+				ChannelID: session.ChannelID,
+				Client:    session.Client,
+				EventID:   message.ID,
+			})
+			if err != nil {
+				c.Log.WithContext(ctx).WithError(err).Warnf("Failed to acknowledge overlay event")
+			}
+		}
+	}
+}
+
+// stream writes the session as text/event-stream once the handler returned.
+// A client that stops reading either fails a write after overlayWriteWait or
+// falls behind until the hub drops it, both end the response and the client
+// reconnects with Last-Event-ID.
+func (c *OverlayController) stream(ctx *fiber.Ctx, session *usecase.OverlaySession) error {
+	ctx.Set(fiber.HeaderContentType, "text/event-stream")
+	ctx.Set(fiber.HeaderCacheControl, "no-cache")
+	ctx.Set(fiber.HeaderConnection, "keep-alive")
+	// nginx buffers responses unless told otherwise
+	ctx.Set("X-Accel-Buffering", "no")
+
+	conn := ctx.Context().Conn()
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer session.Subscription.Close()
+
+		flush := func() error {
+			_ = conn.SetWriteDeadline(time.Now().Add(overlayWriteWait))
+			return w.Flush()
+		}
+
+		hello, err := json.Marshal(&model.OverlayMessage{
+			Op:                model.OverlayOpHello,
+			ChannelID:         session.ChannelID,
+			LastEventID:       session.LastEventID,
+			HeartbeatInterval: c.HeartbeatInterval.Milliseconds(),
+			Gap:               session.Gap,
+		})
+		if err != nil {
+			return
+		}
+		fmt.Fprintf(w, "retry: %d\nevent: %s\ndata: %s\n\n", streamRetry.Milliseconds(), model.OverlayOpHello, hello)
+
+		lastEventID := session.LastEventID
+		for i := range session.Replay {
+			if err := writeStreamEvent(w, &session.Replay[i]); err != nil {
+				return
+			}
+			lastEventID = session.Replay[i].ID
+		}
+		if err := flush(); err != nil {
+			return
+		}
+
+		ticker := time.NewTicker(c.HeartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case liveEvent, ok := <-session.Subscription.Events():
+				if !ok {
+					if err := session.Subscription.Err(); err != nil {
+						fmt.Fprintf(w, ": %s\n\n", err.Error())
+						_ = flush()
+					}
+					return
+				}
+				if lastEventID != "" && !event.After(liveEvent.ID, lastEventID) {
+					continue
+				}
+				if err := writeStreamEvent(w, &liveEvent); err != nil {
+					return
+				}
+				lastEventID = liveEvent.ID
+			case <-ticker.C:
+				// comments keep proxies from closing an idle connection and
+				// reveal a client that went away
+				fmt.Fprint(w, ": keep-alive\n\n")
+			}
+			if err := flush(); err != nil {
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+// writeStreamEvent writes one event in the text/event-stream format, with
+// the whole event as data like the WebSocket sends it.
+func writeStreamEvent(w *bufio.Writer, liveEvent *event.Event) error {
+	payload, err := json.Marshal(liveEvent)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", liveEvent.ID, liveEvent.Type, payload)
+	return err
+}
+
+func (c *OverlayController) writeEvent(conn *websocket.Conn, liveEvent *event.Event) error {
+	payload, err := json.Marshal(liveEvent)
+	if err != nil {
+		return err
+	}
+	return c.write(conn, &model.OverlayMessage{Op: model.OverlayOpEvent, ID: liveEvent.ID, Event: payload})
+}
+
+func (c *OverlayController) write(conn *websocket.Conn, message *model.OverlayMessage) error {
+	_ = conn.SetWriteDeadline(time.Now().Add(overlayWriteWait))
+	return conn.WriteJSON(message)
+}
+
+// closeSubscription tells the client why the server ended the session. A
+// restart or a slow client means "reconnect and resume from your last
+// event", a revoked session has to be set up again with the new key.
+func (c *OverlayController) closeSubscription(conn *websocket.Conn, err error) {
+	code, text := websocket.CloseNormalClosure, ""
+	switch {
+	case errors.Is(err, event.ErrHubClosed):
+		code, text = websocket.CloseServiceRestart, "server restarting"
+	case errors.Is(err, event.ErrSlowSubscriber):
+		code, text = websocket.CloseTryAgainLater, "too slow"
+	case errors.Is(err, event.ErrRevoked):
+		code, text = websocket.ClosePolicyViolation, "overlay key rotated"
+	case err != nil:
+		code, text = websocket.CloseInternalServerErr, "internal error"
+	}
+
+	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(overlayWriteWait))
+}
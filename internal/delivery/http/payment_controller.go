@@ -0,0 +1,77 @@
+package http
+
+import (
+	"bytes"
+	"net/http"
+	"streamhelper-backend/internal/delivery/http/middleware"
+	"streamhelper-backend/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type PaymentController struct {
+	Log     *logrus.Logger
+	UseCase PaymentUseCase
+}
+
+func NewPaymentController(useCase PaymentUseCase, logger *logrus.Logger) *PaymentController {
+	return &PaymentController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+// Notify receives the provider's callbacks. The body is passed on untouched
+// because the signature is computed over it.
+func (c *PaymentController) Notify(ctx *fiber.Ctx) error {
+	request := &model.PaymentNotificationRequest{
+		Provider: ctx.Params("provider"),
+		Header:   http.Header(ctx.GetReqHeaders()),
+		Body:     bytes.Clone(ctx.Body()),
+	}
+
+	response, err := c.UseCase.Notify(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to handle payment notification")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[bool]{Data: response})
+}
+
+func (c *PaymentController) Get(ctx *fiber.Ctx) error {
+	request := &model.GetDonationPaymentRequest{
+		DonationID: ctx.Params("donationId"),
+	}
+
+	response, err := c.UseCase.Get(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to get donation payment")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.DonationResponse]{Data: response})
+}
+
+func (c *PaymentController) Refund(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.RefundDonationRequest)
+	if len(ctx.Body()) > 0 {
+		if err := ctx.BodyParser(request); err != nil {
+			c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse request body : %+v", err)
+			return fiber.ErrBadRequest
+		}
+	}
+
+	request.UserID = auth.ID
+	request.DonationID = ctx.Params("donationId")
+	response, err := c.UseCase.Refund(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to refund donation")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.DonationResponse]{Data: response})
+}
@@ -9,17 +9,60 @@ import (
 type RouteConfig struct {
 	App               *fiber.App
 	UserController    *http.UserController
+	ChannelController *http.ChannelController
+	DonationController *http.DonationController
+	PaymentController *http.PaymentController
+	OverlayController *http.OverlayController
+	AlertController   *http.AlertController
+	AlertTemplateController *http.AlertTemplateController
+	MessageFilterController *http.MessageFilterController
+	SpeechController  *http.SpeechController
+	GoalController    *http.GoalController
+	LeaderboardController *http.LeaderboardController
+	HealthController  *http.HealthController
 	AuthMiddleware    fiber.Handler
+	MetricsMiddleware fiber.Handler
+	TracingMiddleware fiber.Handler
+	RequestIDMiddleware fiber.Handler
+	LocaleMiddleware  fiber.Handler
+	RateLimitMiddleware fiber.Handler
+	AccessLogMiddleware fiber.Handler
+	MetricsAuthMiddleware fiber.Handler
+	MetricsHandler    fiber.Handler
 }
 
 func (c *RouteConfig) Setup() {
+	c.App.Use(c.RequestIDMiddleware)
+	c.App.Use(c.LocaleMiddleware)
+	c.App.Use(c.TracingMiddleware)
+	c.App.Use(c.AccessLogMiddleware)
+	c.App.Use(c.MetricsMiddleware)
+	// probes and scrapes are answered before the rate limit
+	c.SetupProbeRoute()
+	c.App.Use(c.RateLimitMiddleware)
 	c.SetupGuestRoute()
 	c.SetupAuthRoute()
 }
 
+func (c *RouteConfig) SetupProbeRoute() {
+	c.App.Get("/healthz", c.HealthController.Liveness)
+	c.App.Get("/readyz", c.HealthController.Readiness)
+	c.App.Get("/metrics", c.MetricsAuthMiddleware, c.MetricsHandler)
+}
+
 func (c *RouteConfig) SetupGuestRoute() {
 	c.App.Post("/api/users", c.UserController.Register)
 	c.App.Post("/api/users/_login", c.UserController.Login)
+
+	c.App.Get("/api/channels/:slug", c.ChannelController.Get)
+	c.App.Post("/api/channels/:slug/donations", c.DonationController.Create)
+	c.App.Get("/api/channels/:slug/goals", c.GoalController.ListChannel)
+	c.App.Get("/api/channels/:slug/leaderboards/:board", c.LeaderboardController.GetChannel)
+	c.App.Get("/api/donations/:donationId/payment", c.PaymentController.Get)
+	c.App.Post("/api/payments/:provider/notifications", c.PaymentController.Notify)
+	c.App.Get("/api/overlay/ws", c.OverlayController.Connect)
+	c.App.Get("/api/overlay/events", c.OverlayController.Stream)
+	c.App.Get("/api/speech/:name", c.SpeechController.Audio)
 }
 
 func (c *RouteConfig) SetupAuthRoute() {
@@ -28,4 +71,50 @@ func (c *RouteConfig) SetupAuthRoute() {
 	c.App.Delete("/api/users", c.UserController.Logout)
 	c.App.Patch("/api/users/_current", c.UserController.Update)
 	c.App.Get("/api/users/_current", c.UserController.Current)
+
+	c.App.Post("/api/channels", c.ChannelController.Create)
+	c.App.Get("/api/users/_current/channel", c.ChannelController.Current)
+	c.App.Patch("/api/users/_current/channel", c.ChannelController.Update)
+	c.App.Get("/api/users/_current/channel/donations", c.DonationController.List)
+	c.App.Post("/api/users/_current/channel/donations/:donationId/_refund", c.PaymentController.Refund)
+	c.App.Get("/api/users/_current/channel/overlay", c.OverlayController.Get)
+	c.App.Post("/api/users/_current/channel/overlay/_rotate", c.OverlayController.RotateKey)
+	c.App.Get("/api/users/_current/channel/events", c.OverlayController.Feed)
+	c.App.Get("/api/users/_current/channel/alerts", c.AlertController.Get)
+	c.App.Post("/api/users/_current/channel/alerts/_pause", c.AlertController.Pause)
+	c.App.Post("/api/users/_current/channel/alerts/_resume", c.AlertController.Resume)
+	c.App.Get("/api/users/_current/channel/alerts/_settings", c.AlertController.GetSettings)
+	c.App.Patch("/api/users/_current/channel/alerts/_settings", c.AlertController.UpdateSettings)
+	c.App.Post("/api/users/_current/channel/alerts/:alertId/_approve", c.AlertController.Approve)
+	c.App.Post("/api/users/_current/channel/alerts/:alertId/_reject", c.AlertController.Reject)
+	c.App.Post("/api/users/_current/channel/alerts/:alertId/_skip", c.AlertController.Skip)
+	c.App.Post("/api/users/_current/channel/alerts/:alertId/_replay", c.AlertController.Replay)
+	c.App.Get("/api/users/_current/channel/alert-templates", c.AlertTemplateController.List)
+	c.App.Post("/api/users/_current/channel/alert-templates", c.AlertTemplateController.Create)
+	c.App.Post("/api/users/_current/channel/alert-templates/_preview", c.AlertTemplateController.Preview)
+	c.App.Get("/api/users/_current/channel/alert-templates/:templateId", c.AlertTemplateController.Get)
+	c.App.Patch("/api/users/_current/channel/alert-templates/:templateId", c.AlertTemplateController.Update)
+	c.App.Delete("/api/users/_current/channel/alert-templates/:templateId", c.AlertTemplateController.Delete)
+	c.App.Get("/api/users/_current/channel/filter", c.MessageFilterController.GetSettings)
+	c.App.Patch("/api/users/_current/channel/filter", c.MessageFilterController.UpdateSettings)
+	c.App.Post("/api/users/_current/channel/filter/_check", c.MessageFilterController.Check)
+	c.App.Get("/api/users/_current/channel/filter/terms", c.MessageFilterController.ListTerms)
+	c.App.Post("/api/users/_current/channel/filter/terms", c.MessageFilterController.CreateTerm)
+	c.App.Delete("/api/users/_current/channel/filter/terms/:termId", c.MessageFilterController.DeleteTerm)
+	c.App.Get("/api/users/_current/channel/filter/audit", c.MessageFilterController.SearchAudit)
+	c.App.Get("/api/users/_current/channel/tts", c.SpeechController.GetSettings)
+	c.App.Patch("/api/users/_current/channel/tts", c.SpeechController.UpdateSettings)
+	c.App.Get("/api/users/_current/channel/tts/voices", c.SpeechController.ListVoices)
+	c.App.Get("/api/users/_current/channel/goals", c.GoalController.List)
+	c.App.Post("/api/users/_current/channel/goals", c.GoalController.Create)
+	c.App.Get("/api/users/_current/channel/goals/history", c.GoalController.History)
+	c.App.Get("/api/users/_current/channel/goals/:goalId", c.GoalController.Get)
+	c.App.Patch("/api/users/_current/channel/goals/:goalId", c.GoalController.Update)
+	c.App.Delete("/api/users/_current/channel/goals/:goalId", c.GoalController.Delete)
+	c.App.Post("/api/users/_current/channel/goals/:goalId/_end", c.GoalController.End)
+	c.App.Get("/api/users/_current/channel/leaderboards/_settings", c.LeaderboardController.GetSettings)
+	c.App.Patch("/api/users/_current/channel/leaderboards/_settings", c.LeaderboardController.UpdateSettings)
+	c.App.Post("/api/users/_current/channel/leaderboards/_rebuild", c.LeaderboardController.Rebuild)
+	c.App.Post("/api/users/_current/channel/leaderboards/stream/_start", c.LeaderboardController.StartStream)
+	c.App.Get("/api/users/_current/channel/leaderboards/:board", c.LeaderboardController.Get)
 }
\ No newline at end of file
@@ -0,0 +1,81 @@
+package http
+
+import (
+	"streamhelper-backend/internal/delivery/http/middleware"
+	"streamhelper-backend/internal/model"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+type SpeechController struct {
+	Log     *logrus.Logger
+	UseCase SpeechUseCase
+}
+
+func NewSpeechController(useCase SpeechUseCase, logger *logrus.Logger) *SpeechController {
+	return &SpeechController{
+		Log:     logger,
+		UseCase: useCase,
+	}
+}
+
+func (c *SpeechController) GetSettings(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.GetSpeechSettingsRequest{UserID: auth.ID}
+	response, err := c.UseCase.GetSettings(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to get speech settings")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.SpeechSettingsResponse]{Data: response})
+}
+
+func (c *SpeechController) UpdateSettings(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := new(model.UpdateSpeechSettingsRequest)
+	if err := ctx.BodyParser(request); err != nil {
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse request body : %+v", err)
+		return fiber.ErrBadRequest
+	}
+
+	request.UserID = auth.ID
+	response, err := c.UseCase.UpdateSettings(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to update speech settings")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.SpeechSettingsResponse]{Data: response})
+}
+
+func (c *SpeechController) ListVoices(ctx *fiber.Ctx) error {
+	auth := middleware.GetUser(ctx)
+
+	request := &model.GetSpeechSettingsRequest{UserID: auth.ID}
+	response, err := c.UseCase.ListVoices(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to list speech voices")
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[[]model.SpeechVoiceResponse]{Data: response})
+}
+
+// Audio serves synthesized speech to overlays. Names are donation IDs, and
+// an artifact never changes once written, so it may be cached for long.
+func (c *SpeechController) Audio(ctx *fiber.Ctx) error {
+	request := &model.GetSpeechAudioRequest{Name: ctx.Params("name")}
+	response, err := c.UseCase.Audio(ctx.UserContext(), request)
+	if err != nil {
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to get speech audio")
+		return err
+	}
+
+	ctx.Set(fiber.HeaderContentType, response.ContentType)
+	ctx.Set(fiber.HeaderCacheControl, "public, max-age=86400, immutable")
+	return ctx.Send(response.Data)
+}
@@ -3,7 +3,6 @@ package http
 import (
 	"streamhelper-backend/internal/delivery/http/middleware"
 	"streamhelper-backend/internal/model"
-	"streamhelper-backend/internal/usecase"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/sirupsen/logrus"
@@ -11,10 +10,10 @@ import (
 
 type UserController struct {
 	Log 			*logrus.Logger
-	UseCase 		*usecase.UserUseCase
+	UseCase 		UserUseCase
 }
 
-func NewUserController (useCase *usecase.UserUseCase, logger *logrus.Logger) *UserController{
+func NewUserController (useCase UserUseCase, logger *logrus.Logger) *UserController{
 	return &UserController{
 		Log: logger,
 		UseCase: useCase,
@@ -25,13 +24,13 @@ func (c *UserController) Register(ctx *fiber.Ctx) error {
 	request := new(model.RegisterUserRequest)
 	err := ctx.BodyParser(request)
 	if err != nil {
-		c.Log.Warnf("Failed to parse Body request : %+v", err)
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse Body request : %+v", err)
 		return fiber.ErrBadRequest
 	}
 
-	response , err := c.UseCase.Create(ctx.Context(), request)
+	response , err := c.UseCase.Create(ctx.UserContext(), request)
 	if err != nil {
-		c.Log.Warnf("Failed to register user : %+v", err)
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to register user : %+v", err)
 		return err
 	}
 
@@ -42,13 +41,13 @@ func (c *UserController) Login(ctx *fiber.Ctx) error {
 	request := new(model.LoginUserRequest)
 	err := ctx.BodyParser(request)
 	if err != nil {
-		c.Log.Warnf("Failed to parse request body : %+v", err)
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse request body : %+v", err)
 		return fiber.ErrBadRequest
 	}
 
 	response , err := c.UseCase.Login(ctx.UserContext(), request)
 	if err != nil {
-		c.Log.Warnf("Failed to login user : %+v", err)
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to login user : %+v", err)
 		return err
 	}
 
@@ -64,7 +63,7 @@ func (c *UserController) Current(ctx *fiber.Ctx) error {
 
 	response , err := c.UseCase.Current(ctx.UserContext(), request)
 	if err != nil {
-		c.Log.WithError(err).Warnf("Failed get current user")
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed get current user")
 		return err
 	}
 
@@ -80,7 +79,7 @@ func (c *UserController) Logout(ctx *fiber.Ctx) error {
 
 	response , err := c.UseCase.Logout(ctx.UserContext(), request)
 	if err != nil {
-		c.Log.WithError(err).Warnf("Failed to logout user")
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to logout user")
 		return err
 	}
 
@@ -92,14 +91,14 @@ func (c *UserController) Update(ctx *fiber.Ctx) error {
 
 	request := new(model.UpdateUserRequest)
 	if err := ctx.BodyParser(request); err != nil {
-		c.Log.Warnf("Failed to parse request body : %+v", err)
+		c.Log.WithContext(ctx.UserContext()).Warnf("Failed to parse request body : %+v", err)
 		return fiber.ErrBadRequest
 	}
 
 	request.ID = auth.ID
 	response , err := c.UseCase.Update(ctx.UserContext(), request)
 	if err != nil {
-		c.Log.WithError(err).Warnf("Failed to update user")
+		c.Log.WithContext(ctx.UserContext()).WithError(err).Warnf("Failed to update user")
 		return err
 	}
 
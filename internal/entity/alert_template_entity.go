@@ -0,0 +1,36 @@
+package entity
+
+// AlertTemplate decides how the overlay shows alerts of EventType whose
+// amount is at least MinAmount, the template with the highest MinAmount that
+// applies wins. Duration is in seconds, the channel's minimum alert duration
+// applies when it is shorter.
+type AlertTemplate struct {
+	ID        string `gorm:"column:id;primaryKey"`
+	ChannelID string `gorm:"column:channel_id;uniqueIndex:idx_alert_templates_channel_id_tier,priority:1"`
+	Name      string `gorm:"column:name"`
+	EventType string `gorm:"column:event_type;uniqueIndex:idx_alert_templates_channel_id_tier,priority:2"`
+	MinAmount int64  `gorm:"column:min_amount;uniqueIndex:idx_alert_templates_channel_id_tier,priority:3"`
+	Text      string `gorm:"column:text"`
+	ImageURL  string `gorm:"column:image_url"`
+	SoundURL  string `gorm:"column:sound_url"`
+	Duration  int    `gorm:"column:duration"`
+	Animation string `gorm:"column:animation"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt int64  `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+}
+
+func (a *AlertTemplate) TableName() string {
+	return "alert_templates"
+}
+
+// Animations the overlay knows for showing an alert.
+const (
+	AnimationFade   = "fade"
+	AnimationSlide  = "slide"
+	AnimationBounce = "bounce"
+	AnimationZoom   = "zoom"
+	AnimationNone   = "none"
+)
+
+// MaxAlertTemplates is how many templates a channel may keep.
+const MaxAlertTemplates = 20
@@ -0,0 +1,41 @@
+package entity
+
+// BlockedTerm is a word or a regular expression a channel does not allow in
+// donation messages, on top of the global wordlist.
+type BlockedTerm struct {
+	ID        string `gorm:"column:id;primaryKey"`
+	ChannelID string `gorm:"column:channel_id;uniqueIndex:idx_blocked_terms_channel_id_kind_pattern,priority:1"`
+	Kind      string `gorm:"column:kind;uniqueIndex:idx_blocked_terms_channel_id_kind_pattern,priority:2"`
+	Pattern   string `gorm:"column:pattern;uniqueIndex:idx_blocked_terms_channel_id_kind_pattern,priority:3"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli"`
+}
+
+func (b *BlockedTerm) TableName() string {
+	return "blocked_terms"
+}
+
+const (
+	BlockedTermWord  = "word"
+	BlockedTermRegex = "regex"
+)
+
+// MaxBlockedTerms is how many terms a channel may add.
+const MaxBlockedTerms = 100
+
+// FilteredMessage records a donation message the filter acted on, so the
+// streamer can see what was masked, held or rejected. DonationID is empty
+// for rejected donations, which are never stored.
+type FilteredMessage struct {
+	ID         string   `gorm:"column:id;primaryKey"`
+	ChannelID  string   `gorm:"column:channel_id;index:idx_filtered_messages_channel_id_created_at,priority:1"`
+	DonationID string   `gorm:"column:donation_id"`
+	Action     string   `gorm:"column:action"`
+	Original   string   `gorm:"column:original"`
+	Filtered   string   `gorm:"column:filtered"`
+	Matches    []string `gorm:"column:matches;serializer:json"`
+	CreatedAt  int64    `gorm:"column:created_at;autoCreateTime:milli;index:idx_filtered_messages_channel_id_created_at,priority:2"`
+}
+
+func (f *FilteredMessage) TableName() string {
+	return "filtered_messages"
+}
@@ -0,0 +1,75 @@
+package entity
+
+// Channel is the public page of a streamer. It is owned by exactly one user
+// and addressed by its slug, which viewers see in URLs.
+type Channel struct {
+	ID              string            `gorm:"column:id;primaryKey"`
+	UserID          string            `gorm:"column:user_id;uniqueIndex"`
+	Slug            string            `gorm:"column:slug;uniqueIndex"`
+	DisplayName     string            `gorm:"column:display_name"`
+	Bio             string            `gorm:"column:bio"`
+	AvatarURL       string            `gorm:"column:avatar_url"`
+	SocialLinks     []SocialLink      `gorm:"column:social_links;serializer:json"`
+	PlatformHandles map[string]string `gorm:"column:platform_handles;serializer:json"`
+	Currency        string            `gorm:"column:currency;default:IDR"`
+	MinDonation     int64             `gorm:"column:min_donation;default:1000"`
+	MaxDonation     int64             `gorm:"column:max_donation"`
+	OverlayKey      string            `gorm:"column:overlay_key;uniqueIndex"`
+	AlertModeration bool              `gorm:"column:alert_moderation"`
+	AlertDuration   int               `gorm:"column:alert_duration;default:5"`
+	FilterAction    string            `gorm:"column:filter_action;default:mask"`
+	TTSEnabled      bool              `gorm:"column:tts_enabled"`
+	TTSVoice        string            `gorm:"column:tts_voice"`
+	TTSLanguage     string            `gorm:"column:tts_language;default:id-ID"`
+	TTSMaxLength    int               `gorm:"column:tts_max_length;default:200"`
+	OverlayRankings bool              `gorm:"column:overlay_rankings"`
+	StreamStartedAt int64             `gorm:"column:stream_started_at"`
+	CreatedAt       int64             `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt       int64             `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+}
+
+func (c *Channel) TableName() string {
+	return "channels"
+}
+
+type SocialLink struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+// DefaultAlertDuration is the minimum time in seconds an alert stays on
+// screen for channels that did not choose their own.
+const DefaultAlertDuration = 5
+
+// Actions a channel can take on donation messages with blocked words. Mask
+// replaces the words with asterisks, hold shows the message only after the
+// streamer approves its alert and reject refuses the donation.
+const (
+	FilterActionMask   = "mask"
+	FilterActionHold   = "hold"
+	FilterActionReject = "reject"
+)
+
+// Text-to-speech settings of a channel that did not choose its own. Messages
+// are cut to the maximum length before they are read aloud.
+const (
+	DefaultTTSLanguage  = "id-ID"
+	DefaultTTSMaxLength = 200
+	MaxTTSLength        = 500
+)
+
+// Donation settings of a channel that did not choose its own.
+const (
+	DefaultCurrency    = "IDR"
+	DefaultMinDonation = 1000
+)
+
+// Streaming platforms a channel can list a handle for.
+const (
+	PlatformYouTube   = "youtube"
+	PlatformTwitch    = "twitch"
+	PlatformTikTok    = "tiktok"
+	PlatformKick      = "kick"
+	PlatformFacebook  = "facebook"
+	PlatformInstagram = "instagram"
+)
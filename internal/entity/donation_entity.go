@@ -0,0 +1,60 @@
+package entity
+
+// Donation is a tip sent to a channel. Amount is in the smallest unit of
+// Currency, which for IDR is the rupiah itself. Held donations have a
+// message with blocked words that waits for the streamer's review.
+type Donation struct {
+	ID        string `gorm:"column:id;primaryKey"`
+	ChannelID string `gorm:"column:channel_id;index:idx_donations_channel_id_created_at,priority:1"`
+	DonorName string `gorm:"column:donor_name"`
+	Message   string `gorm:"column:message"`
+	Amount    int64  `gorm:"column:amount"`
+	Currency  string `gorm:"column:currency"`
+	Status    string `gorm:"column:status"`
+	Anonymous bool   `gorm:"column:anonymous"`
+	Held      bool   `gorm:"column:held"`
+	PaidAt    int64  `gorm:"column:paid_at"`
+	CreatedAt int64  `gorm:"column:created_at;autoCreateTime:milli;index:idx_donations_channel_id_created_at,priority:2"`
+	UpdatedAt int64  `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+}
+
+func (d *Donation) TableName() string {
+	return "donations"
+}
+
+const (
+	DonationStatusPending  = "pending"
+	DonationStatusPaid     = "paid"
+	DonationStatusFailed   = "failed"
+	DonationStatusExpired  = "expired"
+	DonationStatusRefunded = "refunded"
+)
+
+// donationTransitions lists where each status may move. Payment callbacks
+// arrive late, twice or out of order, anything not listed is ignored.
+var donationTransitions = map[string][]string{
+	DonationStatusPending: {DonationStatusPaid, DonationStatusFailed, DonationStatusExpired},
+	DonationStatusPaid:    {DonationStatusRefunded},
+}
+
+// CanMoveTo reports whether the donation may change to status.
+func (d *Donation) CanMoveTo(status string) bool {
+	for _, next := range donationTransitions[d.Status] {
+		if next == status {
+			return true
+		}
+	}
+	return false
+}
+
+// AnonymousDonorName replaces the donor name wherever an anonymous donation
+// is shown to someone other than the donor.
+const AnonymousDonorName = "Anonymous"
+
+// PublicDonorName is the name to show on stream and in public lists.
+func (d *Donation) PublicDonorName() string {
+	if d.Anonymous {
+		return AnonymousDonorName
+	}
+	return d.DonorName
+}
@@ -0,0 +1,55 @@
+package entity
+
+// Goal is an amount a channel raises donations toward, shown as a progress
+// bar on stream. Amount is the sum of the paid donations in Currency made
+// between StartsAt and EndsAt with one of the Sources, all payment methods
+// when empty, and is kept up to date as payments change. EndsAt is 0 for a
+// goal without an end. Times are unix milliseconds.
+type Goal struct {
+	ID           string   `gorm:"column:id;primaryKey"`
+	ChannelID    string   `gorm:"column:channel_id;index:idx_goals_channel_id_ends_at,priority:1"`
+	Title        string   `gorm:"column:title"`
+	TargetAmount int64    `gorm:"column:target_amount"`
+	Currency     string   `gorm:"column:currency"`
+	Sources      []string `gorm:"column:sources;serializer:json"`
+	StartsAt     int64    `gorm:"column:starts_at"`
+	EndsAt       int64    `gorm:"column:ends_at;index:idx_goals_channel_id_ends_at,priority:2"`
+	Amount       int64    `gorm:"column:amount"`
+	CompletedAt  int64    `gorm:"column:completed_at"`
+	CreatedAt    int64    `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt    int64    `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+}
+
+func (g *Goal) TableName() string {
+	return "goals"
+}
+
+// Statuses of a goal. A completed goal keeps counting donations until it
+// ends, and stays completed when a refund takes it below the target again.
+const (
+	GoalStatusScheduled = "scheduled"
+	GoalStatusActive    = "active"
+	GoalStatusCompleted = "completed"
+	GoalStatusEnded     = "ended"
+)
+
+// MaxOpenGoals is how many goals that have not ended a channel may have.
+const MaxOpenGoals = 10
+
+// Ended reports whether the goal is over at now.
+func (g *Goal) Ended(now int64) bool {
+	return g.EndsAt != 0 && g.EndsAt <= now
+}
+
+// Status returns the status of the goal at now.
+func (g *Goal) Status(now int64) string {
+	switch {
+	case g.Ended(now):
+		return GoalStatusEnded
+	case g.StartsAt > now:
+		return GoalStatusScheduled
+	case g.CompletedAt != 0:
+		return GoalStatusCompleted
+	}
+	return GoalStatusActive
+}
@@ -0,0 +1,25 @@
+package entity
+
+// Payment is the charge collecting a donation at the payment provider. A
+// donation has at most one payment, the donation ID is the provider's order ID.
+type Payment struct {
+	ID          string `gorm:"column:id;primaryKey"`
+	DonationID  string `gorm:"column:donation_id;uniqueIndex"`
+	Provider    string `gorm:"column:provider"`
+	Reference   string `gorm:"column:reference"`
+	Method      string `gorm:"column:method"`
+	Bank        string `gorm:"column:bank"`
+	Status      string `gorm:"column:status"`
+	Amount      int64  `gorm:"column:amount"`
+	Currency    string `gorm:"column:currency"`
+	QRString    string `gorm:"column:qr_string"`
+	RedirectURL string `gorm:"column:redirect_url"`
+	VANumber    string `gorm:"column:va_number"`
+	ExpiresAt   int64  `gorm:"column:expires_at"`
+	CreatedAt   int64  `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt   int64  `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+}
+
+func (p *Payment) TableName() string {
+	return "payments"
+}
@@ -6,10 +6,22 @@ type User struct {
 	Password  string    `gorm:"column:password"`
 	Name      string    `gorm:"column:name"`
 	Token     string    `gorm:"column:token"`
+	Language  string    `gorm:"column:language"`
+	Role      string    `gorm:"column:role;default:user"`
+	DisabledAt int64    `gorm:"column:disabled_at"`
 	CreatedAt int64     `gorm:"column:created_at;autoCreateTime:milli"`
 	UpdatedAt int64     `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
 }
 
 func (u *User) TableName() string{
 	return "users"
-}
\ No newline at end of file
+}
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+func (u *User) Disabled() bool {
+	return u.DisabledAt != 0
+}
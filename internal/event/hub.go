@@ -0,0 +1,475 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/tracing"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrSlowSubscriber closes a subscription whose buffer is full. The
+	// client is expected to reconnect and replay from its last event.
+	ErrSlowSubscriber = errors.New("event: subscriber too slow")
+	// ErrHubClosed closes every subscription when the hub shuts down.
+	ErrHubClosed = errors.New("event: hub closed")
+	// ErrRevoked closes the subscriptions opened under a key that was
+	// revoked.
+	ErrRevoked = errors.New("event: subscription revoked")
+	// ErrInvalidID is returned for event IDs that are not stream IDs.
+	ErrInvalidID = errors.New("event: invalid event id")
+)
+
+// historyTTL drops the history of channels that stopped receiving events.
+const historyTTL = 7 * 24 * time.Hour
+
+// revokeType marks a control message on a channel's live pub/sub. It has no
+// ID, is never kept in the history and never reaches a subscriber.
+const revokeType = "hub.revoke"
+
+// Event is one entry of a channel's stream. ID is the Redis stream ID, it
+// increases monotonically per channel and is what clients acknowledge.
+type Event struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	ChannelID string          `json:"channel_id"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt int64           `json:"created_at"`
+}
+
+// Hub fans events out to every subscriber of a channel on every instance.
+// Events are appended to a capped Redis stream per channel, for replay, and
+// announced on a Redis pub/sub channel, for live delivery. Each instance
+// subscribes only to the channels it has local subscribers for.
+type Hub struct {
+	Redis *redis.Client
+	Log   *logrus.Logger
+	// MaxLen caps the history kept per channel for replay.
+	MaxLen int64
+
+	mu          sync.Mutex
+	closed      bool
+	pubsub      *redis.PubSub
+	subscribers map[string]map[*Subscription]struct{}
+	waiters     map[string][]chan struct{}
+}
+
+func NewHub(redisClient *redis.Client, log *logrus.Logger, maxLen int64) *Hub {
+	return &Hub{
+		Redis:       redisClient,
+		Log:         log,
+		MaxLen:      maxLen,
+		subscribers: make(map[string]map[*Subscription]struct{}),
+		waiters:     make(map[string][]chan struct{}),
+	}
+}
+
+// Publish appends an event to the channel's history and delivers it to the
+// live subscribers.
+func (h *Hub) Publish(ctx context.Context, channelID string, eventType string, data model.Event) (*Event, error) {
+	ctx, span := tracing.Start(ctx, "Hub.Publish")
+	defer span.End()
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &Event{
+		Type:      eventType,
+		ChannelID: channelID,
+		Data:      payload,
+		CreatedAt: time.Now().UnixMilli(),
+	}
+
+	key := historyKey(channelID)
+	event.ID, err = h.Redis.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		MaxLen: h.MaxLen,
+		Approx: true,
+		Values: map[string]any{
+			"type":       event.Type,
+			"data":       string(event.Data),
+			"created_at": event.CreatedAt,
+		},
+	}).Result()
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	message, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	pipe := h.Redis.Pipeline()
+	pipe.Expire(ctx, key, historyTTL)
+	pipe.Publish(ctx, liveKey(channelID), message)
+	if _, err := pipe.Exec(ctx); err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// Since returns the newest limit events of the channel published after
+// afterID, oldest first. gap reports that older ones were left out, the
+// client has to reload its state instead of relying on the replay. An empty
+// afterID returns nothing, a new client only wants what happens from now on.
+func (h *Hub) Since(ctx context.Context, channelID string, afterID string, limit int64) (events []Event, gap bool, err error) {
+	ctx, span := tracing.Start(ctx, "Hub.Since")
+	defer span.End()
+
+	if afterID == "" {
+		return nil, false, nil
+	}
+	start, err := nextID(afterID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// one more than asked for tells whether anything was left out
+	messages, err := h.Redis.XRevRangeN(ctx, historyKey(channelID), "+", start, limit+1).Result()
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, false, err
+	}
+	if int64(len(messages)) > limit {
+		messages, gap = messages[:limit], true
+	}
+
+	events = make([]Event, 0, len(messages))
+	for i := len(messages) - 1; i >= 0; i-- {
+		message := messages[i]
+		event := Event{ID: message.ID, ChannelID: channelID}
+		event.Type, _ = message.Values["type"].(string)
+		if data, ok := message.Values["data"].(string); ok {
+			event.Data = json.RawMessage(data)
+		}
+		if createdAt, ok := message.Values["created_at"].(string); ok {
+			event.CreatedAt, _ = strconv.ParseInt(createdAt, 10, 64)
+		}
+		events = append(events, event)
+	}
+
+	return events, gap, nil
+}
+
+// Ack remembers the last event a client of the channel has handled, so it
+// can resume from there without keeping state itself. Acknowledgements only
+// move forward.
+func (h *Hub) Ack(ctx context.Context, channelID string, client string, id string) error {
+	if _, err := parseID(id); err != nil {
+		return err
+	}
+
+	key := ackKey(channelID, client)
+	last, err := h.Redis.Get(ctx, key).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	if last != "" && !After(id, last) {
+		return nil
+	}
+
+	return h.Redis.Set(ctx, key, id, historyTTL).Err()
+}
+
+// LastAck returns the last event acknowledged by the client, or "".
+func (h *Hub) LastAck(ctx context.Context, channelID string, client string) (string, error) {
+	id, err := h.Redis.Get(ctx, ackKey(channelID, client)).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return id, err
+}
+
+// Subscribe registers a local subscriber for the channel's live events. It
+// returns once Redis confirmed the subscription, so nothing published after
+// it returns is missed. buffer bounds how far the subscriber may fall behind.
+// key groups the subscriptions Revoke ends together, "" is never revoked.
+func (h *Hub) Subscribe(ctx context.Context, channelID string, key string, buffer int) (*Subscription, error) {
+	subscription := &Subscription{
+		ChannelID: channelID,
+		Key:       key,
+		hub:       h,
+		events:    make(chan Event, buffer),
+	}
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil, ErrHubClosed
+	}
+	if h.pubsub == nil {
+		h.pubsub = h.Redis.Subscribe(context.Background())
+		go h.dispatch(h.pubsub)
+	}
+
+	topic := liveKey(channelID)
+	confirmed := make(chan struct{})
+	var err error
+	if subscribers, ok := h.subscribers[channelID]; ok {
+		subscribers[subscription] = struct{}{}
+		if _, pending := h.waiters[topic]; !pending {
+			h.mu.Unlock()
+			return subscription, nil
+		}
+		h.waiters[topic] = append(h.waiters[topic], confirmed)
+	} else {
+		h.subscribers[channelID] = map[*Subscription]struct{}{subscription: {}}
+		h.waiters[topic] = append(h.waiters[topic], confirmed)
+		err = h.pubsub.Subscribe(ctx, topic)
+	}
+	h.mu.Unlock()
+
+	if err != nil {
+		subscription.Close()
+		return nil, err
+	}
+
+	select {
+	case <-confirmed:
+		return subscription, nil
+	case <-ctx.Done():
+		subscription.Close()
+		return nil, ctx.Err()
+	}
+}
+
+// Revoke ends the channel's subscriptions opened under key with ErrRevoked,
+// on every instance.
+func (h *Hub) Revoke(ctx context.Context, channelID string, key string) error {
+	ctx, span := tracing.Start(ctx, "Hub.Revoke")
+	defer span.End()
+
+	data, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+	message, err := json.Marshal(&Event{Type: revokeType, ChannelID: channelID, Data: data})
+	if err != nil {
+		return err
+	}
+
+	err = h.Redis.Publish(ctx, liveKey(channelID), message).Err()
+	if err != nil {
+		tracing.RecordError(span, err)
+	}
+	return err
+}
+
+// Close ends every subscription with ErrHubClosed and releases the pub/sub
+// connection.
+func (h *Hub) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil
+	}
+	h.closed = true
+
+	for _, subscribers := range h.subscribers {
+		for subscription := range subscribers {
+			subscription.end(ErrHubClosed)
+		}
+	}
+	h.subscribers = make(map[string]map[*Subscription]struct{})
+
+	if h.pubsub != nil {
+		return h.pubsub.Close()
+	}
+	return nil
+}
+
+func (h *Hub) dispatch(pubsub *redis.PubSub) {
+	for message := range pubsub.ChannelWithSubscriptions() {
+		switch message := message.(type) {
+		case *redis.Subscription:
+			if message.Kind == "subscribe" {
+				h.confirm(message.Channel)
+			}
+		case *redis.Message:
+			event := new(Event)
+			if err := json.Unmarshal([]byte(message.Payload), event); err != nil {
+				h.Log.Warnf("Failed to decode event from %s : %+v", message.Channel, err)
+				continue
+			}
+			if event.ID == "" && event.Type == revokeType {
+				var key string
+				if err := json.Unmarshal(event.Data, &key); err != nil || key == "" {
+					h.Log.Warnf("Failed to decode revocation from %s : %+v", message.Channel, err)
+					continue
+				}
+				h.revoke(event.ChannelID, key)
+				continue
+			}
+			h.deliver(event)
+		}
+	}
+}
+
+func (h *Hub) confirm(key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, confirmed := range h.waiters[key] {
+		close(confirmed)
+	}
+	delete(h.waiters, key)
+}
+
+// deliver never blocks on a subscriber, one that cannot keep up is dropped
+// instead of holding back everyone else.
+func (h *Hub) deliver(event *Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for subscription := range h.subscribers[event.ChannelID] {
+		select {
+		case subscription.events <- *event:
+		default:
+			h.Log.Warnf("Dropping slow subscriber of channel %s", event.ChannelID)
+			h.remove(subscription)
+			subscription.end(ErrSlowSubscriber)
+		}
+	}
+}
+
+func (h *Hub) revoke(channelID string, key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for subscription := range h.subscribers[channelID] {
+		if subscription.Key == key {
+			h.remove(subscription)
+			subscription.end(ErrRevoked)
+		}
+	}
+}
+
+// remove must be called with h.mu held.
+func (h *Hub) remove(subscription *Subscription) {
+	subscribers := h.subscribers[subscription.ChannelID]
+	if _, ok := subscribers[subscription]; !ok {
+		return
+	}
+
+	delete(subscribers, subscription)
+	if len(subscribers) == 0 {
+		delete(h.subscribers, subscription.ChannelID)
+		if h.pubsub != nil && !h.closed {
+			if err := h.pubsub.Unsubscribe(context.Background(), liveKey(subscription.ChannelID)); err != nil {
+				h.Log.Warnf("Failed to unsubscribe from channel %s : %+v", subscription.ChannelID, err)
+			}
+		}
+	}
+}
+
+// Subscription receives the live events of one channel until it is closed.
+type Subscription struct {
+	ChannelID string
+	Key       string
+
+	hub    *Hub
+	events chan Event
+	ended  bool
+	err    error
+}
+
+// Events is closed when the subscription ends, Err then tells why.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Err returns why the subscription ended, nil when it was closed normally.
+func (s *Subscription) Err() error {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	return s.err
+}
+
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	s.hub.remove(s)
+	s.end(nil)
+}
+
+// end must be called with the hub's lock held.
+func (s *Subscription) end(err error) {
+	if s.ended {
+		return
+	}
+	s.ended = true
+	s.err = err
+	close(s.events)
+}
+
+// After reports whether stream ID a comes after b. Invalid IDs come first.
+func After(a string, b string) bool {
+	first, err := parseID(a)
+	if err != nil {
+		return false
+	}
+	second, err := parseID(b)
+	if err != nil {
+		return true
+	}
+	if first[0] != second[0] {
+		return first[0] > second[0]
+	}
+	return first[1] > second[1]
+}
+
+// parseID splits a stream ID "<milliseconds>-<sequence>".
+func parseID(id string) ([2]uint64, error) {
+	milliseconds, sequence, ok := strings.Cut(id, "-")
+	if !ok {
+		return [2]uint64{}, ErrInvalidID
+	}
+	first, err := strconv.ParseUint(milliseconds, 10, 64)
+	if err != nil {
+		return [2]uint64{}, ErrInvalidID
+	}
+	second, err := strconv.ParseUint(sequence, 10, 64)
+	if err != nil {
+		return [2]uint64{}, ErrInvalidID
+	}
+	return [2]uint64{first, second}, nil
+}
+
+// nextID is the smallest stream ID after id, XRANGE bounds are inclusive.
+func nextID(id string) (string, error) {
+	parsed, err := parseID(id)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d-%d", parsed[0], parsed[1]+1), nil
+}
+
+func historyKey(channelID string) string {
+	return "events:history:" + channelID
+}
+
+func liveKey(channelID string) string {
+	return "events:live:" + channelID
+}
+
+func ackKey(channelID string, client string) string {
+	return "events:ack:" + channelID + ":" + client
+}
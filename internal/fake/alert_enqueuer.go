@@ -0,0 +1,36 @@
+package fake
+
+import (
+	"context"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/usecase"
+	"sync"
+)
+
+var _ usecase.AlertEnqueuer = (*AlertEnqueuer)(nil)
+
+// AlertEnqueuer records the alerts it is asked to queue.
+type AlertEnqueuer struct {
+	mu       sync.Mutex
+	requests []model.EnqueueAlertRequest
+}
+
+func NewAlertEnqueuer() *AlertEnqueuer {
+	return &AlertEnqueuer{}
+}
+
+func (e *AlertEnqueuer) Enqueue(ctx context.Context, request *model.EnqueueAlertRequest) (*model.AlertResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.requests = append(e.requests, *request)
+	return &model.AlertResponse{ID: request.Data.GetId(), Type: request.Type}, nil
+}
+
+// Requests returns every request so far, oldest first.
+func (e *AlertEnqueuer) Requests() []model.EnqueueAlertRequest {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]model.EnqueueAlertRequest(nil), e.requests...)
+}
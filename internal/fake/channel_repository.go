@@ -0,0 +1,106 @@
+package fake
+
+import (
+	"fmt"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/usecase"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var _ usecase.ChannelRepository = (*ChannelRepository)(nil)
+
+// ChannelRepository keeps channels in memory, keyed by ID.
+type ChannelRepository struct {
+	mu       sync.RWMutex
+	channels map[string]entity.Channel
+}
+
+func NewChannelRepository(channels ...*entity.Channel) *ChannelRepository {
+	repository := &ChannelRepository{
+		channels: make(map[string]entity.Channel),
+	}
+	for _, channel := range channels {
+		repository.channels[channel.ID] = *channel
+	}
+
+	return repository
+}
+
+func (r *ChannelRepository) Create(db *gorm.DB, channel *entity.Channel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, found := range r.channels {
+		if found.ID == channel.ID || found.Slug == channel.Slug || found.UserID == channel.UserID {
+			return fmt.Errorf("duplicate channel %q", channel.Slug)
+		}
+	}
+
+	now := time.Now().UnixMilli()
+	channel.CreatedAt = now
+	channel.UpdatedAt = now
+	r.channels[channel.ID] = *channel
+	return nil
+}
+
+func (r *ChannelRepository) Update(db *gorm.DB, channel *entity.Channel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	channel.UpdatedAt = time.Now().UnixMilli()
+	r.channels[channel.ID] = *channel
+	return nil
+}
+
+func (r *ChannelRepository) FindById(db *gorm.DB, channel *entity.Channel, id any) error {
+	return r.find(channel, func(found entity.Channel) bool { return found.ID == id })
+}
+
+func (r *ChannelRepository) FindBySlug(db *gorm.DB, channel *entity.Channel, slug string) error {
+	return r.find(channel, func(found entity.Channel) bool { return found.Slug == slug })
+}
+
+func (r *ChannelRepository) FindByUserId(db *gorm.DB, channel *entity.Channel, userId string) error {
+	return r.find(channel, func(found entity.Channel) bool { return found.UserID == userId })
+}
+
+func (r *ChannelRepository) FindByOverlayKey(db *gorm.DB, channel *entity.Channel, key string) error {
+	return r.find(channel, func(found entity.Channel) bool { return found.OverlayKey == key })
+}
+
+func (r *ChannelRepository) CountBySlug(db *gorm.DB, slug string) (int64, error) {
+	return r.count(func(found entity.Channel) bool { return found.Slug == slug }), nil
+}
+
+func (r *ChannelRepository) CountByUserId(db *gorm.DB, userId string) (int64, error) {
+	return r.count(func(found entity.Channel) bool { return found.UserID == userId }), nil
+}
+
+func (r *ChannelRepository) find(channel *entity.Channel, match func(entity.Channel) bool) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, found := range r.channels {
+		if match(found) {
+			*channel = found
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (r *ChannelRepository) count(match func(entity.Channel) bool) int64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var total int64
+	for _, found := range r.channels {
+		if match(found) {
+			total++
+		}
+	}
+	return total
+}
@@ -0,0 +1,117 @@
+package fake
+
+import (
+	"fmt"
+	"sort"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/usecase"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var _ usecase.DonationRepository = (*DonationRepository)(nil)
+
+// DonationRepository keeps donations in memory, keyed by ID.
+type DonationRepository struct {
+	mu        sync.RWMutex
+	donations map[string]entity.Donation
+}
+
+func NewDonationRepository(donations ...*entity.Donation) *DonationRepository {
+	repository := &DonationRepository{
+		donations: make(map[string]entity.Donation),
+	}
+	for _, donation := range donations {
+		repository.donations[donation.ID] = *donation
+	}
+
+	return repository
+}
+
+func (r *DonationRepository) Create(db *gorm.DB, donation *entity.Donation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.donations[donation.ID]; ok {
+		return fmt.Errorf("duplicate key %q", donation.ID)
+	}
+
+	now := time.Now().UnixMilli()
+	if donation.CreatedAt == 0 {
+		donation.CreatedAt = now
+	}
+	if donation.UpdatedAt == 0 {
+		donation.UpdatedAt = now
+	}
+	r.donations[donation.ID] = *donation
+	return nil
+}
+
+func (r *DonationRepository) Update(db *gorm.DB, donation *entity.Donation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	donation.UpdatedAt = time.Now().UnixMilli()
+	r.donations[donation.ID] = *donation
+	return nil
+}
+
+func (r *DonationRepository) UpdateStatus(db *gorm.DB, donation *entity.Donation, from string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.donations[donation.ID]
+	if !ok || stored.Status != from {
+		return false, nil
+	}
+
+	stored.Status = donation.Status
+	stored.PaidAt = donation.PaidAt
+	stored.UpdatedAt = time.Now().UnixMilli()
+	r.donations[donation.ID] = stored
+	donation.UpdatedAt = stored.UpdatedAt
+	return true, nil
+}
+
+func (r *DonationRepository) FindById(db *gorm.DB, donation *entity.Donation, id any) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	found, ok := r.donations[fmt.Sprint(id)]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	*donation = found
+	return nil
+}
+
+// FindByIdForUpdate does not lock, the fake is safe for concurrent use.
+func (r *DonationRepository) FindByIdForUpdate(db *gorm.DB, donation *entity.Donation, id string) error {
+	return r.FindById(db, donation, id)
+}
+
+func (r *DonationRepository) Search(db *gorm.DB, channelId string, request *model.SearchDonationRequest) ([]entity.Donation, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []entity.Donation
+	for _, donation := range r.donations {
+		if donation.ChannelID == channelId && (request.Status == "" || donation.Status == request.Status) {
+			matched = append(matched, donation)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].CreatedAt != matched[j].CreatedAt {
+			return matched[i].CreatedAt > matched[j].CreatedAt
+		}
+		return matched[i].ID < matched[j].ID
+	})
+
+	start := min((request.Page-1)*request.Size, len(matched))
+	end := min(start+request.Size, len(matched))
+	return matched[start:end], int64(len(matched)), nil
+}
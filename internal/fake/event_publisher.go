@@ -0,0 +1,52 @@
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"streamhelper-backend/internal/event"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/usecase"
+	"sync"
+	"time"
+)
+
+var _ usecase.EventPublisher = (*EventPublisher)(nil)
+
+// EventPublisher records published events instead of sending them anywhere.
+type EventPublisher struct {
+	mu     sync.Mutex
+	events []event.Event
+}
+
+func NewEventPublisher() *EventPublisher {
+	return &EventPublisher{}
+}
+
+func (p *EventPublisher) Publish(ctx context.Context, channelID string, eventType string, data model.Event) (*event.Event, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	published := event.Event{
+		ID:        fmt.Sprintf("%d-0", len(p.events)+1),
+		Type:      eventType,
+		ChannelID: channelID,
+		Data:      payload,
+		CreatedAt: time.Now().UnixMilli(),
+	}
+	p.events = append(p.events, published)
+	return &published, nil
+}
+
+// Events returns everything published so far, oldest first.
+func (p *EventPublisher) Events() []event.Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]event.Event(nil), p.events...)
+}
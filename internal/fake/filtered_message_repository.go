@@ -0,0 +1,49 @@
+package fake
+
+import (
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/usecase"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var _ usecase.FilteredMessageRepository = (*FilteredMessageRepository)(nil)
+
+// FilteredMessageRepository keeps the audit in memory, oldest first.
+type FilteredMessageRepository struct {
+	mu       sync.RWMutex
+	messages []entity.FilteredMessage
+}
+
+func NewFilteredMessageRepository() *FilteredMessageRepository {
+	return &FilteredMessageRepository{}
+}
+
+func (r *FilteredMessageRepository) Create(db *gorm.DB, message *entity.FilteredMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	message.CreatedAt = time.Now().UnixMilli()
+	r.messages = append(r.messages, *message)
+	return nil
+}
+
+func (r *FilteredMessageRepository) Search(db *gorm.DB, channelId string, request *model.SearchFilteredMessageRequest) ([]entity.FilteredMessage, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []entity.FilteredMessage
+	for i := len(r.messages) - 1; i >= 0; i-- {
+		message := r.messages[i]
+		if message.ChannelID == channelId && (request.Action == "" || message.Action == request.Action) {
+			matched = append(matched, message)
+		}
+	}
+
+	start := min((request.Page-1)*request.Size, len(matched))
+	end := min(start+request.Size, len(matched))
+	return matched[start:end], int64(len(matched)), nil
+}
@@ -0,0 +1,36 @@
+package fake
+
+import (
+	"context"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/usecase"
+	"sync"
+)
+
+var _ usecase.GoalTracker = (*GoalTracker)(nil)
+
+// GoalTracker records the donations whose goals it is asked to track.
+type GoalTracker struct {
+	mu       sync.Mutex
+	requests []model.TrackGoalRequest
+}
+
+func NewGoalTracker() *GoalTracker {
+	return &GoalTracker{}
+}
+
+func (t *GoalTracker) Track(ctx context.Context, request *model.TrackGoalRequest) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.requests = append(t.requests, *request)
+	return nil
+}
+
+// Requests returns every request so far, oldest first.
+func (t *GoalTracker) Requests() []model.TrackGoalRequest {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]model.TrackGoalRequest(nil), t.requests...)
+}
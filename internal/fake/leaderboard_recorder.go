@@ -0,0 +1,36 @@
+package fake
+
+import (
+	"context"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/usecase"
+	"sync"
+)
+
+var _ usecase.LeaderboardRecorder = (*LeaderboardRecorder)(nil)
+
+// LeaderboardRecorder records the donations it is asked to rank.
+type LeaderboardRecorder struct {
+	mu       sync.Mutex
+	requests []model.RecordLeaderboardRequest
+}
+
+func NewLeaderboardRecorder() *LeaderboardRecorder {
+	return &LeaderboardRecorder{}
+}
+
+func (r *LeaderboardRecorder) Record(ctx context.Context, request *model.RecordLeaderboardRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.requests = append(r.requests, *request)
+	return nil
+}
+
+// Requests returns every request so far, oldest first.
+func (r *LeaderboardRecorder) Requests() []model.RecordLeaderboardRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]model.RecordLeaderboardRequest(nil), r.requests...)
+}
@@ -0,0 +1,29 @@
+package fake
+
+import (
+	"context"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/moderation"
+	"streamhelper-backend/internal/usecase"
+)
+
+var _ usecase.MessageFilter = (*MessageFilter)(nil)
+
+// MessageFilter blocks the same words in every channel, without rules.
+type MessageFilter struct {
+	filter *moderation.Filter
+}
+
+func NewMessageFilter(words ...string) *MessageFilter {
+	return &MessageFilter{
+		filter: moderation.NewFilter(words, nil),
+	}
+}
+
+func (f *MessageFilter) Filter(ctx context.Context, request *model.FilterMessageRequest) (*model.FilterMessageResponse, error) {
+	result := f.filter.Check(request.Text)
+	return &model.FilterMessageResponse{
+		Text:    result.Text,
+		Matches: result.Matches,
+	}, nil
+}
@@ -0,0 +1,30 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"streamhelper-backend/internal/usecase"
+)
+
+var ErrPasswordMismatch = errors.New("password does not match")
+
+var _ usecase.PasswordHasher = (*PasswordHasher)(nil)
+
+// PasswordHasher stores passwords with a readable prefix instead of bcrypt,
+// which keeps unit tests fast.
+type PasswordHasher struct{}
+
+func NewPasswordHasher() *PasswordHasher {
+	return &PasswordHasher{}
+}
+
+func (h *PasswordHasher) Hash(ctx context.Context, password string) (string, error) {
+	return "hashed:" + password, nil
+}
+
+func (h *PasswordHasher) Compare(ctx context.Context, hash string, password string) error {
+	if hash != "hashed:"+password {
+		return ErrPasswordMismatch
+	}
+	return nil
+}
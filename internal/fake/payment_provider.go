@@ -0,0 +1,125 @@
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"streamhelper-backend/internal/payment"
+	"streamhelper-backend/internal/usecase"
+	"sync"
+)
+
+var _ usecase.PaymentProvider = (*PaymentProvider)(nil)
+
+// FakeSignatureHeader must equal "valid" for a notification to verify.
+const FakeSignatureHeader = "X-Fake-Signature"
+
+// PaymentProvider accepts every charge and keeps its state in memory. Err,
+// when set, is returned by every call that reaches the provider. OnStatus,
+// when set, runs before a status poll is answered, to let something else
+// happen in the middle of it.
+type PaymentProvider struct {
+	Err      error
+	OnStatus func(orderID string)
+
+	mu      sync.Mutex
+	charges map[string]payment.Notification
+}
+
+func NewPaymentProvider() *PaymentProvider {
+	return &PaymentProvider{
+		charges: make(map[string]payment.Notification),
+	}
+}
+
+func (p *PaymentProvider) Name() string {
+	return "fake"
+}
+
+func (p *PaymentProvider) CreateCharge(ctx context.Context, request *payment.ChargeRequest) (*payment.Charge, error) {
+	if p.Err != nil {
+		return nil, p.Err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.charges[request.OrderID] = payment.Notification{
+		OrderID:   request.OrderID,
+		Reference: "fake-" + request.OrderID,
+		Status:    payment.StatusPending,
+		Amount:    request.Amount,
+	}
+
+	return &payment.Charge{
+		OrderID:   request.OrderID,
+		Reference: "fake-" + request.OrderID,
+		Method:    request.Method,
+		Bank:      request.Bank,
+		Status:    payment.StatusPending,
+		QRString:  "fake-qr-" + request.OrderID,
+	}, nil
+}
+
+// VerifyNotification expects the body to be a JSON payment.Notification.
+func (p *PaymentProvider) VerifyNotification(ctx context.Context, header http.Header, body []byte) (*payment.Notification, error) {
+	if header.Get(FakeSignatureHeader) != "valid" {
+		return nil, payment.ErrInvalidSignature
+	}
+
+	notification := new(payment.Notification)
+	if err := json.Unmarshal(body, notification); err != nil {
+		return nil, err
+	}
+	return notification, nil
+}
+
+func (p *PaymentProvider) Status(ctx context.Context, orderID string) (*payment.Notification, error) {
+	if p.OnStatus != nil {
+		p.OnStatus(orderID)
+	}
+	if p.Err != nil {
+		return nil, p.Err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	notification, ok := p.charges[orderID]
+	if !ok {
+		return nil, payment.ErrChargeNotFound
+	}
+	return &notification, nil
+}
+
+func (p *PaymentProvider) Refund(ctx context.Context, orderID string, amount int64, reason string) (*payment.Notification, error) {
+	if p.Err != nil {
+		return nil, p.Err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	notification, ok := p.charges[orderID]
+	if !ok {
+		return nil, payment.ErrChargeNotFound
+	}
+	if notification.Status != payment.StatusPaid {
+		return nil, payment.ErrNotRefundable
+	}
+
+	notification.Status = payment.StatusRefunded
+	p.charges[orderID] = notification
+	return &notification, nil
+}
+
+// SetStatus changes what Status reports for orderID, as if the donor paid
+// without the provider calling back.
+func (p *PaymentProvider) SetStatus(orderID string, status string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	notification := p.charges[orderID]
+	notification.Status = status
+	p.charges[orderID] = notification
+}
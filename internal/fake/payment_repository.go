@@ -0,0 +1,73 @@
+package fake
+
+import (
+	"fmt"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/usecase"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var _ usecase.PaymentRepository = (*PaymentRepository)(nil)
+
+// PaymentRepository keeps payments in memory, keyed by ID.
+type PaymentRepository struct {
+	mu       sync.RWMutex
+	payments map[string]entity.Payment
+}
+
+func NewPaymentRepository(payments ...*entity.Payment) *PaymentRepository {
+	repository := &PaymentRepository{
+		payments: make(map[string]entity.Payment),
+	}
+	for _, payment := range payments {
+		repository.payments[payment.ID] = *payment
+	}
+
+	return repository
+}
+
+func (r *PaymentRepository) Create(db *gorm.DB, payment *entity.Payment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, found := range r.payments {
+		if found.ID == payment.ID || found.DonationID == payment.DonationID {
+			return fmt.Errorf("duplicate payment for donation %q", payment.DonationID)
+		}
+	}
+
+	now := time.Now().UnixMilli()
+	if payment.CreatedAt == 0 {
+		payment.CreatedAt = now
+	}
+	if payment.UpdatedAt == 0 {
+		payment.UpdatedAt = now
+	}
+	r.payments[payment.ID] = *payment
+	return nil
+}
+
+func (r *PaymentRepository) Update(db *gorm.DB, payment *entity.Payment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	payment.UpdatedAt = time.Now().UnixMilli()
+	r.payments[payment.ID] = *payment
+	return nil
+}
+
+func (r *PaymentRepository) FindByDonationId(db *gorm.DB, payment *entity.Payment, donationId string) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, found := range r.payments {
+		if found.DonationID == donationId {
+			*payment = found
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
@@ -0,0 +1,39 @@
+package fake
+
+import (
+	"context"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/usecase"
+	"sync"
+)
+
+var _ usecase.SpeechSubmitter = (*SpeechSubmitter)(nil)
+
+// SpeechSubmitter records the donations it is asked to read aloud. It takes
+// them on when Enabled is set, like a channel using text-to-speech.
+type SpeechSubmitter struct {
+	Enabled bool
+
+	mu       sync.Mutex
+	requests []model.SubmitSpeechRequest
+}
+
+func NewSpeechSubmitter() *SpeechSubmitter {
+	return &SpeechSubmitter{}
+}
+
+func (s *SpeechSubmitter) Submit(ctx context.Context, request *model.SubmitSpeechRequest) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, *request)
+	return s.Enabled, nil
+}
+
+// Requests returns every request so far, oldest first.
+func (s *SpeechSubmitter) Requests() []model.SubmitSpeechRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]model.SubmitSpeechRequest(nil), s.requests...)
+}
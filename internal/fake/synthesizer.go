@@ -0,0 +1,42 @@
+package fake
+
+import (
+	"context"
+	"streamhelper-backend/internal/speech"
+	"streamhelper-backend/internal/usecase"
+	"sync"
+)
+
+var _ usecase.Synthesizer = (*Synthesizer)(nil)
+
+// Synthesizer speaks with the voices of speech.Stub and fails with Err while
+// it is set. It counts how often it was called.
+type Synthesizer struct {
+	speech.Stub
+	Err error
+
+	mu    sync.Mutex
+	calls int
+}
+
+func NewSynthesizer(err error) *Synthesizer {
+	return &Synthesizer{Err: err}
+}
+
+func (s *Synthesizer) Synthesize(ctx context.Context, request *speech.Request) (*speech.Audio, error) {
+	s.mu.Lock()
+	s.calls++
+	s.mu.Unlock()
+
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	return s.Stub.Synthesize(ctx, request)
+}
+
+func (s *Synthesizer) Calls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.calls
+}
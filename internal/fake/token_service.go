@@ -0,0 +1,72 @@
+package fake
+
+import (
+	"context"
+	"fmt"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/usecase"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var _ usecase.TokenService = (*TokenService)(nil)
+
+// TokenService issues sequential opaque tokens and remembers them in memory.
+type TokenService struct {
+	mu     sync.Mutex
+	next   int
+	tokens map[string]string
+}
+
+func NewTokenService() *TokenService {
+	return &TokenService{
+		tokens: make(map[string]string),
+	}
+}
+
+func (s *TokenService) CreateToken(ctx context.Context, auth *model.Auth) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.next++
+	token := fmt.Sprintf("token-%s-%d", auth.ID, s.next)
+	s.tokens[token] = auth.ID
+	return token, nil
+}
+
+func (s *TokenService) ParseToken(ctx context.Context, token string) (*model.Auth, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, ok := s.tokens[token]
+	if !ok {
+		return nil, fiber.ErrUnauthorized
+	}
+	return &model.Auth{ID: id}, nil
+}
+
+func (s *TokenService) RevokeToken(ctx context.Context, token string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.tokens[token]; !ok {
+		return false, nil
+	}
+	delete(s.tokens, token)
+	return true, nil
+}
+
+func (s *TokenService) RevokeUserTokens(ctx context.Context, id string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var revoked int64
+	for token, owner := range s.tokens {
+		if owner == id {
+			delete(s.tokens, token)
+			revoked++
+		}
+	}
+	return revoked, nil
+}
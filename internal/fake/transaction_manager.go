@@ -0,0 +1,69 @@
+package fake
+
+import (
+	"context"
+	"streamhelper-backend/internal/repository"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+var _ repository.TransactionManager = (*TransactionManager)(nil)
+
+// TransactionManager hands out transactions whose DB is nil, which the fake
+// repositories ignore. It counts commits and rollbacks so tests can assert
+// that a use case committed, or did not, without a database. CommitErr, when
+// set, fails every commit.
+type TransactionManager struct {
+	mu         sync.Mutex
+	Begun      int
+	Committed  int
+	RolledBack int
+	CommitErr  error
+}
+
+func NewTransactionManager() *TransactionManager {
+	return &TransactionManager{}
+}
+
+func (m *TransactionManager) Begin(ctx context.Context) repository.Transaction {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Begun++
+	return &transaction{manager: m}
+}
+
+type transaction struct {
+	manager *TransactionManager
+	done    bool
+}
+
+func (t *transaction) DB() *gorm.DB {
+	return nil
+}
+
+func (t *transaction) Commit() error {
+	t.manager.mu.Lock()
+	defer t.manager.mu.Unlock()
+
+	if t.manager.CommitErr != nil {
+		return t.manager.CommitErr
+	}
+	if !t.done {
+		t.done = true
+		t.manager.Committed++
+	}
+	return nil
+}
+
+func (t *transaction) Rollback() error {
+	t.manager.mu.Lock()
+	defer t.manager.mu.Unlock()
+
+	if !t.done {
+		t.done = true
+		t.manager.RolledBack++
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package fake
+
+import (
+	"fmt"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/usecase"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var _ usecase.UserRepository = (*UserRepository)(nil)
+
+// UserRepository keeps users in memory. Writes are visible immediately and
+// are not undone by a rollback.
+type UserRepository struct {
+	mu    sync.RWMutex
+	users map[string]entity.User
+}
+
+func NewUserRepository(users ...*entity.User) *UserRepository {
+	repository := &UserRepository{
+		users: make(map[string]entity.User),
+	}
+	for _, user := range users {
+		repository.users[user.ID] = *user
+	}
+
+	return repository
+}
+
+func (r *UserRepository) Create(db *gorm.DB, user *entity.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[user.ID]; ok {
+		return fmt.Errorf("duplicate key %q", user.ID)
+	}
+
+	now := time.Now().UnixMilli()
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	if user.Role == "" {
+		user.Role = entity.RoleUser
+	}
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *UserRepository) Update(db *gorm.DB, user *entity.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user.UpdatedAt = time.Now().UnixMilli()
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *UserRepository) CountById(db *gorm.DB, id any) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.users[fmt.Sprint(id)]; ok {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (r *UserRepository) FindById(db *gorm.DB, user *entity.User, id any) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	found, ok := r.users[fmt.Sprint(id)]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+
+	*user = found
+	return nil
+}
+
+func (r *UserRepository) FindByToken(db *gorm.DB, user *entity.User, token string) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, found := range r.users {
+		if token != "" && found.Token == token {
+			*user = found
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+// Get returns a copy of the stored user, for assertions.
+func (r *UserRepository) Get(id string) (entity.User, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[id]
+	return user, ok
+}
@@ -0,0 +1,123 @@
+package fixture
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Fixture is a dataset that can be loaded into an empty or an already seeded
+// database. Records are identified by their natural keys, so loading the same
+// fixture twice creates nothing the second time.
+type Fixture struct {
+	Users     []User     `json:"users" yaml:"users"`
+	Channels  []Channel  `json:"channels" yaml:"channels"`
+	Donations []Donation `json:"donations" yaml:"donations"`
+}
+
+type User struct {
+	ID       string `json:"id" yaml:"id"`
+	Name     string `json:"name" yaml:"name"`
+	Password string `json:"password" yaml:"password"`
+	Language string `json:"language,omitempty" yaml:"language,omitempty"`
+	Role     string `json:"role,omitempty" yaml:"role,omitempty"`
+	Disabled bool   `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+}
+
+// Channel is identified by its slug and owned by the user with ID Owner.
+type Channel struct {
+	Owner           string            `json:"owner" yaml:"owner"`
+	Slug            string            `json:"slug" yaml:"slug"`
+	DisplayName     string            `json:"display_name" yaml:"display_name"`
+	Bio             string            `json:"bio,omitempty" yaml:"bio,omitempty"`
+	AvatarURL       string            `json:"avatar_url,omitempty" yaml:"avatar_url,omitempty"`
+	SocialLinks     []SocialLink      `json:"social_links,omitempty" yaml:"social_links,omitempty"`
+	PlatformHandles map[string]string `json:"platform_handles,omitempty" yaml:"platform_handles,omitempty"`
+}
+
+// Donation is identified by its ID. Age places it in the past relative to
+// the time the fixture is loaded, e.g. "36h".
+type Donation struct {
+	ID        string `json:"id" yaml:"id"`
+	Channel   string `json:"channel" yaml:"channel"`
+	DonorName string `json:"donor_name" yaml:"donor_name"`
+	Message   string `json:"message,omitempty" yaml:"message,omitempty"`
+	Amount    int64  `json:"amount" yaml:"amount"`
+	Status    string `json:"status,omitempty" yaml:"status,omitempty"`
+	Anonymous bool   `json:"anonymous,omitempty" yaml:"anonymous,omitempty"`
+	Age       string `json:"age,omitempty" yaml:"age,omitempty"`
+}
+
+type SocialLink struct {
+	Label string `json:"label" yaml:"label"`
+	URL   string `json:"url" yaml:"url"`
+}
+
+// Parse decodes a fixture, the format is chosen by the file extension.
+func Parse(name string, data []byte) (*Fixture, error) {
+	fixture := new(Fixture)
+
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(fixture); err != nil {
+			return nil, fmt.Errorf("parse fixture %s : %w", name, err)
+		}
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(fixture); err != nil {
+			return nil, fmt.Errorf("parse fixture %s : %w", name, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported fixture format %q", name)
+	}
+
+	return fixture, nil
+}
+
+// ReadFile reads and parses a fixture from fsys.
+func ReadFile(fsys fs.FS, name string) (*Fixture, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(name, data)
+}
+
+// Datasets lists the names of the fixtures in fsys, without extension.
+func Datasets(fsys fs.FS) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(entry.Name(), ext))
+	}
+
+	return names, nil
+}
+
+// ReadDataset reads the dataset called name from fsys, whatever its format.
+func ReadDataset(fsys fs.FS, name string) (*Fixture, error) {
+	for _, ext := range []string{".yaml", ".yml", ".json"} {
+		if _, err := fs.Stat(fsys, name+ext); err == nil {
+			return ReadFile(fsys, name+ext)
+		}
+	}
+
+	return nil, fmt.Errorf("unknown dataset %q", name)
+}
@@ -0,0 +1,189 @@
+package fixture
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/usecase"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Result counts what a Load call created and what already existed.
+type Result struct {
+	Created int
+	Skipped int
+}
+
+// Loader writes fixtures through the use cases, so seeded data goes through
+// the same validation and password hashing as data created over HTTP, and
+// seeded paid donations count toward goals and leaderboards.
+type Loader struct {
+	Log                *logrus.Logger
+	UserUseCase        *usecase.UserUseCase
+	ChannelUseCase     *usecase.ChannelUseCase
+	DonationUseCase    *usecase.DonationUseCase
+	GoalUseCase        *usecase.GoalUseCase
+	LeaderboardUseCase *usecase.LeaderboardUseCase
+}
+
+func NewLoader(log *logrus.Logger, userUseCase *usecase.UserUseCase, channelUseCase *usecase.ChannelUseCase,
+	donationUseCase *usecase.DonationUseCase, goalUseCase *usecase.GoalUseCase,
+	leaderboardUseCase *usecase.LeaderboardUseCase) *Loader {
+	return &Loader{
+		Log:                log,
+		UserUseCase:        userUseCase,
+		ChannelUseCase:     channelUseCase,
+		DonationUseCase:    donationUseCase,
+		GoalUseCase:        goalUseCase,
+		LeaderboardUseCase: leaderboardUseCase,
+	}
+}
+
+// Load creates every record of fixture that does not exist yet. Existing
+// records are left untouched, except that role and disabled flags declared
+// in the fixture are applied again.
+func (l *Loader) Load(ctx context.Context, fixture *Fixture) (*Result, error) {
+	result := new(Result)
+
+	for _, user := range fixture.Users {
+		created, err := l.loadUser(ctx, user)
+		if err != nil {
+			return result, err
+		}
+		if created {
+			result.Created++
+		} else {
+			result.Skipped++
+		}
+	}
+
+	for _, channel := range fixture.Channels {
+		created, err := l.loadChannel(ctx, channel)
+		if err != nil {
+			return result, err
+		}
+		if created {
+			result.Created++
+		} else {
+			result.Skipped++
+		}
+	}
+
+	now := time.Now()
+	for _, donation := range fixture.Donations {
+		created, err := l.loadDonation(ctx, donation, now)
+		if err != nil {
+			return result, err
+		}
+		if created {
+			result.Created++
+		} else {
+			result.Skipped++
+		}
+	}
+
+	return result, nil
+}
+
+func (l *Loader) loadUser(ctx context.Context, user User) (bool, error) {
+	_, err := l.UserUseCase.Create(ctx, &model.RegisterUserRequest{
+		ID:       user.ID,
+		Name:     user.Name,
+		Password: user.Password,
+		Language: user.Language,
+	})
+	created := err == nil
+	if err != nil && !errors.Is(err, model.ErrUserAlreadyExists) {
+		l.Log.WithContext(ctx).Warnf("Failed to load user %s : %+v", user.ID, err)
+		return false, err
+	}
+
+	if user.Role != "" {
+		if _, err := l.UserUseCase.SetRole(ctx, &model.SetUserRoleRequest{ID: user.ID, Role: user.Role}); err != nil {
+			return created, err
+		}
+	}
+
+	if user.Disabled {
+		if _, err := l.UserUseCase.Disable(ctx, &model.DisableUserRequest{ID: user.ID}); err != nil {
+			return created, err
+		}
+	}
+
+	return created, nil
+}
+
+func (l *Loader) loadChannel(ctx context.Context, channel Channel) (bool, error) {
+	socialLinks := make([]model.SocialLink, len(channel.SocialLinks))
+	for i, link := range channel.SocialLinks {
+		socialLinks[i] = model.SocialLink{Label: link.Label, URL: link.URL}
+	}
+
+	_, err := l.ChannelUseCase.Create(ctx, &model.CreateChannelRequest{
+		UserID:          channel.Owner,
+		Slug:            channel.Slug,
+		DisplayName:     channel.DisplayName,
+		Bio:             channel.Bio,
+		AvatarURL:       channel.AvatarURL,
+		SocialLinks:     socialLinks,
+		PlatformHandles: channel.PlatformHandles,
+	})
+	if errors.Is(err, model.ErrChannelExists) {
+		return false, nil
+	}
+	if err != nil {
+		l.Log.WithContext(ctx).Warnf("Failed to load channel %s : %+v", channel.Slug, err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (l *Loader) loadDonation(ctx context.Context, donation Donation, now time.Time) (bool, error) {
+	var age time.Duration
+	if donation.Age != "" {
+		parsed, err := time.ParseDuration(donation.Age)
+		if err != nil {
+			return false, fmt.Errorf("donation %s : invalid age : %w", donation.ID, err)
+		}
+		age = parsed
+	}
+
+	status := donation.Status
+	if status == "" {
+		status = entity.DonationStatusPaid
+	}
+
+	created, err := l.DonationUseCase.Import(ctx, &model.ImportDonationRequest{
+		ID:        donation.ID,
+		Slug:      donation.Channel,
+		DonorName: donation.DonorName,
+		Message:   donation.Message,
+		Amount:    donation.Amount,
+		Status:    status,
+		Anonymous: donation.Anonymous,
+		CreatedAt: now.Add(-age).UnixMilli(),
+	})
+	if err != nil {
+		l.Log.WithContext(ctx).Warnf("Failed to load donation %s : %+v", donation.ID, err)
+		return false, err
+	}
+
+	if status == entity.DonationStatusPaid {
+		// both count a donation once, however often it is seeded
+		if err := l.GoalUseCase.Track(ctx, &model.TrackGoalRequest{DonationID: donation.ID}); err != nil {
+			l.Log.WithContext(ctx).Warnf("Failed to track donation %s goals : %+v", donation.ID, err)
+			return created, err
+		}
+		if err := l.LeaderboardUseCase.Record(ctx, &model.RecordLeaderboardRequest{DonationID: donation.ID}); err != nil {
+			l.Log.WithContext(ctx).Warnf("Failed to rank donation %s : %+v", donation.ID, err)
+			return created, err
+		}
+	}
+
+	return created, nil
+}
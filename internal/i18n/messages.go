@@ -0,0 +1,79 @@
+package i18n
+
+import "streamhelper-backend/internal/model"
+
+// messages holds the client-facing text for every error code.
+var messages = map[string]map[string]string{
+	English: {
+		model.ErrCodeBadRequest:         "Bad request",
+		model.ErrCodeValidation:         "Validation failed",
+		model.ErrCodeUnauthorized:       "Unauthorized",
+		model.ErrCodeForbidden:          "Forbidden",
+		model.ErrCodeNotFound:           "Resource not found",
+		model.ErrCodeConflict:           "Resource already exists",
+		model.ErrCodeTooManyRequests:    "Too many requests, please slow down",
+		model.ErrCodeInternal:           "Internal server error",
+		model.ErrCodeServiceUnavailable: "Service unavailable",
+		model.ErrCodeUserAlreadyExists:  "User already exists",
+		model.ErrCodeInvalidCredentials: "Invalid user id or password",
+		model.ErrCodeUserDisabled:       "User is disabled",
+		model.ErrCodeChannelExists:      "You already have a channel",
+		model.ErrCodeSlugTaken:          "This channel address is already taken",
+		model.ErrCodeAmountOutOfRange:   "Donation amount is outside the range accepted by this channel",
+		model.ErrCodeCurrency:           "Currency is not accepted by this channel",
+		model.ErrCodeInvalidSignature:   "Invalid payment notification signature",
+		model.ErrCodePaymentMethod:      "Payment method is not supported",
+		model.ErrCodePaymentCurrency:    "Currency is not supported by the payment provider",
+		model.ErrCodePaymentProvider:    "Payment provider is unavailable, please try again",
+		model.ErrCodeNotRefundable:      "Only paid donations can be refunded",
+		model.ErrCodeInvalidOverlayKey:  "Invalid overlay key, copy the overlay URL from your dashboard again",
+		model.ErrCodeInvalidEventID:     "Invalid event id",
+		model.ErrCodeAlertStatus:        "Alert is not in a status that allows this",
+		model.ErrCodeTemplateTierTaken:  "Another alert template already uses this event type and minimum amount",
+		model.ErrCodeTemplateLimit:      "You have reached the maximum number of alert templates",
+		model.ErrCodeMessageRejected:    "Your message contains words this channel does not allow",
+		model.ErrCodeInvalidFilterRule:  "Pattern is not a valid regular expression or matches empty text",
+		model.ErrCodeBlockedTermExists:  "This term is already blocked",
+		model.ErrCodeBlockedTermLimit:   "You have reached the maximum number of blocked terms",
+		model.ErrCodeSpeechVoice:        "This voice or language is not available for text-to-speech",
+		model.ErrCodeGoalDates:          "A goal must end after it starts",
+		model.ErrCodeGoalEnded:          "This goal has already ended",
+		model.ErrCodeGoalLimit:          "You have reached the maximum number of running goals",
+	},
+	Indonesian: {
+		model.ErrCodeBadRequest:         "Permintaan tidak valid",
+		model.ErrCodeValidation:         "Validasi gagal",
+		model.ErrCodeUnauthorized:       "Tidak terautentikasi",
+		model.ErrCodeForbidden:          "Akses ditolak",
+		model.ErrCodeNotFound:           "Data tidak ditemukan",
+		model.ErrCodeConflict:           "Data sudah ada",
+		model.ErrCodeTooManyRequests:    "Terlalu banyak permintaan, coba lagi nanti",
+		model.ErrCodeInternal:           "Terjadi kesalahan pada server",
+		model.ErrCodeServiceUnavailable: "Layanan sedang tidak tersedia",
+		model.ErrCodeUserAlreadyExists:  "Pengguna sudah terdaftar",
+		model.ErrCodeInvalidCredentials: "ID pengguna atau kata sandi salah",
+		model.ErrCodeUserDisabled:       "Pengguna telah dinonaktifkan",
+		model.ErrCodeChannelExists:      "Kamu sudah memiliki channel",
+		model.ErrCodeSlugTaken:          "Alamat channel ini sudah dipakai",
+		model.ErrCodeAmountOutOfRange:   "Nominal donasi di luar batas yang diterima channel ini",
+		model.ErrCodeCurrency:           "Mata uang tidak diterima oleh channel ini",
+		model.ErrCodeInvalidSignature:   "Tanda tangan notifikasi pembayaran tidak valid",
+		model.ErrCodePaymentMethod:      "Metode pembayaran tidak didukung",
+		model.ErrCodePaymentCurrency:    "Mata uang tidak didukung oleh penyedia pembayaran",
+		model.ErrCodePaymentProvider:    "Penyedia pembayaran sedang tidak tersedia, silakan coba lagi",
+		model.ErrCodeNotRefundable:      "Hanya donasi yang sudah dibayar yang dapat dikembalikan",
+		model.ErrCodeInvalidOverlayKey:  "Kunci overlay tidak valid, salin ulang URL overlay dari dashboard",
+		model.ErrCodeInvalidEventID:     "ID event tidak valid",
+		model.ErrCodeAlertStatus:        "Status alert tidak memungkinkan tindakan ini",
+		model.ErrCodeTemplateTierTaken:  "Template alert lain sudah memakai jenis event dan nominal minimum ini",
+		model.ErrCodeTemplateLimit:      "Jumlah template alert sudah mencapai batas maksimum",
+		model.ErrCodeMessageRejected:    "Pesanmu mengandung kata yang tidak diizinkan channel ini",
+		model.ErrCodeInvalidFilterRule:  "Pola bukan regular expression yang valid atau cocok dengan teks kosong",
+		model.ErrCodeBlockedTermExists:  "Kata ini sudah diblokir",
+		model.ErrCodeBlockedTermLimit:   "Jumlah kata yang diblokir sudah mencapai batas maksimum",
+		model.ErrCodeSpeechVoice:        "Suara atau bahasa ini tidak tersedia untuk text-to-speech",
+		model.ErrCodeGoalDates:          "Target harus berakhir setelah dimulai",
+		model.ErrCodeGoalEnded:          "Target ini sudah berakhir",
+		model.ErrCodeGoalLimit:          "Jumlah target yang berjalan sudah mencapai batas maksimum",
+	},
+}
@@ -0,0 +1,168 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	idTranslations "github.com/go-playground/validator/v10/translations/id"
+)
+
+const (
+	Indonesian = "id"
+	English    = "en"
+)
+
+// Supported lists the languages a client or user profile may choose.
+var Supported = []string{Indonesian, English}
+
+type Translator struct {
+	Default   string
+	universal *ut.UniversalTranslator
+}
+
+// NewTranslator registers the validator's built-in translations for every
+// supported language, so validation messages can be rendered per request.
+func NewTranslator(validate *validator.Validate, defaultLanguage string) (*Translator, error) {
+	universal := ut.New(en.New(), en.New(), id.New())
+
+	enTrans, _ := universal.GetTranslator(English)
+	if err := enTranslations.RegisterDefaultTranslations(validate, enTrans); err != nil {
+		return nil, err
+	}
+
+	idTrans, _ := universal.GetTranslator(Indonesian)
+	if err := idTranslations.RegisterDefaultTranslations(validate, idTrans); err != nil {
+		return nil, err
+	}
+
+	// translations of the custom tags registered in config.NewValidator
+	custom := map[ut.Translator]map[string]string{
+		enTrans: {
+			"slug":           "{0} must be 3 to 50 lowercase letters, digits or single dashes",
+			"alert_template": "{0} may only use the donor, amount, currency and message variables, each in closed double braces",
+			"asset_url":      "{0} must be an http or https URL",
+		},
+		idTrans: {
+			"slug":           "{0} harus 3 sampai 50 huruf kecil, angka atau tanda hubung tunggal",
+			"alert_template": "{0} hanya boleh memakai variabel donor, amount, currency dan message, masing-masing di dalam kurung kurawal ganda yang tertutup",
+			"asset_url":      "{0} harus berupa URL http atau https",
+		},
+	}
+	for trans, tags := range custom {
+		for tag, text := range tags {
+			if err := validate.RegisterTranslation(tag, trans, registerTranslation(tag, text), translateField); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &Translator{
+		Default:   defaultLanguage,
+		universal: universal,
+	}, nil
+}
+
+func registerTranslation(tag string, text string) validator.RegisterTranslationsFunc {
+	return func(trans ut.Translator) error {
+		return trans.Add(tag, text, true)
+	}
+}
+
+func translateField(trans ut.Translator, fieldError validator.FieldError) string {
+	message, err := trans.T(fieldError.Tag(), fieldError.Field())
+	if err != nil {
+		return fieldError.Error()
+	}
+	return message
+}
+
+// IsSupported reports whether language can be served.
+func IsSupported(language string) bool {
+	for _, supported := range Supported {
+		if supported == language {
+			return true
+		}
+	}
+	return false
+}
+
+// Negotiate picks the best supported language from an Accept-Language
+// header. It returns "" when the header names nothing we support, so callers
+// can fall back to a user preference before the default.
+func (t *Translator) Negotiate(acceptLanguage string) string {
+	type candidate struct {
+		language string
+		quality  float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if value, ok := strings.CutPrefix(param, "q="); ok {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					quality = q
+				}
+			}
+		}
+
+		// "id-ID" and "in" (the legacy Indonesian code) both mean id
+		language, _, _ := strings.Cut(tag, "-")
+		if language == "in" {
+			language = Indonesian
+		}
+		if quality > 0 && IsSupported(language) {
+			candidates = append(candidates, candidate{language: language, quality: quality})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+	return candidates[0].language
+}
+
+// Resolve returns language if supported, otherwise the default.
+func (t *Translator) Resolve(language string) string {
+	if IsSupported(language) {
+		return language
+	}
+	return t.Default
+}
+
+// Message returns the catalog message for an error code, or fallback when
+// the code has no translation.
+func (t *Translator) Message(language string, code string, fallback string) string {
+	if message, ok := messages[t.Resolve(language)][code]; ok {
+		return message
+	}
+	return fallback
+}
+
+// Validation renders the message of every validator error in language.
+func (t *Translator) Validation(language string, errs validator.ValidationErrors) []string {
+	trans, _ := t.universal.GetTranslator(t.Resolve(language))
+
+	result := make([]string, len(errs))
+	for i, fieldError := range errs {
+		result[i] = fieldError.Translate(trans)
+	}
+	return result
+}
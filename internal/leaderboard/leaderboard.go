@@ -0,0 +1,91 @@
+package leaderboard
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kinds of boards a channel has. All-time never resets, monthly and weekly
+// boards start over at the beginning of each calendar month and each week
+// starting on Monday, and the stream board when the streamer starts a stream.
+const (
+	KindAllTime = "all_time"
+	KindMonthly = "monthly"
+	KindWeekly  = "weekly"
+	KindStream  = "stream"
+)
+
+// Board is one ranking of a channel's donors. Donations paid from Start until
+// End count toward it, a zero Start or End leaves that side open.
+type Board struct {
+	Kind string
+	// Period tells the boards of one kind apart: the month as "2006-01",
+	// the ISO week as "2006-W01" or when the stream started in
+	// milliseconds.
+	Period string
+	Start  time.Time
+	End    time.Time
+}
+
+// Contains reports whether a donation paid at t counts toward the board.
+func (b *Board) Contains(t time.Time) bool {
+	if !b.Start.IsZero() && t.Before(b.Start) {
+		return false
+	}
+	return b.End.IsZero() || t.Before(b.End)
+}
+
+// Current returns the board of kind that t falls in. Weeks and months begin
+// at midnight in loc. The stream board of a channel that never started a
+// stream stays empty.
+func Current(kind string, t time.Time, loc *time.Location, streamStartedAt int64) Board {
+	t = t.In(loc)
+	switch kind {
+	case KindMonthly:
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+		return Board{Kind: kind, Period: start.Format("2006-01"), Start: start, End: start.AddDate(0, 1, 0)}
+	case KindWeekly:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		start := day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+		year, week := start.ISOWeek()
+		return Board{Kind: kind, Period: fmt.Sprintf("%d-W%02d", year, week), Start: start, End: start.AddDate(0, 0, 7)}
+	case KindStream:
+		if streamStartedAt == 0 {
+			return Board{Kind: kind}
+		}
+		return Board{Kind: kind, Period: strconv.FormatInt(streamStartedAt, 10), Start: time.UnixMilli(streamStartedAt)}
+	default:
+		return Board{Kind: KindAllTime}
+	}
+}
+
+// Boards returns every board a donation paid at t counts toward. The stream
+// board is left out when the donation was paid before the stream started or
+// no stream was ever started.
+func Boards(t time.Time, loc *time.Location, streamStartedAt int64) []Board {
+	boards := []Board{
+		Current(KindAllTime, t, loc, streamStartedAt),
+		Current(KindMonthly, t, loc, streamStartedAt),
+		Current(KindWeekly, t, loc, streamStartedAt),
+	}
+	if stream := Current(KindStream, t, loc, streamStartedAt); stream.Period != "" && stream.Contains(t) {
+		boards = append(boards, stream)
+	}
+	return boards
+}
+
+// Entry is the total of one donor on a board. Donor is the key donations of
+// the same donor are summed under, Name how the donor is shown.
+type Entry struct {
+	Donor  string
+	Name   string
+	Amount int64
+}
+
+// DonorKey returns the key a donor name is ranked under, so a donor is not
+// listed twice for changing the case of their name.
+func DonorKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,192 @@
+package leaderboard
+
+import (
+	"context"
+	"streamhelper-backend/internal/tracing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// StreamRetention is how long a stream board is kept after its last
+// donation, older streams are no longer shown anywhere.
+const StreamRetention = 7 * 24 * time.Hour
+
+// Store keeps the boards of every channel in Redis sorted sets, one per
+// board, scored by the donors' totals. Scores are exact for totals up to
+// 2^53. Next to each board a set holds the donations counted on it, so a
+// donation is never counted twice. Boards of past weeks and months expire
+// one period after they ended.
+type Store struct {
+	Redis *redis.Client
+}
+
+func NewStore(redisClient *redis.Client) *Store {
+	return &Store{Redis: redisClient}
+}
+
+// addScript counts donation ARGV[1] on every board in KEYS[2..], given as
+// pairs of the board and its set of counted donations. A positive amount
+// ARGV[2] is only added if the donation is not counted yet, a negative one
+// only taken off if it is. ARGV[5..] hold when each board expires in
+// milliseconds, zero for never.
+var addScript = redis.NewScript(`
+local amount = tonumber(ARGV[2])
+for i = 2, #KEYS, 2 do
+	local counted
+	if amount > 0 then
+		counted = redis.call('SADD', KEYS[i + 1], ARGV[1])
+	else
+		counted = redis.call('SREM', KEYS[i + 1], ARGV[1])
+	end
+	if counted == 1 then
+		redis.call('ZINCRBY', KEYS[i], amount, ARGV[3])
+		redis.call('ZREMRANGEBYSCORE', KEYS[i], '-inf', 0)
+	end
+	local expiresAt = tonumber(ARGV[4 + i / 2])
+	if expiresAt > 0 then
+		redis.call('PEXPIREAT', KEYS[i], expiresAt)
+		redis.call('PEXPIREAT', KEYS[i + 1], expiresAt)
+	end
+end
+if amount > 0 then
+	redis.call('HSET', KEYS[1], ARGV[3], ARGV[4])
+end
+return 0
+`)
+
+// Add counts a paid donation toward the donor's total on each board, a
+// negative amount takes a refunded one off again. Counting the same
+// donation twice, or taking it off twice, changes nothing. Donors whose
+// total drops to zero leave the board.
+func (s *Store) Add(ctx context.Context, channelID string, boards []Board, donationID string, entry *Entry) error {
+	ctx, span := tracing.Start(ctx, "LeaderboardStore.Add")
+	defer span.End()
+
+	now := time.Now()
+	keys := []string{namesKey(channelID)}
+	args := []any{donationID, entry.Amount, entry.Donor, entry.Name}
+	for i := range boards {
+		key := boardKey(channelID, &boards[i])
+		keys = append(keys, key, countedKey(key))
+		args = append(args, expiresAt(&boards[i], now))
+	}
+
+	if err := addScript.Run(ctx, s.Redis, keys, args...).Err(); err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+	return nil
+}
+
+// Replace swaps the whole board for entries, summed over the donations
+// counted, at once. Readers never see it half rebuilt.
+func (s *Store) Replace(ctx context.Context, channelID string, board *Board, entries []Entry, counted []string) error {
+	ctx, span := tracing.Start(ctx, "LeaderboardStore.Replace")
+	defer span.End()
+
+	key := boardKey(channelID, board)
+	pipe := s.Redis.TxPipeline()
+	if len(entries) == 0 {
+		pipe.Del(ctx, key, countedKey(key))
+	} else {
+		members := make([]redis.Z, 0, len(entries))
+		names := make(map[string]any, len(entries))
+		for _, entry := range entries {
+			members = append(members, redis.Z{Score: float64(entry.Amount), Member: entry.Donor})
+			names[entry.Donor] = entry.Name
+		}
+		donations := make([]any, len(counted))
+		for i, id := range counted {
+			donations[i] = id
+		}
+
+		// the same keys on every instance, the transaction keeps two
+		// rebuilds from mixing
+		building := key + ":building"
+		pipe.Del(ctx, building, countedKey(building))
+		pipe.ZAdd(ctx, building, members...)
+		pipe.SAdd(ctx, countedKey(building), donations...)
+		pipe.Rename(ctx, building, key)
+		pipe.Rename(ctx, countedKey(building), countedKey(key))
+		pipe.HSet(ctx, namesKey(channelID), names)
+		if at := expiresAt(board, time.Now()); at > 0 {
+			pipe.PExpireAt(ctx, key, time.UnixMilli(at))
+			pipe.PExpireAt(ctx, countedKey(key), time.UnixMilli(at))
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+	return nil
+}
+
+// Top returns limit entries of the board from offset on, highest total
+// first, and how many donors are on the board.
+func (s *Store) Top(ctx context.Context, channelID string, board *Board, offset int64, limit int64) ([]Entry, int64, error) {
+	ctx, span := tracing.Start(ctx, "LeaderboardStore.Top")
+	defer span.End()
+
+	key := boardKey(channelID, board)
+	pipe := s.Redis.Pipeline()
+	ranked := pipe.ZRevRangeWithScores(ctx, key, offset, offset+limit-1)
+	total := pipe.ZCard(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		tracing.RecordError(span, err)
+		return nil, 0, err
+	}
+
+	entries := make([]Entry, 0, len(ranked.Val()))
+	if len(ranked.Val()) == 0 {
+		return entries, total.Val(), nil
+	}
+
+	donors := make([]string, 0, len(ranked.Val()))
+	for _, member := range ranked.Val() {
+		donors = append(donors, member.Member.(string))
+	}
+	names, err := s.Redis.HMGet(ctx, namesKey(channelID), donors...).Result()
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, 0, err
+	}
+
+	for i, member := range ranked.Val() {
+		name, _ := names[i].(string)
+		if name == "" {
+			name = donors[i]
+		}
+		entries = append(entries, Entry{Donor: donors[i], Name: name, Amount: int64(member.Score)})
+	}
+	return entries, total.Val(), nil
+}
+
+// expiresAt returns when a board updated at now expires in milliseconds,
+// zero for never.
+func expiresAt(board *Board, now time.Time) int64 {
+	switch {
+	case board.Kind == KindStream:
+		return now.Add(StreamRetention).UnixMilli()
+	case !board.End.IsZero():
+		return board.End.Add(board.End.Sub(board.Start)).UnixMilli()
+	default:
+		return 0
+	}
+}
+
+func boardKey(channelID string, board *Board) string {
+	key := "leaderboard:" + channelID + ":" + board.Kind
+	if board.Period != "" {
+		key += ":" + board.Period
+	}
+	return key
+}
+
+func countedKey(boardKey string) string {
+	return boardKey + ":counted"
+}
+
+func namesKey(channelID string) string {
+	return "leaderboard:" + channelID + ":names"
+}
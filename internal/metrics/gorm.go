@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const gormStartKey = "metrics:start"
+
+// GormPlugin times every GORM statement through the callback chain.
+type GormPlugin struct {
+	Metrics *Metrics
+}
+
+func NewGormPlugin(metrics *Metrics) *GormPlugin {
+	return &GormPlugin{
+		Metrics: metrics,
+	}
+}
+
+func (p *GormPlugin) Name() string {
+	return "metrics"
+}
+
+func (p *GormPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	registrations := []error{
+		callback.Create().Before("gorm:create").Register("metrics:before_create", p.before),
+		callback.Create().After("gorm:create").Register("metrics:after_create", p.after("create")),
+		callback.Query().Before("gorm:query").Register("metrics:before_query", p.before),
+		callback.Query().After("gorm:query").Register("metrics:after_query", p.after("query")),
+		callback.Update().Before("gorm:update").Register("metrics:before_update", p.before),
+		callback.Update().After("gorm:update").Register("metrics:after_update", p.after("update")),
+		callback.Delete().Before("gorm:delete").Register("metrics:before_delete", p.before),
+		callback.Delete().After("gorm:delete").Register("metrics:after_delete", p.after("delete")),
+		callback.Row().Before("gorm:row").Register("metrics:before_row", p.before),
+		callback.Row().After("gorm:row").Register("metrics:after_row", p.after("row")),
+		callback.Raw().Before("gorm:raw").Register("metrics:before_raw", p.before),
+		callback.Raw().After("gorm:raw").Register("metrics:after_raw", p.after("raw")),
+	}
+
+	return errors.Join(registrations...)
+}
+
+func (p *GormPlugin) before(db *gorm.DB) {
+	db.InstanceSet(gormStartKey, time.Now())
+}
+
+func (p *GormPlugin) after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(gormStartKey)
+		if !ok {
+			return
+		}
+
+		status := "ok"
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			status = "error"
+		}
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+
+		duration := time.Since(value.(time.Time))
+		p.Metrics.DBQueryDuration.WithLabelValues(operation, table, status).Observe(duration.Seconds())
+	}
+}
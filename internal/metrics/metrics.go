@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/redis/go-redis/v9"
+)
+
+const namespace = "streamhelp"
+
+// Metrics owns a dedicated registry so Bootstrap can be called more than once
+// (tests do) without colliding on the global default registry.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	HTTPRequests        *prometheus.CounterVec
+	HTTPRequestDuration *prometheus.HistogramVec
+	DBQueryDuration     *prometheus.HistogramVec
+	RedisCommandLatency *prometheus.HistogramVec
+	Logins              *prometheus.CounterVec
+	Registrations       prometheus.Counter
+	Payments            *prometheus.CounterVec
+}
+
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		HTTPRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Number of HTTP requests by route, method and status.",
+		}, []string{"method", "route", "status"}),
+		HTTPRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by route, method and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		DBQueryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "db",
+			Name:      "query_duration_seconds",
+			Help:      "GORM query latency by operation and table.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation", "table", "status"}),
+		RedisCommandLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "redis",
+			Name:      "command_duration_seconds",
+			Help:      "Redis command latency by command name.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"command", "status"}),
+		Logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "auth",
+			Name:      "logins_total",
+			Help:      "Login attempts by result.",
+		}, []string{"result"}),
+		Registrations: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "auth",
+			Name:      "registrations_total",
+			Help:      "Number of successfully registered users.",
+		}),
+		Payments: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "payment",
+			Name:      "status_changes_total",
+			Help:      "Donation payment status changes by provider and new status.",
+		}, []string{"provider", "status"}),
+	}
+
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.HTTPRequests,
+		m.HTTPRequestDuration,
+		m.DBQueryDuration,
+		m.RedisCommandLatency,
+		m.Logins,
+		m.Registrations,
+		m.Payments,
+	)
+
+	return m
+}
+
+// RegisterDBStats exports the database/sql connection pool statistics.
+func (m *Metrics) RegisterDBStats(db *sql.DB) {
+	m.Registry.MustRegister(collectors.NewDBStatsCollector(db, "postgres"))
+}
+
+// RegisterRedisStats exports the go-redis connection pool statistics.
+func (m *Metrics) RegisterRedisStats(client *redis.Client) {
+	m.Registry.MustRegister(&redisPoolCollector{client: client})
+}
+
+// LoginSucceeded and LoginFailed are nil-safe so use cases built without
+// metrics (unit tests) do not need a registry.
+func (m *Metrics) LoginSucceeded() {
+	if m != nil {
+		m.Logins.WithLabelValues("success").Inc()
+	}
+}
+
+func (m *Metrics) LoginFailed() {
+	if m != nil {
+		m.Logins.WithLabelValues("failure").Inc()
+	}
+}
+
+func (m *Metrics) UserRegistered() {
+	if m != nil {
+		m.Registrations.Inc()
+	}
+}
+
+func (m *Metrics) PaymentStatusChanged(provider string, status string) {
+	if m != nil {
+		m.Payments.WithLabelValues(provider, status).Inc()
+	}
+}
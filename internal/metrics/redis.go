@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisHook records the latency of every command sent through a client.
+type RedisHook struct {
+	Metrics *Metrics
+}
+
+func NewRedisHook(metrics *Metrics) *RedisHook {
+	return &RedisHook{
+		Metrics: metrics,
+	}
+}
+
+func (h *RedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (h *RedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		h.observe(cmd.Name(), err, time.Since(start))
+		return err
+	}
+}
+
+func (h *RedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		h.observe("pipeline", err, time.Since(start))
+		return err
+	}
+}
+
+func (h *RedisHook) observe(command string, err error, duration time.Duration) {
+	status := "ok"
+	if err != nil && !errors.Is(err, redis.Nil) {
+		status = "error"
+	}
+	h.Metrics.RedisCommandLatency.WithLabelValues(command, status).Observe(duration.Seconds())
+}
+
+type redisPoolCollector struct {
+	client *redis.Client
+}
+
+var (
+	redisPoolHits = prometheus.NewDesc(namespace+"_redis_pool_hits_total",
+		"Number of times a free connection was found in the pool.", nil, nil)
+	redisPoolMisses = prometheus.NewDesc(namespace+"_redis_pool_misses_total",
+		"Number of times a free connection was not found in the pool.", nil, nil)
+	redisPoolTimeouts = prometheus.NewDesc(namespace+"_redis_pool_timeouts_total",
+		"Number of times a wait for a connection timed out.", nil, nil)
+	redisPoolTotalConns = prometheus.NewDesc(namespace+"_redis_pool_total_connections",
+		"Number of connections in the pool.", nil, nil)
+	redisPoolIdleConns = prometheus.NewDesc(namespace+"_redis_pool_idle_connections",
+		"Number of idle connections in the pool.", nil, nil)
+)
+
+func (c *redisPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- redisPoolHits
+	ch <- redisPoolMisses
+	ch <- redisPoolTimeouts
+	ch <- redisPoolTotalConns
+	ch <- redisPoolIdleConns
+}
+
+func (c *redisPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.client.PoolStats()
+	ch <- prometheus.MustNewConstMetric(redisPoolHits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(redisPoolMisses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(redisPoolTimeouts, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(redisPoolTotalConns, prometheus.GaugeValue, float64(stats.TotalConns))
+	ch <- prometheus.MustNewConstMetric(redisPoolIdleConns, prometheus.GaugeValue, float64(stats.IdleConns))
+}
@@ -0,0 +1,359 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// advisoryLockKey serialises migrations across every instance sharing a
+// database. The value is arbitrary but must never change.
+const advisoryLockKey = 7305417284720211
+
+const schemaTable = "schema_migrations"
+
+var fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt int64
+}
+
+type Migrator struct {
+	DB  *gorm.DB
+	Log *logrus.Logger
+	// FS must contain the migration files at its root.
+	FS fs.FS
+}
+
+func NewMigrator(db *gorm.DB, log *logrus.Logger, files fs.FS) *Migrator {
+	return &Migrator{
+		DB:  db,
+		Log: log,
+		FS:  files,
+	}
+}
+
+// Load reads and orders every migration in FS.
+func (m *Migrator) Load() ([]Migration, error) {
+	entries, err := fs.ReadDir(m.FS, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		content, err := fs.ReadFile(m.FS, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names : %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if strings.TrimSpace(migration.Up) == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up script", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Up applies pending migrations in order, at most steps of them when steps
+// is positive. It returns the number applied.
+func (m *Migrator) Up(ctx context.Context, steps int) (int, error) {
+	migrations, err := m.Load()
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	err = m.withLock(ctx, func(conn *sql.Conn) error {
+		versions, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range migrations {
+			if _, ok := versions[migration.Version]; ok {
+				continue
+			}
+			if steps > 0 && applied >= steps {
+				break
+			}
+
+			m.Log.Infof("Applying migration %d_%s", migration.Version, migration.Name)
+			err := inTransaction(ctx, conn, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx,
+					"INSERT INTO "+schemaTable+" (version, name, applied_at) VALUES ($1, $2, $3)",
+					migration.Version, migration.Name, time.Now().UnixMilli())
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s : %w", migration.Version, migration.Name, err)
+			}
+			applied++
+		}
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down rolls back the most recently applied migrations, one when steps is
+// not positive. It returns the number rolled back.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	if steps <= 0 {
+		steps = 1
+	}
+
+	migrations, err := m.Load()
+	if err != nil {
+		return 0, err
+	}
+
+	rolledBack := 0
+	err = m.withLock(ctx, func(conn *sql.Conn) error {
+		versions, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0 && rolledBack < steps; i-- {
+			migration := migrations[i]
+			if _, ok := versions[migration.Version]; !ok {
+				continue
+			}
+			if strings.TrimSpace(migration.Down) == "" {
+				return fmt.Errorf("migration %d_%s has no down script", migration.Version, migration.Name)
+			}
+
+			m.Log.Infof("Rolling back migration %d_%s", migration.Version, migration.Name)
+			err := inTransaction(ctx, conn, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, "DELETE FROM "+schemaTable+" WHERE version = $1", migration.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s : %w", migration.Version, migration.Name, err)
+			}
+			rolledBack++
+		}
+		return nil
+	})
+
+	return rolledBack, err
+}
+
+// Status lists every known migration and whether it has been applied.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	migrations, err := m.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []Status
+	err = m.withConn(ctx, func(conn *sql.Conn) error {
+		if err := ensureSchemaTable(ctx, conn); err != nil {
+			return err
+		}
+		versions, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range migrations {
+			appliedAt, applied := versions[migration.Version]
+			statuses = append(statuses, Status{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				Applied:   applied,
+				AppliedAt: appliedAt,
+			})
+		}
+		return nil
+	})
+
+	return statuses, err
+}
+
+// Pending returns the migrations that have not been applied yet.
+func (m *Migrator) Pending(ctx context.Context) ([]Status, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]Status, 0)
+	for _, status := range statuses {
+		if !status.Applied {
+			pending = append(pending, status)
+		}
+	}
+	return pending, nil
+}
+
+// Create writes an empty up/down pair to dir, numbered after the highest
+// version already there.
+func Create(dir string, name string) ([]string, error) {
+	name = strings.Trim(regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, errors.New("migration name is required")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var next int64 = 1
+	for _, entry := range entries {
+		if match := fileName.FindStringSubmatch(entry.Name()); match != nil {
+			version, _ := strconv.ParseInt(match[1], 10, 64)
+			if version >= next {
+				next = version + 1
+			}
+		}
+	}
+
+	var files []string
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(dir, fmt.Sprintf("%06d_%s.%s.sql", next, name, direction))
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			return files, err
+		}
+		files = append(files, path)
+	}
+
+	return files, nil
+}
+
+func (m *Migrator) withConn(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	sqlDB, err := m.DB.DB()
+	if err != nil {
+		return err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return fn(conn)
+}
+
+// withLock holds the advisory lock on a single connection for the whole run,
+// since advisory locks belong to the session that took them.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	return m.withConn(ctx, func(conn *sql.Conn) error {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", advisoryLockKey); err != nil {
+			return fmt.Errorf("acquire migration lock : %w", err)
+		}
+		defer func() {
+			if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", advisoryLockKey); err != nil {
+				m.Log.Warnf("Failed to release migration lock : %+v", err)
+			}
+		}()
+
+		if err := ensureSchemaTable(ctx, conn); err != nil {
+			return err
+		}
+		return fn(conn)
+	})
+}
+
+func ensureSchemaTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+schemaTable+` (
+    version    BIGINT       NOT NULL,
+    name       VARCHAR(255) NOT NULL,
+    applied_at BIGINT       NOT NULL,
+    PRIMARY KEY (version)
+)`)
+	return err
+}
+
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]int64, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM "+schemaTable)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := map[int64]int64{}
+	for rows.Next() {
+		var version, appliedAt int64
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		versions[version] = appliedAt
+	}
+	return versions, rows.Err()
+}
+
+func inTransaction(ctx context.Context, conn *sql.Conn, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
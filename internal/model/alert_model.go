@@ -0,0 +1,71 @@
+package model
+
+import "encoding/json"
+
+// AlertResponse is an alert as the dashboard lists it and as the overlay
+// receives it in alert events. Duration is in milliseconds.
+type AlertResponse struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	Duration  int64           `json:"duration"`
+	Status    string          `json:"status"`
+	CreatedAt int64           `json:"created_at"`
+	Display   *AlertDisplay   `json:"display,omitempty"`
+}
+
+func (a *AlertResponse) GetId() string {
+	return a.ID
+}
+
+// AlertQueueResponse is the alert queue of a channel. Current is on screen
+// until Until, in unix milliseconds. History lists the latest alerts first.
+type AlertQueueResponse struct {
+	Paused  bool            `json:"paused"`
+	Current *AlertResponse  `json:"current"`
+	Until   int64           `json:"until,omitempty"`
+	Review  []AlertResponse `json:"review"`
+	Queue   []AlertResponse `json:"queue"`
+	History []AlertResponse `json:"history"`
+}
+
+// AlertSettingsResponse holds how a channel shows alerts. MinDuration is in
+// seconds.
+type AlertSettingsResponse struct {
+	Moderation  bool `json:"moderation"`
+	MinDuration int  `json:"min_duration"`
+}
+
+type UpdateAlertSettingsRequest struct {
+	UserID      string `json:"-" validate:"required,max=100"`
+	Moderation  *bool  `json:"moderation,omitempty"`
+	MinDuration *int   `json:"min_duration,omitempty" validate:"omitempty,min=1,max=60"`
+}
+
+type GetAlertQueueRequest struct {
+	UserID string `json:"-" validate:"required,max=100"`
+}
+
+// AlertActionRequest approves, rejects, skips or replays one alert.
+type AlertActionRequest struct {
+	UserID  string `json:"-" validate:"required,max=100"`
+	AlertID string `json:"-" validate:"required,max=100"`
+}
+
+// EnqueueAlertRequest queues an alert for Data, whose ID becomes the alert
+// ID. Hold sends it to review even when the channel does not moderate.
+// Speech is the event's message read aloud, if any.
+type EnqueueAlertRequest struct {
+	ChannelID string       `json:"-" validate:"required,max=36"`
+	Type      string       `json:"-" validate:"required,max=50"`
+	Data      Event        `json:"-" validate:"required"`
+	Hold      bool         `json:"-"`
+	Speech    *AlertSpeech `json:"-"`
+}
+
+// AlertSpeech is synthesized speech played with an alert. Duration is in
+// milliseconds.
+type AlertSpeech struct {
+	URL      string
+	Duration int64
+}
@@ -0,0 +1,93 @@
+package model
+
+// AlertDisplay is how the overlay shows an alert. Text is rendered from the
+// template and must be displayed as plain text, never as HTML. Duration is in
+// milliseconds.
+type AlertDisplay struct {
+	TemplateID string `json:"template_id,omitempty"`
+	Text       string `json:"text"`
+	ImageURL   string `json:"image_url,omitempty"`
+	SoundURL   string `json:"sound_url,omitempty"`
+	Animation  string `json:"animation"`
+	SpeechURL  string `json:"speech_url,omitempty"`
+	Duration   int64  `json:"duration,omitempty"`
+}
+
+// AlertTemplateData holds what an alert template can say about an event.
+// Amount is in the smallest unit of Currency.
+type AlertTemplateData struct {
+	Donor    string
+	Amount   int64
+	Currency string
+	Message  string
+}
+
+// AlertSource is an event whose alert is rendered from a template.
+type AlertSource interface {
+	Event
+	AlertTemplateData() *AlertTemplateData
+}
+
+type AlertTemplateResponse struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	EventType string `json:"event_type"`
+	MinAmount int64  `json:"min_amount"`
+	Text      string `json:"text"`
+	ImageURL  string `json:"image_url"`
+	SoundURL  string `json:"sound_url"`
+	Duration  int    `json:"duration"`
+	Animation string `json:"animation"`
+	CreatedAt int64  `json:"created_at"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+// CreateAlertTemplateRequest adds a template for EventType alerts of at
+// least MinAmount. Text may use {{donor}}, {{amount}}, {{currency}} and
+// {{message}}. Duration is in seconds, 0 keeps the channel's minimum.
+type CreateAlertTemplateRequest struct {
+	UserID    string `json:"-" validate:"required,max=100"`
+	Name      string `json:"name" validate:"required,max=50"`
+	EventType string `json:"event_type" validate:"required,oneof=donation"`
+	MinAmount int64  `json:"min_amount" validate:"min=0"`
+	Text      string `json:"text" validate:"required,max=500,alert_template"`
+	ImageURL  string `json:"image_url" validate:"max=255,asset_url"`
+	SoundURL  string `json:"sound_url" validate:"max=255,asset_url"`
+	Duration  int    `json:"duration" validate:"min=0,max=60"`
+	Animation string `json:"animation" validate:"omitempty,oneof=fade slide bounce zoom none"`
+}
+
+// UpdateAlertTemplateRequest changes only the fields that are sent, an empty
+// image or sound URL removes it.
+type UpdateAlertTemplateRequest struct {
+	UserID     string  `json:"-" validate:"required,max=100"`
+	TemplateID string  `json:"-" validate:"required,max=36"`
+	Name       string  `json:"name,omitempty" validate:"max=50"`
+	MinAmount  *int64  `json:"min_amount,omitempty" validate:"omitempty,min=0"`
+	Text       string  `json:"text,omitempty" validate:"omitempty,max=500,alert_template"`
+	ImageURL   *string `json:"image_url,omitempty" validate:"omitnil,max=255,asset_url"`
+	SoundURL   *string `json:"sound_url,omitempty" validate:"omitnil,max=255,asset_url"`
+	Duration   *int    `json:"duration,omitempty" validate:"omitempty,min=0,max=60"`
+	Animation  string  `json:"animation,omitempty" validate:"omitempty,oneof=fade slide bounce zoom none"`
+}
+
+type GetAlertTemplateRequest struct {
+	UserID     string `json:"-" validate:"required,max=100"`
+	TemplateID string `json:"-" validate:"required,max=36"`
+}
+
+type ListAlertTemplateRequest struct {
+	UserID string `json:"-" validate:"required,max=100"`
+}
+
+// PreviewAlertTemplateRequest renders the alert an event would get. With
+// Text the unsaved text is rendered, otherwise the channel's templates are
+// chosen the same way as for real events.
+type PreviewAlertTemplateRequest struct {
+	UserID    string `json:"-" validate:"required,max=100"`
+	Text      string `json:"text,omitempty" validate:"omitempty,max=500,alert_template"`
+	EventType string `json:"event_type" validate:"required,oneof=donation"`
+	Donor     string `json:"donor" validate:"max=50"`
+	Amount    int64  `json:"amount" validate:"min=0"`
+	Message   string `json:"message" validate:"max=255"`
+}
@@ -0,0 +1,57 @@
+package model
+
+type ChannelResponse struct {
+	ID              string            `json:"id,omitempty"`
+	Slug            string            `json:"slug,omitempty"`
+	DisplayName     string            `json:"display_name,omitempty"`
+	Bio             string            `json:"bio,omitempty"`
+	AvatarURL       string            `json:"avatar_url,omitempty"`
+	SocialLinks     []SocialLink      `json:"social_links,omitempty"`
+	PlatformHandles map[string]string `json:"platform_handles,omitempty"`
+	Currency        string            `json:"currency,omitempty"`
+	MinDonation     int64             `json:"min_donation,omitempty"`
+	MaxDonation     int64             `json:"max_donation,omitempty"`
+	CreatedAt       int64             `json:"created_at,omitempty"`
+	UpdatedAt       int64             `json:"updated_at,omitempty"`
+}
+
+type SocialLink struct {
+	Label string `json:"label" validate:"required,max=50"`
+	URL   string `json:"url" validate:"required,url,max=255"`
+}
+
+type CreateChannelRequest struct {
+	UserID          string            `json:"-" validate:"required,max=100"`
+	Slug            string            `json:"slug" validate:"required,slug"`
+	DisplayName     string            `json:"display_name" validate:"required,max=100"`
+	Bio             string            `json:"bio" validate:"max=500"`
+	AvatarURL       string            `json:"avatar_url" validate:"omitempty,url,max=255"`
+	SocialLinks     []SocialLink      `json:"social_links" validate:"max=10,dive"`
+	PlatformHandles map[string]string `json:"platform_handles" validate:"max=10,dive,keys,oneof=youtube twitch tiktok kick facebook instagram,endkeys,required,max=100"`
+	Currency        string            `json:"currency" validate:"omitempty,iso4217"`
+	MinDonation     int64             `json:"min_donation" validate:"min=0"`
+	MaxDonation     int64             `json:"max_donation" validate:"min=0"`
+}
+
+// UpdateChannelRequest changes only the fields that are sent. SocialLinks and
+// PlatformHandles replace the stored values when present.
+type UpdateChannelRequest struct {
+	UserID          string            `json:"-" validate:"required,max=100"`
+	Slug            string            `json:"slug,omitempty" validate:"omitempty,slug"`
+	DisplayName     string            `json:"display_name,omitempty" validate:"max=100"`
+	Bio             *string           `json:"bio,omitempty" validate:"omitempty,max=500"`
+	AvatarURL       *string           `json:"avatar_url,omitempty" validate:"omitempty,max=255"`
+	SocialLinks     []SocialLink      `json:"social_links,omitempty" validate:"max=10,dive"`
+	PlatformHandles map[string]string `json:"platform_handles,omitempty" validate:"max=10,dive,keys,oneof=youtube twitch tiktok kick facebook instagram,endkeys,required,max=100"`
+	Currency        string            `json:"currency,omitempty" validate:"omitempty,iso4217"`
+	MinDonation     *int64            `json:"min_donation,omitempty" validate:"omitempty,min=1"`
+	MaxDonation     *int64            `json:"max_donation,omitempty" validate:"omitempty,min=0"`
+}
+
+type GetChannelRequest struct {
+	Slug string `json:"slug" validate:"required,max=50"`
+}
+
+type GetCurrentChannelRequest struct {
+	UserID string `json:"-" validate:"required,max=100"`
+}
@@ -0,0 +1,51 @@
+package converter
+
+import (
+	"streamhelper-backend/internal/alert"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+)
+
+func AlertToResponse(item *alert.Alert) *model.AlertResponse {
+	response := &model.AlertResponse{
+		ID:        item.ID,
+		Type:      item.Type,
+		Data:      item.Data,
+		Duration:  item.Duration,
+		Status:    item.Status,
+		CreatedAt: item.CreatedAt,
+	}
+	if item.Display != nil {
+		response.Display = AlertDisplayToResponse(item.Display, 0)
+	}
+	return response
+}
+
+func AlertStateToResponse(state *alert.State) *model.AlertQueueResponse {
+	response := &model.AlertQueueResponse{
+		Paused:  state.Paused,
+		Until:   state.Until,
+		Review:  alertsToResponse(state.Review),
+		Queue:   alertsToResponse(state.Queue),
+		History: alertsToResponse(state.History),
+	}
+	if state.Current != nil {
+		response.Current = AlertToResponse(state.Current)
+	}
+	return response
+}
+
+func AlertSettingsToResponse(channel *entity.Channel) *model.AlertSettingsResponse {
+	return &model.AlertSettingsResponse{
+		Moderation:  channel.AlertModeration,
+		MinDuration: channel.AlertDuration,
+	}
+}
+
+func alertsToResponse(items []alert.Alert) []model.AlertResponse {
+	responses := make([]model.AlertResponse, len(items))
+	for i := range items {
+		responses[i] = *AlertToResponse(&items[i])
+	}
+	return responses
+}
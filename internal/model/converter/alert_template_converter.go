@@ -0,0 +1,43 @@
+package converter
+
+import (
+	"streamhelper-backend/internal/alert"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+)
+
+func AlertTemplateToResponse(template *entity.AlertTemplate) *model.AlertTemplateResponse {
+	return &model.AlertTemplateResponse{
+		ID:        template.ID,
+		Name:      template.Name,
+		EventType: template.EventType,
+		MinAmount: template.MinAmount,
+		Text:      template.Text,
+		ImageURL:  template.ImageURL,
+		SoundURL:  template.SoundURL,
+		Duration:  template.Duration,
+		Animation: template.Animation,
+		CreatedAt: template.CreatedAt,
+		UpdatedAt: template.UpdatedAt,
+	}
+}
+
+func AlertTemplatesToResponse(templates []entity.AlertTemplate) []model.AlertTemplateResponse {
+	responses := make([]model.AlertTemplateResponse, len(templates))
+	for i := range templates {
+		responses[i] = *AlertTemplateToResponse(&templates[i])
+	}
+	return responses
+}
+
+func AlertDisplayToResponse(display *alert.Display, duration int64) *model.AlertDisplay {
+	return &model.AlertDisplay{
+		TemplateID: display.TemplateID,
+		Text:       display.Text,
+		ImageURL:   display.ImageURL,
+		SoundURL:   display.SoundURL,
+		Animation:  display.Animation,
+		SpeechURL:  display.SpeechURL,
+		Duration:   duration,
+	}
+}
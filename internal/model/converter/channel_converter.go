@@ -0,0 +1,36 @@
+package converter
+
+import (
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+)
+
+func ChannelToResponse(channel *entity.Channel) *model.ChannelResponse {
+	socialLinks := make([]model.SocialLink, len(channel.SocialLinks))
+	for i, link := range channel.SocialLinks {
+		socialLinks[i] = model.SocialLink{Label: link.Label, URL: link.URL}
+	}
+
+	return &model.ChannelResponse{
+		ID:              channel.ID,
+		Slug:            channel.Slug,
+		DisplayName:     channel.DisplayName,
+		Bio:             channel.Bio,
+		AvatarURL:       channel.AvatarURL,
+		SocialLinks:     socialLinks,
+		PlatformHandles: channel.PlatformHandles,
+		Currency:        channel.Currency,
+		MinDonation:     channel.MinDonation,
+		MaxDonation:     channel.MaxDonation,
+		CreatedAt:       channel.CreatedAt,
+		UpdatedAt:       channel.UpdatedAt,
+	}
+}
+
+func SocialLinksToEntity(links []model.SocialLink) []entity.SocialLink {
+	result := make([]entity.SocialLink, len(links))
+	for i, link := range links {
+		result[i] = entity.SocialLink{Label: link.Label, URL: link.URL}
+	}
+	return result
+}
@@ -0,0 +1,34 @@
+package converter
+
+import (
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+)
+
+func DonationToResponse(donation *entity.Donation) *model.DonationResponse {
+	return &model.DonationResponse{
+		ID:        donation.ID,
+		ChannelID: donation.ChannelID,
+		DonorName: donation.DonorName,
+		Message:   donation.Message,
+		Amount:    donation.Amount,
+		Currency:  donation.Currency,
+		Status:    donation.Status,
+		Anonymous: donation.Anonymous,
+		Held:      donation.Held,
+		PaidAt:    donation.PaidAt,
+		CreatedAt: donation.CreatedAt,
+		UpdatedAt: donation.UpdatedAt,
+	}
+}
+
+func DonationToEvent(donation *entity.Donation) *model.DonationEvent {
+	return &model.DonationEvent{
+		ID:        donation.ID,
+		DonorName: donation.PublicDonorName(),
+		Message:   donation.Message,
+		Amount:    donation.Amount,
+		Currency:  donation.Currency,
+		PaidAt:    donation.PaidAt,
+	}
+}
@@ -0,0 +1,41 @@
+package converter
+
+import (
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+)
+
+func MessageFilterSettingsToResponse(channel *entity.Channel) *model.MessageFilterSettingsResponse {
+	return &model.MessageFilterSettingsResponse{
+		Action: channel.FilterAction,
+	}
+}
+
+func BlockedTermToResponse(term *entity.BlockedTerm) *model.BlockedTermResponse {
+	return &model.BlockedTermResponse{
+		ID:        term.ID,
+		Kind:      term.Kind,
+		Pattern:   term.Pattern,
+		CreatedAt: term.CreatedAt,
+	}
+}
+
+func BlockedTermsToResponse(terms []entity.BlockedTerm) []model.BlockedTermResponse {
+	responses := make([]model.BlockedTermResponse, len(terms))
+	for i := range terms {
+		responses[i] = *BlockedTermToResponse(&terms[i])
+	}
+	return responses
+}
+
+func FilteredMessageToResponse(message *entity.FilteredMessage) *model.FilteredMessageResponse {
+	return &model.FilteredMessageResponse{
+		ID:         message.ID,
+		DonationID: message.DonationID,
+		Action:     message.Action,
+		Original:   message.Original,
+		Filtered:   message.Filtered,
+		Matches:    message.Matches,
+		CreatedAt:  message.CreatedAt,
+	}
+}
@@ -0,0 +1,38 @@
+package converter
+
+import (
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+)
+
+// GoalToResponse converts a goal with its status at now.
+func GoalToResponse(goal *entity.Goal, now int64) *model.GoalResponse {
+	sources := goal.Sources
+	if sources == nil {
+		sources = []string{}
+	}
+
+	return &model.GoalResponse{
+		ID:           goal.ID,
+		Title:        goal.Title,
+		TargetAmount: goal.TargetAmount,
+		Amount:       goal.Amount,
+		Percent:      goal.Amount * 100 / goal.TargetAmount,
+		Currency:     goal.Currency,
+		Sources:      sources,
+		Status:       goal.Status(now),
+		StartsAt:     goal.StartsAt,
+		EndsAt:       goal.EndsAt,
+		CompletedAt:  goal.CompletedAt,
+		CreatedAt:    goal.CreatedAt,
+		UpdatedAt:    goal.UpdatedAt,
+	}
+}
+
+func GoalsToResponse(goals []entity.Goal, now int64) []model.GoalResponse {
+	responses := make([]model.GoalResponse, len(goals))
+	for i := range goals {
+		responses[i] = *GoalToResponse(&goals[i], now)
+	}
+	return responses
+}
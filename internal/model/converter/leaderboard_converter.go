@@ -0,0 +1,39 @@
+package converter
+
+import (
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/leaderboard"
+	"streamhelper-backend/internal/model"
+)
+
+// LeaderboardToResponse converts the entries of a board starting at rank
+// offset+1.
+func LeaderboardToResponse(board *leaderboard.Board, currency string, entries []leaderboard.Entry, offset int64) *model.LeaderboardResponse {
+	response := &model.LeaderboardResponse{
+		Board:    board.Kind,
+		Period:   board.Period,
+		Currency: currency,
+		Entries:  make([]model.LeaderboardEntryResponse, len(entries)),
+	}
+	if !board.Start.IsZero() {
+		response.StartsAt = board.Start.UnixMilli()
+	}
+	if !board.End.IsZero() {
+		response.EndsAt = board.End.UnixMilli()
+	}
+	for i, entry := range entries {
+		response.Entries[i] = model.LeaderboardEntryResponse{
+			Rank:      offset + int64(i) + 1,
+			DonorName: entry.Name,
+			Amount:    entry.Amount,
+		}
+	}
+	return response
+}
+
+func LeaderboardSettingsToResponse(channel *entity.Channel) *model.LeaderboardSettingsResponse {
+	return &model.LeaderboardSettingsResponse{
+		Overlay:         channel.OverlayRankings,
+		StreamStartedAt: channel.StreamStartedAt,
+	}
+}
@@ -0,0 +1,19 @@
+package converter
+
+import (
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+)
+
+func PaymentToResponse(payment *entity.Payment) *model.PaymentResponse {
+	return &model.PaymentResponse{
+		Provider:    payment.Provider,
+		Method:      payment.Method,
+		Bank:        payment.Bank,
+		Status:      payment.Status,
+		QRString:    payment.QRString,
+		RedirectURL: payment.RedirectURL,
+		VANumber:    payment.VANumber,
+		ExpiresAt:   payment.ExpiresAt,
+	}
+}
@@ -0,0 +1,28 @@
+package converter
+
+import (
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/speech"
+)
+
+func SpeechSettingsToResponse(channel *entity.Channel) *model.SpeechSettingsResponse {
+	return &model.SpeechSettingsResponse{
+		Enabled:   channel.TTSEnabled,
+		Voice:     channel.TTSVoice,
+		Language:  channel.TTSLanguage,
+		MaxLength: channel.TTSMaxLength,
+	}
+}
+
+func SpeechVoicesToResponse(voices []speech.Voice) []model.SpeechVoiceResponse {
+	responses := make([]model.SpeechVoiceResponse, len(voices))
+	for i, voice := range voices {
+		responses[i] = model.SpeechVoiceResponse{
+			ID:       voice.ID,
+			Language: voice.Language,
+			Name:     voice.Name,
+		}
+	}
+	return responses
+}
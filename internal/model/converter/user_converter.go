@@ -9,6 +9,9 @@ func UserToResponse(user *entity.User) *model.UserResponse{
 	return &model.UserResponse{
 		ID: 		user.ID,
 		Name: 		user.Name,
+		Language: 	user.Language,
+		Role: 		user.Role,
+		Disabled: 	user.Disabled(),
 		CreatedAt: 	user.CreatedAt,
 		UpdatedAt: 	user.UpdatedAt,
 	}
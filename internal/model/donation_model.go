@@ -0,0 +1,52 @@
+package model
+
+type DonationResponse struct {
+	ID        string `json:"id,omitempty"`
+	ChannelID string `json:"channel_id,omitempty"`
+	DonorName string `json:"donor_name,omitempty"`
+	Message   string `json:"message,omitempty"`
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency,omitempty"`
+	Status    string `json:"status,omitempty"`
+	Anonymous bool   `json:"anonymous"`
+	Held      bool   `json:"held,omitempty"`
+	PaidAt    int64  `json:"paid_at,omitempty"`
+	CreatedAt int64  `json:"created_at,omitempty"`
+	UpdatedAt int64  `json:"updated_at,omitempty"`
+	// Payment is only shown to the donor, never in the streamer's history.
+	Payment *PaymentResponse `json:"payment,omitempty"`
+}
+
+// CreateDonationRequest is sent by a viewer from the public tipping page, no
+// account is needed. Currency defaults to the channel's currency. Bank is
+// required for bank transfers, which are paid into a virtual account.
+type CreateDonationRequest struct {
+	Slug          string `json:"-" validate:"required,max=50"`
+	DonorName     string `json:"donor_name" validate:"required,max=50"`
+	Message       string `json:"message" validate:"max=255"`
+	Amount        int64  `json:"amount" validate:"required,min=1"`
+	Currency      string `json:"currency" validate:"omitempty,iso4217"`
+	Anonymous     bool   `json:"anonymous"`
+	PaymentMethod string `json:"payment_method" validate:"required,oneof=qris gopay shopeepay bank_transfer"`
+	Bank          string `json:"bank" validate:"required_if=PaymentMethod bank_transfer,omitempty,oneof=bca bni bri"`
+}
+
+type SearchDonationRequest struct {
+	UserID string `json:"-" validate:"required,max=100"`
+	Status string `json:"status" validate:"omitempty,oneof=pending paid failed expired refunded"`
+	Page   int    `json:"page" validate:"min=1"`
+	Size   int    `json:"size" validate:"min=1,max=100"`
+}
+
+// ImportDonationRequest loads a historic donation as is, used by fixtures.
+// ID makes the import idempotent.
+type ImportDonationRequest struct {
+	ID        string `json:"id" validate:"required,max=36"`
+	Slug      string `json:"slug" validate:"required,max=50"`
+	DonorName string `json:"donor_name" validate:"required,max=50"`
+	Message   string `json:"message" validate:"max=255"`
+	Amount    int64  `json:"amount" validate:"required,min=1"`
+	Status    string `json:"status" validate:"required,oneof=pending paid failed expired refunded"`
+	Anonymous bool   `json:"anonymous"`
+	CreatedAt int64  `json:"created_at" validate:"min=0"`
+}
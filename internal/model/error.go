@@ -0,0 +1,215 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Stable, machine-readable error codes. Clients switch on these, so existing
+// values must never change meaning.
+const (
+	ErrCodeBadRequest         = "BAD_REQUEST"
+	ErrCodeValidation         = "VALIDATION_FAILED"
+	ErrCodeUnauthorized       = "UNAUTHORIZED"
+	ErrCodeForbidden          = "FORBIDDEN"
+	ErrCodeNotFound           = "NOT_FOUND"
+	ErrCodeConflict           = "CONFLICT"
+	ErrCodeTooManyRequests    = "TOO_MANY_REQUESTS"
+	ErrCodeInternal           = "INTERNAL_ERROR"
+	ErrCodeServiceUnavailable = "SERVICE_UNAVAILABLE"
+	ErrCodeUserAlreadyExists  = "USER_ALREADY_EXISTS"
+	ErrCodeInvalidCredentials = "INVALID_CREDENTIALS"
+	ErrCodeUserDisabled       = "USER_DISABLED"
+	ErrCodeChannelExists      = "CHANNEL_ALREADY_EXISTS"
+	ErrCodeSlugTaken          = "SLUG_TAKEN"
+	ErrCodeAmountOutOfRange   = "DONATION_AMOUNT_OUT_OF_RANGE"
+	ErrCodeCurrency           = "CURRENCY_NOT_ACCEPTED"
+	ErrCodeInvalidSignature   = "INVALID_SIGNATURE"
+	ErrCodePaymentMethod      = "PAYMENT_METHOD_NOT_SUPPORTED"
+	ErrCodePaymentCurrency    = "PAYMENT_CURRENCY_NOT_SUPPORTED"
+	ErrCodePaymentProvider    = "PAYMENT_PROVIDER_ERROR"
+	ErrCodeNotRefundable      = "DONATION_NOT_REFUNDABLE"
+	ErrCodeInvalidOverlayKey  = "INVALID_OVERLAY_KEY"
+	ErrCodeInvalidEventID     = "INVALID_EVENT_ID"
+	ErrCodeAlertStatus        = "ALERT_STATUS_CONFLICT"
+	ErrCodeTemplateTierTaken  = "ALERT_TEMPLATE_TIER_TAKEN"
+	ErrCodeTemplateLimit      = "ALERT_TEMPLATE_LIMIT_REACHED"
+	ErrCodeMessageRejected    = "DONATION_MESSAGE_REJECTED"
+	ErrCodeInvalidFilterRule  = "INVALID_FILTER_RULE"
+	ErrCodeBlockedTermExists  = "BLOCKED_TERM_EXISTS"
+	ErrCodeBlockedTermLimit   = "BLOCKED_TERM_LIMIT_REACHED"
+	ErrCodeSpeechVoice        = "SPEECH_VOICE_NOT_SUPPORTED"
+	ErrCodeGoalDates          = "GOAL_INVALID_DATES"
+	ErrCodeGoalEnded          = "GOAL_ENDED"
+	ErrCodeGoalLimit          = "GOAL_LIMIT_REACHED"
+)
+
+var (
+	ErrUserAlreadyExists  = NewAppError(http.StatusConflict, ErrCodeUserAlreadyExists, "User already exists")
+	ErrInvalidCredentials = NewAppError(http.StatusUnauthorized, ErrCodeInvalidCredentials, "Invalid user id or password")
+	ErrUserNotFound       = NewAppError(http.StatusNotFound, ErrCodeNotFound, "User not found")
+	ErrUserDisabled       = NewAppError(http.StatusForbidden, ErrCodeUserDisabled, "User is disabled")
+	ErrTooManyRequests    = NewAppError(http.StatusTooManyRequests, ErrCodeTooManyRequests, "Too many requests")
+	ErrChannelNotFound    = NewAppError(http.StatusNotFound, ErrCodeNotFound, "Channel not found")
+	ErrChannelExists      = NewAppError(http.StatusConflict, ErrCodeChannelExists, "User already has a channel")
+	ErrSlugTaken          = NewAppError(http.StatusConflict, ErrCodeSlugTaken, "Slug is already taken")
+
+	ErrDonationAmountOutOfRange = NewAppError(http.StatusBadRequest, ErrCodeAmountOutOfRange, "Donation amount is outside the range accepted by this channel")
+	ErrCurrencyNotAccepted      = NewAppError(http.StatusBadRequest, ErrCodeCurrency, "Currency is not accepted by this channel")
+	ErrDonationNotFound         = NewAppError(http.StatusNotFound, ErrCodeNotFound, "Donation not found")
+	ErrDonationNotRefundable    = NewAppError(http.StatusConflict, ErrCodeNotRefundable, "Only paid donations can be refunded")
+
+	ErrInvalidSignature            = NewAppError(http.StatusUnauthorized, ErrCodeInvalidSignature, "Invalid payment notification signature")
+	ErrPaymentMethodNotSupported   = NewAppError(http.StatusBadRequest, ErrCodePaymentMethod, "Payment method is not supported")
+	ErrPaymentCurrencyNotSupported = NewAppError(http.StatusBadRequest, ErrCodePaymentCurrency, "Currency is not supported by the payment provider")
+	ErrPaymentProvider             = NewAppError(http.StatusBadGateway, ErrCodePaymentProvider, "Payment provider is unavailable, please try again")
+	ErrPaymentProviderNotFound     = NewAppError(http.StatusNotFound, ErrCodeNotFound, "Payment provider not found")
+
+	ErrInvalidOverlayKey = NewAppError(http.StatusUnauthorized, ErrCodeInvalidOverlayKey, "Invalid overlay key")
+	ErrInvalidEventID    = NewAppError(http.StatusBadRequest, ErrCodeInvalidEventID, "Invalid event id")
+
+	ErrAlertNotFound = NewAppError(http.StatusNotFound, ErrCodeNotFound, "Alert not found")
+	ErrAlertStatus   = NewAppError(http.StatusConflict, ErrCodeAlertStatus, "Alert is not in a status that allows this")
+
+	ErrAlertTemplateNotFound  = NewAppError(http.StatusNotFound, ErrCodeNotFound, "Alert template not found")
+	ErrAlertTemplateTierTaken = NewAppError(http.StatusConflict, ErrCodeTemplateTierTaken, "Another alert template already uses this event type and minimum amount")
+	ErrAlertTemplateLimit     = NewAppError(http.StatusConflict, ErrCodeTemplateLimit, "Channel has reached the maximum number of alert templates")
+
+	ErrMessageRejected     = NewAppError(http.StatusBadRequest, ErrCodeMessageRejected, "Message contains words this channel does not allow")
+	ErrInvalidFilterRule   = NewAppError(http.StatusBadRequest, ErrCodeInvalidFilterRule, "Pattern is not a valid regular expression or matches empty text")
+	ErrBlockedTermExists   = NewAppError(http.StatusConflict, ErrCodeBlockedTermExists, "Term is already blocked")
+	ErrBlockedTermLimit    = NewAppError(http.StatusConflict, ErrCodeBlockedTermLimit, "Channel has reached the maximum number of blocked terms")
+	ErrBlockedTermNotFound = NewAppError(http.StatusNotFound, ErrCodeNotFound, "Blocked term not found")
+
+	ErrSpeechVoiceNotSupported = NewAppError(http.StatusBadRequest, ErrCodeSpeechVoice, "Voice or language is not supported by the speech engine")
+	ErrSpeechAudioNotFound     = NewAppError(http.StatusNotFound, ErrCodeNotFound, "Audio not found")
+
+	ErrGoalNotFound = NewAppError(http.StatusNotFound, ErrCodeNotFound, "Goal not found")
+	ErrGoalDates    = NewAppError(http.StatusBadRequest, ErrCodeGoalDates, "Goal must end after it starts")
+	ErrGoalEnded    = NewAppError(http.StatusConflict, ErrCodeGoalEnded, "Goal has already ended")
+	ErrGoalLimit    = NewAppError(http.StatusConflict, ErrCodeGoalLimit, "Channel has reached the maximum number of running goals")
+)
+
+// AppError is an error that knows how it should be presented to API clients.
+type AppError struct {
+	Status  int
+	Code    string
+	Message string
+	Fields  []FieldError
+	// Validation keeps the original validator errors so the error handler
+	// can render field messages in the client's language.
+	Validation validator.ValidationErrors
+	Err        error
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+func NewAppError(status int, code string, message string) *AppError {
+	return &AppError{
+		Status:  status,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (e *AppError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches on the error code, so errors.Is(err, model.ErrUserNotFound)
+// holds for wrapped copies too.
+func (e *AppError) Is(target error) bool {
+	var other *AppError
+	if !errors.As(target, &other) {
+		return false
+	}
+	return e.Code == other.Code && e.Status == other.Status
+}
+
+// Wrap returns a copy of e carrying the underlying cause, which is logged but
+// never sent to the client.
+func (e *AppError) Wrap(err error) *AppError {
+	wrapped := *e
+	wrapped.Err = err
+	return &wrapped
+}
+
+// NewValidationError converts the result of validator.Struct into an
+// AppError listing every failed field.
+func NewValidationError(err error) *AppError {
+	appError := NewAppError(http.StatusBadRequest, ErrCodeValidation, "Validation failed")
+	appError.Err = err
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return appError
+	}
+
+	appError.Validation = validationErrors
+	for _, fieldError := range validationErrors {
+		appError.Fields = append(appError.Fields, FieldError{
+			Field:   fieldError.Field(),
+			Rule:    fieldError.Tag(),
+			Param:   fieldError.Param(),
+			Message: defaultFieldMessage(fieldError),
+		})
+	}
+
+	return appError
+}
+
+func defaultFieldMessage(fieldError validator.FieldError) string {
+	switch fieldError.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", fieldError.Field())
+	case "max":
+		return fmt.Sprintf("%s must be at most %s", fieldError.Field(), boundUnit(fieldError))
+	case "min":
+		return fmt.Sprintf("%s must be at least %s", fieldError.Field(), boundUnit(fieldError))
+	case "oneof":
+		return fmt.Sprintf("%s must be one of [%s]", fieldError.Field(), fieldError.Param())
+	default:
+		return fmt.Sprintf("%s failed on the '%s' rule", fieldError.Field(), fieldError.Tag())
+	}
+}
+
+// boundUnit is the param of a min or max rule in the unit of the field, a
+// length for text, a count for lists and a plain value for numbers.
+func boundUnit(fieldError validator.FieldError) string {
+	switch fieldError.Kind() {
+	case reflect.String:
+		return fieldError.Param() + " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return fieldError.Param() + " items"
+	default:
+		return fieldError.Param()
+	}
+}
+
+// ProblemDetail is the RFC 7807 application/problem+json body.
+type ProblemDetail struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	Fields    []FieldError `json:"fields,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
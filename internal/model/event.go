@@ -1,5 +1,7 @@
 package model
 
+// Event is the payload of something published to a channel's event stream.
+// GetId identifies what the event is about, e.g. the donation.
 type Event interface {
-	GetInt() string
+	GetId() string
 }
\ No newline at end of file
@@ -0,0 +1,75 @@
+package model
+
+// MessageFilterSettingsResponse holds what a channel does with donation
+// messages that contain blocked words.
+type MessageFilterSettingsResponse struct {
+	Action string `json:"action"`
+}
+
+type UpdateMessageFilterSettingsRequest struct {
+	UserID string `json:"-" validate:"required,max=100"`
+	Action string `json:"action" validate:"required,oneof=mask hold reject"`
+}
+
+type GetMessageFilterRequest struct {
+	UserID string `json:"-" validate:"required,max=100"`
+}
+
+type BlockedTermResponse struct {
+	ID        string `json:"id"`
+	Kind      string `json:"kind"`
+	Pattern   string `json:"pattern"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// CreateBlockedTermRequest blocks a whole word, matched the same forgiving
+// way as the global wordlist, or a case-insensitive regular expression.
+type CreateBlockedTermRequest struct {
+	UserID  string `json:"-" validate:"required,max=100"`
+	Kind    string `json:"kind" validate:"required,oneof=word regex"`
+	Pattern string `json:"pattern" validate:"required,max=100"`
+}
+
+type DeleteBlockedTermRequest struct {
+	UserID string `json:"-" validate:"required,max=100"`
+	TermID string `json:"-" validate:"required,max=36"`
+}
+
+type FilteredMessageResponse struct {
+	ID         string   `json:"id"`
+	DonationID string   `json:"donation_id,omitempty"`
+	Action     string   `json:"action"`
+	Original   string   `json:"original"`
+	Filtered   string   `json:"filtered"`
+	Matches    []string `json:"matches"`
+	CreatedAt  int64    `json:"created_at"`
+}
+
+type SearchFilteredMessageRequest struct {
+	UserID string `json:"-" validate:"required,max=100"`
+	Action string `json:"action" validate:"omitempty,oneof=mask hold reject"`
+	Page   int    `json:"page" validate:"min=1"`
+	Size   int    `json:"size" validate:"min=1,max=100"`
+}
+
+// FilterMessageRequest looks for blocked words in a donation message before
+// the donation is stored.
+type FilterMessageRequest struct {
+	ChannelID string `json:"-" validate:"required,max=36"`
+	Text      string `json:"-" validate:"max=255"`
+}
+
+// CheckMessageRequest lets a streamer try the filter of their channel.
+type CheckMessageRequest struct {
+	UserID string `json:"-" validate:"required,max=100"`
+	Text   string `json:"text" validate:"required,max=255"`
+}
+
+// FilterMessageResponse is Text with blocked words masked and what matched,
+// as it was written. Action is what the channel does with the message, empty
+// when nothing matched.
+type FilterMessageResponse struct {
+	Action  string   `json:"action,omitempty"`
+	Text    string   `json:"text"`
+	Matches []string `json:"matches"`
+}
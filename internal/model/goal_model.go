@@ -0,0 +1,74 @@
+package model
+
+// GoalResponse is a goal with its progress. Percent is rounded down and goes
+// past 100 once donations exceed the target. Times are unix milliseconds.
+type GoalResponse struct {
+	ID           string   `json:"id"`
+	Title        string   `json:"title"`
+	TargetAmount int64    `json:"target_amount"`
+	Amount       int64    `json:"amount"`
+	Percent      int64    `json:"percent"`
+	Currency     string   `json:"currency"`
+	Sources      []string `json:"sources"`
+	Status       string   `json:"status"`
+	StartsAt     int64    `json:"starts_at"`
+	EndsAt       int64    `json:"ends_at,omitempty"`
+	CompletedAt  int64    `json:"completed_at,omitempty"`
+	CreatedAt    int64    `json:"created_at,omitempty"`
+	UpdatedAt    int64    `json:"updated_at,omitempty"`
+}
+
+func (g *GoalResponse) GetId() string {
+	return g.ID
+}
+
+// CreateGoalRequest starts a goal. Currency defaults to the channel's
+// currency, StartsAt to now, and a goal without EndsAt runs until it is
+// ended. Sources are the payment methods that count, all when empty.
+type CreateGoalRequest struct {
+	UserID       string   `json:"-" validate:"required,max=100"`
+	Title        string   `json:"title" validate:"required,max=100"`
+	TargetAmount int64    `json:"target_amount" validate:"required,min=1"`
+	Currency     string   `json:"currency" validate:"omitempty,iso4217"`
+	Sources      []string `json:"sources" validate:"max=4,dive,oneof=qris gopay shopeepay bank_transfer"`
+	StartsAt     int64    `json:"starts_at" validate:"min=0"`
+	EndsAt       int64    `json:"ends_at" validate:"min=0"`
+}
+
+// UpdateGoalRequest changes only the fields that are sent, of a goal that
+// has not ended. An EndsAt of 0 removes the end.
+type UpdateGoalRequest struct {
+	UserID       string    `json:"-" validate:"required,max=100"`
+	GoalID       string    `json:"-" validate:"required,max=36"`
+	Title        *string   `json:"title,omitempty" validate:"omitnil,min=1,max=100"`
+	TargetAmount *int64    `json:"target_amount,omitempty" validate:"omitnil,min=1"`
+	Sources      *[]string `json:"sources,omitempty" validate:"omitnil,max=4,dive,oneof=qris gopay shopeepay bank_transfer"`
+	EndsAt       *int64    `json:"ends_at,omitempty" validate:"omitnil,min=0"`
+}
+
+type GetGoalRequest struct {
+	UserID string `json:"-" validate:"required,max=100"`
+	GoalID string `json:"-" validate:"required,max=36"`
+}
+
+type ListGoalRequest struct {
+	UserID string `json:"-" validate:"required,max=100"`
+}
+
+// ListChannelGoalRequest lists the running goals of a channel for its public
+// page.
+type ListChannelGoalRequest struct {
+	Slug string `json:"-" validate:"required,max=50"`
+}
+
+type SearchGoalHistoryRequest struct {
+	UserID string `json:"-" validate:"required,max=100"`
+	Page   int    `json:"page" validate:"min=1"`
+	Size   int    `json:"size" validate:"min=1,max=100"`
+}
+
+// TrackGoalRequest brings the goals a donation counts toward up to date
+// after its payment changed.
+type TrackGoalRequest struct {
+	DonationID string `json:"-" validate:"required,max=36"`
+}
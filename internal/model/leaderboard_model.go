@@ -0,0 +1,74 @@
+package model
+
+type LeaderboardEntryResponse struct {
+	Rank      int64  `json:"rank"`
+	DonorName string `json:"donor_name"`
+	Amount    int64  `json:"amount"`
+}
+
+// LeaderboardResponse is one page of a board. Period names the month, week
+// or stream the board covers, StartsAt and EndsAt are zero where the board
+// is open.
+type LeaderboardResponse struct {
+	Board    string                     `json:"board"`
+	Period   string                     `json:"period,omitempty"`
+	StartsAt int64                      `json:"starts_at,omitempty"`
+	EndsAt   int64                      `json:"ends_at,omitempty"`
+	Currency string                     `json:"currency"`
+	Entries  []LeaderboardEntryResponse `json:"entries"`
+	Paging   PageMetadata               `json:"-"`
+}
+
+func (l *LeaderboardResponse) GetId() string {
+	return l.Board
+}
+
+// LeaderboardSettingsResponse holds whether rankings are sent to the
+// channel's overlays and when the current stream board began.
+type LeaderboardSettingsResponse struct {
+	Overlay         bool  `json:"overlay"`
+	StreamStartedAt int64 `json:"stream_started_at,omitempty"`
+}
+
+// UpdateLeaderboardSettingsRequest changes only the settings that are sent.
+type UpdateLeaderboardSettingsRequest struct {
+	UserID  string `json:"-" validate:"required,max=100"`
+	Overlay *bool  `json:"overlay,omitempty"`
+}
+
+type GetLeaderboardSettingsRequest struct {
+	UserID string `json:"-" validate:"required,max=100"`
+}
+
+type GetLeaderboardRequest struct {
+	UserID string `json:"-" validate:"required,max=100"`
+	Board  string `json:"-" validate:"required,oneof=all_time monthly weekly stream"`
+	Page   int    `json:"page" validate:"min=1"`
+	Size   int    `json:"size" validate:"min=1,max=100"`
+}
+
+// GetChannelLeaderboardRequest reads a board of a channel for its public
+// page or its overlay.
+type GetChannelLeaderboardRequest struct {
+	Slug  string `json:"-" validate:"required,max=50"`
+	Board string `json:"-" validate:"required,oneof=all_time monthly weekly stream"`
+	Page  int    `json:"page" validate:"min=1"`
+	Size  int    `json:"size" validate:"min=1,max=100"`
+}
+
+// StartStreamRequest starts a new stream board, the previous stream's
+// ranking is no longer shown.
+type StartStreamRequest struct {
+	UserID string `json:"-" validate:"required,max=100"`
+}
+
+// RebuildLeaderboardRequest sums every board of a channel again from its
+// donations, after Redis lost them.
+type RebuildLeaderboardRequest struct {
+	UserID string `json:"-" validate:"required,max=100"`
+}
+
+// RecordLeaderboardRequest ranks a donation after its payment changed.
+type RecordLeaderboardRequest struct {
+	DonationID string `json:"-" validate:"required,max=36"`
+}
@@ -4,6 +4,8 @@ type WebResponse[T any] struct {
 	Data 			T				`json:"data"`
 	Paging			*PageMetadata 	`json:"paging,omitempty"`
 	Errors			string			`json:"errors,omitempty"`
+	Code			string			`json:"code,omitempty"`
+	Fields			[]FieldError	`json:"fields,omitempty"`
 }
 
 type PageResponse[T any] struct {
@@ -0,0 +1,102 @@
+package model
+
+import "encoding/json"
+
+// Event types on a channel's event stream.
+const (
+	EventTypeDonation = "donation"
+	// EventTypeAlert tells the overlay to show an alert, EventTypeAlertSkipped
+	// to take it off screen early.
+	EventTypeAlert        = "alert"
+	EventTypeAlertSkipped = "alert_skipped"
+	// EventTypeGoalProgress carries a running goal whenever it changes,
+	// EventTypeGoalCompleted once it reaches its target and EventTypeGoalEnded
+	// when it is ended or deleted early.
+	EventTypeGoalProgress  = "goal_progress"
+	EventTypeGoalCompleted = "goal_completed"
+	EventTypeGoalEnded     = "goal_ended"
+	// EventTypeLeaderboard carries the top of a board whenever its ranking
+	// changes.
+	EventTypeLeaderboard = "leaderboard"
+)
+
+// Operations of the overlay WebSocket protocol. The server sends hello,
+// event and heartbeat, the client sends ack and pong.
+const (
+	OverlayOpHello     = "hello"
+	OverlayOpEvent     = "event"
+	OverlayOpHeartbeat = "heartbeat"
+	OverlayOpAck       = "ack"
+	OverlayOpPong      = "pong"
+)
+
+// DonationEvent is shown by the overlay when a donation is paid. DonorName
+// is already anonymised.
+type DonationEvent struct {
+	ID        string `json:"id"`
+	DonorName string `json:"donor_name"`
+	Message   string `json:"message,omitempty"`
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency"`
+	PaidAt    int64  `json:"paid_at"`
+}
+
+func (d *DonationEvent) GetId() string {
+	return d.ID
+}
+
+func (d *DonationEvent) AlertTemplateData() *AlertTemplateData {
+	return &AlertTemplateData{
+		Donor:    d.DonorName,
+		Amount:   d.Amount,
+		Currency: d.Currency,
+		Message:  d.Message,
+	}
+}
+
+// OverlayMessage is one WebSocket message in either direction.
+type OverlayMessage struct {
+	Op string `json:"op"`
+	// ID is the event being sent or acknowledged.
+	ID    string          `json:"id,omitempty"`
+	Event json.RawMessage `json:"event,omitempty"`
+	// Hello tells the client where the stream resumes and how often to
+	// expect a heartbeat, missing two means the connection is dead.
+	ChannelID         string `json:"channel_id,omitempty"`
+	LastEventID       string `json:"last_event_id,omitempty"`
+	HeartbeatInterval int64  `json:"heartbeat_interval,omitempty"`
+	Time              int64  `json:"time,omitempty"`
+	// Gap is set on hello when only the newest missed events are replayed,
+	// the client has to reload what it shows instead of catching up.
+	Gap bool `json:"gap,omitempty"`
+}
+
+// OverlayResponse is shown to the streamer to set up the OBS browser source.
+type OverlayResponse struct {
+	ChannelID string `json:"channel_id"`
+	Key       string `json:"key"`
+}
+
+type GetOverlayRequest struct {
+	UserID string `json:"-" validate:"required,max=100"`
+}
+
+// ConnectOverlayRequest opens an overlay session. Without LastEventID the
+// session resumes after the event the client acknowledged last.
+type ConnectOverlayRequest struct {
+	Key         string `json:"-" validate:"required,max=100"`
+	Client      string `json:"-" validate:"required,max=50"`
+	LastEventID string `json:"-" validate:"max=50"`
+}
+
+// GetEventFeedRequest opens the dashboard's event feed of the user's channel.
+type GetEventFeedRequest struct {
+	UserID      string `json:"-" validate:"required,max=100"`
+	LastEventID string `json:"-" validate:"max=50"`
+}
+
+type AckOverlayRequest struct {
+	ChannelID string `json:"-" validate:"required,max=36"`
+	Client    string `json:"-" validate:"required,max=50"`
+	EventID   string `json:"-" validate:"required,max=50"`
+}
@@ -0,0 +1,36 @@
+package model
+
+import "net/http"
+
+// PaymentResponse tells the donor how to pay: scan QRString, open
+// RedirectURL or transfer to VANumber at Bank, before ExpiresAt.
+type PaymentResponse struct {
+	Provider    string `json:"provider,omitempty"`
+	Method      string `json:"method,omitempty"`
+	Bank        string `json:"bank,omitempty"`
+	Status      string `json:"status,omitempty"`
+	QRString    string `json:"qr_string,omitempty"`
+	RedirectURL string `json:"redirect_url,omitempty"`
+	VANumber    string `json:"va_number,omitempty"`
+	ExpiresAt   int64  `json:"expires_at,omitempty"`
+}
+
+// PaymentNotificationRequest is a provider callback exactly as received, the
+// signature covers the raw body.
+type PaymentNotificationRequest struct {
+	Provider string      `json:"-" validate:"required,max=20"`
+	Header   http.Header `json:"-"`
+	Body     []byte      `json:"-" validate:"required"`
+}
+
+// GetDonationPaymentRequest is polled by the tipping page until the donation
+// is no longer pending.
+type GetDonationPaymentRequest struct {
+	DonationID string `json:"-" validate:"required,max=36"`
+}
+
+type RefundDonationRequest struct {
+	UserID     string `json:"-" validate:"required,max=100"`
+	DonationID string `json:"-" validate:"required,max=36"`
+	Reason     string `json:"reason" validate:"max=255"`
+}
@@ -0,0 +1,47 @@
+package model
+
+// SpeechSettingsResponse holds how a channel reads donation messages aloud.
+// MaxLength is in characters.
+type SpeechSettingsResponse struct {
+	Enabled   bool   `json:"enabled"`
+	Voice     string `json:"voice"`
+	Language  string `json:"language"`
+	MaxLength int    `json:"max_length"`
+}
+
+// UpdateSpeechSettingsRequest changes only the settings that are sent. A
+// language without a voice picks the engine's first voice for it, a voice
+// without a language brings its own.
+type UpdateSpeechSettingsRequest struct {
+	UserID    string  `json:"-" validate:"required,max=100"`
+	Enabled   *bool   `json:"enabled,omitempty"`
+	Voice     *string `json:"voice,omitempty" validate:"omitnil,max=100"`
+	Language  *string `json:"language,omitempty" validate:"omitnil,min=2,max=35"`
+	MaxLength *int    `json:"max_length,omitempty" validate:"omitnil,min=10,max=500"`
+}
+
+type GetSpeechSettingsRequest struct {
+	UserID string `json:"-" validate:"required,max=100"`
+}
+
+type SpeechVoiceResponse struct {
+	ID       string `json:"id"`
+	Language string `json:"language"`
+	Name     string `json:"name"`
+}
+
+// SubmitSpeechRequest asks for the message of a paid donation to be read
+// aloud before its alert is queued.
+type SubmitSpeechRequest struct {
+	ChannelID  string `json:"-" validate:"required,max=36"`
+	DonationID string `json:"-" validate:"required,max=36"`
+}
+
+type GetSpeechAudioRequest struct {
+	Name string `json:"-" validate:"required,max=100"`
+}
+
+type SpeechAudioResponse struct {
+	Data        []byte
+	ContentType string
+}
@@ -4,6 +4,9 @@ type UserResponse struct {
 	ID        string `json:"id,omitempty"`
 	Name      string `json:"name,omitempty"`
 	Token     string `json:"token,omitempty"`
+	Language  string `json:"language,omitempty"`
+	Role      string `json:"role,omitempty"`
+	Disabled  bool   `json:"disabled,omitempty"`
 	CreatedAt int64  `json:"created_at,omitempty"`
 	UpdatedAt int64  `json:"updated_at,omitempty"`
 }
@@ -16,6 +19,7 @@ type RegisterUserRequest struct {
 	ID       string `json:"id" validate:"required,max=100"`
 	Password string `json:"password" validate:"required,max=100"`
 	Name     string `json:"name" validate:"required,max=100"`
+	Language string `json:"language,omitempty" validate:"omitempty,oneof=id en"`
 }
 
 
@@ -23,6 +27,7 @@ type UpdateUserRequest struct {
 	ID       string `json:"-" validate:"required,max=100"`
 	Password string `json:"password,omitempty" validate:"max=100"`
 	Name     string `json:"name,omitempty" validate:"max=100"`
+	Language string `json:"language,omitempty" validate:"omitempty,oneof=id en"`
 }
 
 type LoginUserRequest struct {
@@ -36,4 +41,23 @@ type LogoutUserRequest struct {
 
 type GetUserRequest struct {
 	ID string `json:"id" validate:"required,max=100"`
-}
\ No newline at end of file
+}
+
+type DisableUserRequest struct {
+	ID string `json:"id" validate:"required,max=100"`
+}
+
+type ResetPasswordRequest struct {
+	ID       string `json:"id" validate:"required,max=100"`
+	Password string `json:"password" validate:"required,max=100"`
+}
+
+type SetUserRoleRequest struct {
+	ID   string `json:"id" validate:"required,max=100"`
+	Role string `json:"role" validate:"required,oneof=user admin"`
+}
+
+type RevokeTokenRequest struct {
+	ID    string `json:"id" validate:"required_without=Token,max=100"`
+	Token string `json:"token" validate:"required_without=ID"`
+}
@@ -0,0 +1,258 @@
+package moderation
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxRuleLength bounds the regular expressions channels may add.
+const MaxRuleLength = 100
+
+var ErrInvalidRule = errors.New("moderation: invalid rule")
+
+// leet maps the characters people swap in to dodge filters to the letter
+// they stand for.
+var leet = map[rune]rune{
+	'0': 'o',
+	'1': 'i',
+	'3': 'e',
+	'4': 'a',
+	'5': 's',
+	'6': 'g',
+	'7': 't',
+	'8': 'b',
+	'9': 'g',
+	'@': 'a',
+	'$': 's',
+	'+': 't',
+}
+
+// suffixes are stripped from a word before it is looked up, so "bangsatnya"
+// and "fucking" are caught by "bangsat" and "fuck". The plural "s" is only
+// stripped when at least minStem letters are left, "asus" is not "asu".
+var suffixes = []string{"nya", "lah", "mu", "ku", "ing", "er", "ed", "s"}
+
+const minStem = 4
+
+// Result is what Check found in a text. Text is the input with every match
+// masked, Matches the matched parts as they were written.
+type Result struct {
+	Text    string
+	Matches []string
+}
+
+// Blocked reports whether anything matched.
+func (r *Result) Blocked() bool {
+	return len(r.Matches) > 0
+}
+
+// Filter finds blocked words and rule matches in a text. Words match whole
+// words only, after normalising case, leetspeak, separators like "a.n.j.i.n.g"
+// or "a n j i n g" and stretched letters like "anjiiing", so "Scunthorpe"
+// problems stay rare. Rules are case-insensitive regular expressions matched
+// anywhere.
+type Filter struct {
+	words map[string][][]int
+	rules []*regexp.Regexp
+}
+
+// NewFilter builds a filter for words and rules, see CompileRule.
+func NewFilter(words []string, rules []*regexp.Regexp) *Filter {
+	filter := &Filter{
+		words: make(map[string][][]int, len(words)),
+		rules: rules,
+	}
+	for _, word := range words {
+		letters, counts := runs(normalize(word))
+		if letters == "" {
+			continue
+		}
+		filter.words[letters] = append(filter.words[letters], counts)
+	}
+	return filter
+}
+
+// CompileRule compiles a rule a channel added. Go regular expressions run in
+// linear time, so a rule cannot stall the server whatever the input, but
+// rules matching the empty text are refused as they would mask nothing and
+// match everything.
+func CompileRule(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" || len(pattern) > MaxRuleLength {
+		return nil, ErrInvalidRule
+	}
+	rule, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return nil, errors.Join(ErrInvalidRule, err)
+	}
+	if rule.MatchString("") {
+		return nil, ErrInvalidRule
+	}
+	return rule, nil
+}
+
+// Check looks for blocked words and rule matches in text.
+func (f *Filter) Check(text string) *Result {
+	runes := []rune(text)
+	masked := make([]bool, len(runes))
+	result := &Result{}
+
+	tokens := tokenize(runes)
+	for i := 0; i < len(tokens); i++ {
+		// letters spaced out one by one are read as one word
+		j := i
+		joined := ""
+		for j < len(tokens) && len([]rune(tokens[j].word)) == 1 {
+			joined += tokens[j].word
+			j++
+		}
+		if j-i > 1 && f.matches(joined) {
+			f.mask(result, runes, masked, tokens[i].start, tokens[j-1].end)
+			i = j - 1
+			continue
+		}
+
+		if f.matches(tokens[i].word) {
+			f.mask(result, runes, masked, tokens[i].start, tokens[i].end)
+		}
+	}
+
+	for _, rule := range f.rules {
+		for _, match := range rule.FindAllStringIndex(text, -1) {
+			start := len([]rune(text[:match[0]]))
+			end := start + len([]rune(text[match[0]:match[1]]))
+			f.mask(result, runes, masked, start, end)
+		}
+	}
+
+	for i, hidden := range masked {
+		if hidden && !unicode.IsSpace(runes[i]) {
+			runes[i] = '*'
+		}
+	}
+	result.Text = string(runes)
+	return result
+}
+
+func (f *Filter) mask(result *Result, runes []rune, masked []bool, start int, end int) {
+	result.Matches = append(result.Matches, string(runes[start:end]))
+	for i := start; i < end; i++ {
+		masked[i] = true
+	}
+}
+
+// matches reports whether a normalised word, or the word without a suffix,
+// is blocked. Stretched letters match, "fuuuck" is "fuck", but not shortened
+// ones, "as" is not "ass".
+func (f *Filter) matches(word string) bool {
+	if f.matchesExact(word) {
+		return true
+	}
+	for _, suffix := range suffixes {
+		stem, ok := strings.CutSuffix(word, suffix)
+		if !ok || suffix == "s" && utf8.RuneCountInString(stem) < minStem {
+			continue
+		}
+		if f.matchesExact(stem) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Filter) matchesExact(word string) bool {
+	letters, counts := runs(word)
+	for _, blocked := range f.words[letters] {
+		if atLeast(counts, blocked) {
+			return true
+		}
+	}
+	return false
+}
+
+type token struct {
+	word       string
+	start, end int
+}
+
+// tokenize splits text at whitespace into normalised words, keeping where
+// each word starts and ends in runes. Punctuation around a word is left out
+// of it and is never masked.
+func tokenize(runes []rune) []token {
+	var tokens []token
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(runes) && !unicode.IsSpace(runes[i]) {
+			i++
+		}
+		end := i
+
+		for start < end && !isWordRune(runes[start]) {
+			start++
+		}
+		for end > start && !isWordRune(runes[end-1]) {
+			end--
+		}
+		if word := normalize(string(runes[start:end])); word != "" {
+			tokens = append(tokens, token{word: word, start: start, end: end})
+		}
+	}
+	return tokens
+}
+
+func isWordRune(r rune) bool {
+	_, ok := leet[r]
+	return ok || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// normalize lowercases a word, undoes leetspeak and drops everything that is
+// not a letter.
+func normalize(word string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(word) {
+		if mapped, ok := leet[r]; ok {
+			r = mapped
+		}
+		if unicode.IsLetter(r) {
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+// runs splits a word into its letters with repeats collapsed and how often
+// each letter repeats, "anjiing" is "anjing" and [1 1 1 2 1 1].
+func runs(word string) (string, []int) {
+	var letters []rune
+	var counts []int
+	for _, r := range word {
+		if n := len(letters); n > 0 && letters[n-1] == r {
+			counts[n-1]++
+			continue
+		}
+		letters = append(letters, r)
+		counts = append(counts, 1)
+	}
+	return string(letters), counts
+}
+
+func atLeast(counts []int, minimum []int) bool {
+	for i := range minimum {
+		if counts[i] < minimum[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// IsWord reports whether word can be blocked as a word, it must be a single
+// word that still has letters after normalisation.
+func IsWord(word string) bool {
+	return !strings.ContainsFunc(word, unicode.IsSpace) && normalize(word) != ""
+}
@@ -0,0 +1,42 @@
+package moderation
+
+// DefaultWords is the global list of blocked Indonesian and English words
+// every channel starts with. Variants are covered by normalisation, list the
+// plain base word only.
+var DefaultWords = []string{
+	// Indonesian, including common Javanese and Sundanese slurs
+	"anjing",
+	"asu",
+	"bajingan",
+	"bangsat",
+	"brengsek",
+	"goblok",
+	"jancok",
+	"jancuk",
+	"kampret",
+	"keparat",
+	"kontol",
+	"lonte",
+	"memek",
+	"ngentot",
+	"pelacur",
+	"pepek",
+	"perek",
+	"tolol",
+
+	// English
+	"asshole",
+	"bastard",
+	"bitch",
+	"cunt",
+	"dick",
+	"faggot",
+	"fuck",
+	"motherfucker",
+	"nigger",
+	"pussy",
+	"retard",
+	"shit",
+	"slut",
+	"whore",
+}
@@ -0,0 +1,379 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"streamhelper-backend/internal/tracing"
+	"strings"
+	"time"
+)
+
+const (
+	MidtransSandboxURL    = "https://api.sandbox.midtrans.com"
+	MidtransProductionURL = "https://api.midtrans.com"
+)
+
+// midtransCurrency is the only currency Midtrans charges in, gross_amount is
+// always rupiah.
+const midtransCurrency = "IDR"
+
+// midtransTimeLayout is the format of expiry_time, always in WIB.
+const midtransTimeLayout = "2006-01-02 15:04:05"
+
+var midtransLocation = time.FixedZone("WIB", 7*60*60)
+
+// Midtrans charges through the Midtrans Core API. Callbacks are verified with
+// the signature_key Midtrans computes from the order and the server key.
+type Midtrans struct {
+	ServerKey string
+	BaseURL   string
+	Client    *http.Client
+}
+
+func NewMidtrans(serverKey string, baseURL string, timeout time.Duration) *Midtrans {
+	return &Midtrans{
+		ServerKey: serverKey,
+		BaseURL:   strings.TrimSuffix(baseURL, "/"),
+		Client:    &http.Client{Timeout: timeout},
+	}
+}
+
+func (m *Midtrans) Name() string {
+	return "midtrans"
+}
+
+type midtransChargeRequest struct {
+	PaymentType        string                     `json:"payment_type"`
+	TransactionDetails midtransTransactionDetails `json:"transaction_details"`
+	CustomerDetails    *midtransCustomerDetails   `json:"customer_details,omitempty"`
+	ItemDetails        []midtransItemDetails      `json:"item_details,omitempty"`
+	BankTransfer       *midtransBankTransfer      `json:"bank_transfer,omitempty"`
+	QRIS               *midtransQRIS              `json:"qris,omitempty"`
+	CustomExpiry       *midtransCustomExpiry      `json:"custom_expiry,omitempty"`
+}
+
+type midtransTransactionDetails struct {
+	OrderID     string `json:"order_id"`
+	GrossAmount int64  `json:"gross_amount"`
+}
+
+type midtransCustomerDetails struct {
+	FirstName string `json:"first_name"`
+}
+
+type midtransItemDetails struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Price    int64  `json:"price"`
+	Quantity int    `json:"quantity"`
+}
+
+type midtransBankTransfer struct {
+	Bank string `json:"bank"`
+}
+
+type midtransQRIS struct {
+	Acquirer string `json:"acquirer"`
+}
+
+type midtransCustomExpiry struct {
+	ExpiryDuration int    `json:"expiry_duration"`
+	Unit           string `json:"unit"`
+}
+
+type midtransRefundRequest struct {
+	RefundKey string `json:"refund_key"`
+	Amount    int64  `json:"amount"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// midtransTransaction is the shape of charge, status and refund responses
+// as well as of HTTP notifications.
+type midtransTransaction struct {
+	StatusCode        string             `json:"status_code"`
+	StatusMessage     string             `json:"status_message"`
+	TransactionID     string             `json:"transaction_id"`
+	OrderID           string             `json:"order_id"`
+	GrossAmount       string             `json:"gross_amount"`
+	PaymentType       string             `json:"payment_type"`
+	TransactionStatus string             `json:"transaction_status"`
+	FraudStatus       string             `json:"fraud_status"`
+	SignatureKey      string             `json:"signature_key"`
+	QRString          string             `json:"qr_string"`
+	ExpiryTime        string             `json:"expiry_time"`
+	Actions           []midtransAction   `json:"actions"`
+	VANumbers         []midtransVANumber `json:"va_numbers"`
+}
+
+type midtransAction struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type midtransVANumber struct {
+	Bank     string `json:"bank"`
+	VANumber string `json:"va_number"`
+}
+
+func (m *Midtrans) CreateCharge(ctx context.Context, request *ChargeRequest) (*Charge, error) {
+	ctx, span := tracing.Start(ctx, "Midtrans.CreateCharge")
+	defer span.End()
+
+	if request.Currency != midtransCurrency {
+		return nil, ErrUnsupportedCurrency
+	}
+
+	body := &midtransChargeRequest{
+		PaymentType: request.Method,
+		TransactionDetails: midtransTransactionDetails{
+			OrderID:     request.OrderID,
+			GrossAmount: request.Amount,
+		},
+		ItemDetails: []midtransItemDetails{{
+			ID:       "donation",
+			Name:     truncate(request.Description, 50),
+			Price:    request.Amount,
+			Quantity: 1,
+		}},
+	}
+	if request.CustomerName != "" {
+		body.CustomerDetails = &midtransCustomerDetails{FirstName: truncate(request.CustomerName, 255)}
+	}
+	if request.Expiry > 0 {
+		body.CustomExpiry = &midtransCustomExpiry{
+			ExpiryDuration: int(math.Ceil(request.Expiry.Minutes())),
+			Unit:           "minute",
+		}
+	}
+
+	switch request.Method {
+	case MethodQRIS:
+		body.QRIS = &midtransQRIS{Acquirer: "gopay"}
+	case MethodGoPay, MethodShopeePay:
+	case MethodBankTransfer:
+		switch request.Bank {
+		case BankBCA, BankBNI, BankBRI:
+			body.BankTransfer = &midtransBankTransfer{Bank: request.Bank}
+		default:
+			return nil, ErrUnsupportedMethod
+		}
+	default:
+		return nil, ErrUnsupportedMethod
+	}
+
+	transaction := new(midtransTransaction)
+	if err := m.do(ctx, http.MethodPost, "/v2/charge", body, transaction); err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	charge := &Charge{
+		OrderID:   transaction.OrderID,
+		Reference: transaction.TransactionID,
+		Method:    request.Method,
+		Bank:      request.Bank,
+		Status:    midtransStatus(transaction),
+		QRString:  transaction.QRString,
+	}
+	for _, action := range transaction.Actions {
+		switch action.Name {
+		case "deeplink-redirect":
+			charge.RedirectURL = action.URL
+		case "generate-qr-code":
+			if charge.RedirectURL == "" && request.Method == MethodQRIS {
+				charge.RedirectURL = action.URL
+			}
+		}
+	}
+	for _, vaNumber := range transaction.VANumbers {
+		if vaNumber.Bank == request.Bank {
+			charge.VANumber = vaNumber.VANumber
+		}
+	}
+	if expiresAt, err := time.ParseInLocation(midtransTimeLayout, transaction.ExpiryTime, midtransLocation); err == nil {
+		charge.ExpiresAt = expiresAt.UnixMilli()
+	}
+
+	return charge, nil
+}
+
+// VerifyNotification checks the signature_key of an HTTP notification, which
+// is SHA512(order_id + status_code + gross_amount + server key).
+func (m *Midtrans) VerifyNotification(ctx context.Context, header http.Header, body []byte) (*Notification, error) {
+	_, span := tracing.Start(ctx, "Midtrans.VerifyNotification")
+	defer span.End()
+
+	transaction := new(midtransTransaction)
+	if err := json.Unmarshal(body, transaction); err != nil {
+		return nil, fmt.Errorf("midtrans: decode notification: %w", err)
+	}
+
+	expected := m.Signature(transaction.OrderID, transaction.StatusCode, transaction.GrossAmount)
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(strings.ToLower(transaction.SignatureKey))) != 1 {
+		return nil, ErrInvalidSignature
+	}
+
+	return midtransNotification(transaction)
+}
+
+// Signature computes the signature_key Midtrans puts on notifications.
+func (m *Midtrans) Signature(orderID string, statusCode string, grossAmount string) string {
+	sum := sha512.Sum512([]byte(orderID + statusCode + grossAmount + m.ServerKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func (m *Midtrans) Status(ctx context.Context, orderID string) (*Notification, error) {
+	ctx, span := tracing.Start(ctx, "Midtrans.Status")
+	defer span.End()
+
+	transaction := new(midtransTransaction)
+	if err := m.do(ctx, http.MethodGet, "/v2/"+url.PathEscape(orderID)+"/status", nil, transaction); err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	return midtransNotification(transaction)
+}
+
+func (m *Midtrans) Refund(ctx context.Context, orderID string, amount int64, reason string) (*Notification, error) {
+	ctx, span := tracing.Start(ctx, "Midtrans.Refund")
+	defer span.End()
+
+	body := &midtransRefundRequest{
+		RefundKey: orderID + "-refund",
+		Amount:    amount,
+		Reason:    truncate(reason, 255),
+	}
+
+	transaction := new(midtransTransaction)
+	if err := m.do(ctx, http.MethodPost, "/v2/"+url.PathEscape(orderID)+"/refund", body, transaction); err != nil {
+		tracing.RecordError(span, err)
+		if strings.HasPrefix(transaction.StatusCode, "412") {
+			return nil, ErrNotRefundable
+		}
+		return nil, err
+	}
+	if transaction.OrderID == "" {
+		transaction.OrderID = orderID
+	}
+
+	return midtransNotification(transaction)
+}
+
+// do sends an authenticated request. Midtrans answers most API errors with
+// HTTP 200 and reports them in status_code, so both are checked.
+func (m *Midtrans) do(ctx context.Context, method string, path string, body any, result *midtransTransaction) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, m.BaseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	request.SetBasicAuth(m.ServerKey, "")
+	request.Header.Set("Accept", "application/json")
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := m.Client.Do(request)
+	if err != nil {
+		return fmt.Errorf("midtrans: %s %s: %w", method, path, err)
+	}
+	defer response.Body.Close()
+
+	payload, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("midtrans: read response: %w", err)
+	}
+	if err := json.Unmarshal(payload, result); err != nil {
+		return fmt.Errorf("midtrans: %s %s: unexpected %d response", method, path, response.StatusCode)
+	}
+
+	statusCode := response.StatusCode
+	if code, err := strconv.Atoi(result.StatusCode); err == nil {
+		statusCode = code
+	}
+	switch {
+	case statusCode == http.StatusNotFound:
+		return ErrChargeNotFound
+	case statusCode >= 300:
+		return fmt.Errorf("midtrans: %s %s: %d %s", method, path, statusCode, result.StatusMessage)
+	}
+
+	return nil
+}
+
+func midtransNotification(transaction *midtransTransaction) (*Notification, error) {
+	amount, err := parseGrossAmount(transaction.GrossAmount)
+	if err != nil {
+		return nil, fmt.Errorf("midtrans: invalid gross_amount %q: %w", transaction.GrossAmount, err)
+	}
+
+	return &Notification{
+		OrderID:   transaction.OrderID,
+		Reference: transaction.TransactionID,
+		Status:    midtransStatus(transaction),
+		Amount:    amount,
+	}, nil
+}
+
+// midtransStatus maps transaction_status, see
+// https://docs.midtrans.com/docs/https-notification-webhooks
+func midtransStatus(transaction *midtransTransaction) string {
+	switch transaction.TransactionStatus {
+	case "settlement":
+		return StatusPaid
+	case "capture":
+		switch transaction.FraudStatus {
+		case "", "accept":
+			return StatusPaid
+		case "challenge":
+			return StatusPending
+		default:
+			return StatusFailed
+		}
+	case "deny", "cancel", "failure":
+		return StatusFailed
+	case "expire":
+		return StatusExpired
+	case "refund", "partial_refund":
+		return StatusRefunded
+	default:
+		return StatusPending
+	}
+}
+
+// parseGrossAmount reads amounts like "25000.00". Rupiah has no minor unit,
+// so the fraction is dropped.
+func parseGrossAmount(grossAmount string) (int64, error) {
+	if grossAmount == "" {
+		return 0, nil
+	}
+	whole, _, _ := strings.Cut(grossAmount, ".")
+	return strconv.ParseInt(whole, 10, 64)
+}
+
+func truncate(value string, length int) string {
+	runes := []rune(value)
+	if len(runes) <= length {
+		return value
+	}
+	return string(runes[:length])
+}
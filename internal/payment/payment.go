@@ -0,0 +1,83 @@
+package payment
+
+import (
+	"errors"
+	"time"
+)
+
+// Methods a donor can pay with. Bank transfers are paid into a virtual
+// account (VA) of the chosen bank.
+const (
+	MethodQRIS         = "qris"
+	MethodGoPay        = "gopay"
+	MethodShopeePay    = "shopeepay"
+	MethodBankTransfer = "bank_transfer"
+)
+
+const (
+	BankBCA = "bca"
+	BankBNI = "bni"
+	BankBRI = "bri"
+)
+
+// Statuses are the provider independent outcome of a charge. They match the
+// donation statuses so a notification can be applied as is.
+const (
+	StatusPending  = "pending"
+	StatusPaid     = "paid"
+	StatusFailed   = "failed"
+	StatusExpired  = "expired"
+	StatusRefunded = "refunded"
+)
+
+var (
+	// ErrInvalidSignature is returned for callbacks that were not signed by
+	// the provider and must be rejected.
+	ErrInvalidSignature = errors.New("payment: invalid callback signature")
+	// ErrUnsupportedMethod is returned when the provider cannot charge with
+	// the requested method or bank.
+	ErrUnsupportedMethod = errors.New("payment: unsupported payment method")
+	// ErrUnsupportedCurrency is returned when the provider cannot charge in
+	// the requested currency.
+	ErrUnsupportedCurrency = errors.New("payment: unsupported currency")
+	// ErrChargeNotFound is returned when the provider does not know the order.
+	ErrChargeNotFound = errors.New("payment: charge not found")
+	// ErrNotRefundable is returned when the charge is not paid.
+	ErrNotRefundable = errors.New("payment: charge is not refundable")
+)
+
+// ChargeRequest asks the provider to collect Amount for OrderID. OrderID is
+// our donation ID, providers use it to identify the charge in callbacks.
+type ChargeRequest struct {
+	OrderID      string
+	Amount       int64
+	Currency     string
+	Method       string
+	Bank         string
+	CustomerName string
+	Description  string
+	Expiry       time.Duration
+}
+
+// Charge tells the donor how to pay. Depending on the method it carries a
+// QR string, a redirect (deeplink) URL or a virtual account number.
+type Charge struct {
+	OrderID     string
+	Reference   string
+	Method      string
+	Bank        string
+	Status      string
+	QRString    string
+	RedirectURL string
+	VANumber    string
+	ExpiresAt   int64
+}
+
+// Notification is the verified state of a charge, either pushed by the
+// provider's callback or pulled with Status.
+type Notification struct {
+	OrderID   string
+	Reference string
+	Status    string
+	Amount    int64
+}
@@ -0,0 +1,236 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"streamhelper-backend/internal/tracing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// SimulatorSignatureHeader carries the hex HMAC-SHA256 of the notification
+// body, keyed with the simulator secret.
+const SimulatorSignatureHeader = "X-Simulator-Signature"
+
+// simulatorTTL bounds how long simulated charges are remembered.
+const simulatorTTL = 7 * 24 * time.Hour
+
+// Simulator is a provider for development and tests. Charges are kept in
+// Redis so the server and "streamhelp payment simulate", which fires the
+// callbacks, see the same state. Nothing leaves the machine.
+type Simulator struct {
+	Secret string
+	Redis  *redis.Client
+}
+
+func NewSimulator(secret string, redisClient *redis.Client) *Simulator {
+	return &Simulator{
+		Secret: secret,
+		Redis:  redisClient,
+	}
+}
+
+func (s *Simulator) Name() string {
+	return "simulator"
+}
+
+// SimulatorNotification is the callback body the simulator sends.
+type SimulatorNotification struct {
+	OrderID   string `json:"order_id"`
+	Reference string `json:"reference"`
+	Status    string `json:"status"`
+	Amount    int64  `json:"amount"`
+}
+
+func (s *Simulator) CreateCharge(ctx context.Context, request *ChargeRequest) (*Charge, error) {
+	ctx, span := tracing.Start(ctx, "Simulator.CreateCharge")
+	defer span.End()
+
+	reference := uuid.NewString()
+	charge := &Charge{
+		OrderID:   request.OrderID,
+		Reference: reference,
+		Method:    request.Method,
+		Bank:      request.Bank,
+		Status:    StatusPending,
+	}
+	if request.Expiry > 0 {
+		charge.ExpiresAt = time.Now().Add(request.Expiry).UnixMilli()
+	}
+
+	switch request.Method {
+	case MethodQRIS:
+		charge.QRString = "00020101021226SIMULATOR5204" + request.OrderID
+	case MethodGoPay, MethodShopeePay:
+		charge.RedirectURL = "simulator://" + request.Method + "/pay/" + request.OrderID
+	case MethodBankTransfer:
+		switch request.Bank {
+		case BankBCA, BankBNI, BankBRI:
+			charge.VANumber = simulatorVANumber(request.OrderID)
+		default:
+			return nil, ErrUnsupportedMethod
+		}
+	default:
+		return nil, ErrUnsupportedMethod
+	}
+
+	key := simulatorKey(request.OrderID)
+	pipe := s.Redis.TxPipeline()
+	pipe.HSet(ctx, key,
+		"reference", reference,
+		"status", StatusPending,
+		"amount", request.Amount,
+		"expires_at", charge.ExpiresAt,
+	)
+	pipe.Expire(ctx, key, simulatorTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	return charge, nil
+}
+
+func (s *Simulator) VerifyNotification(ctx context.Context, header http.Header, body []byte) (*Notification, error) {
+	_, span := tracing.Start(ctx, "Simulator.VerifyNotification")
+	defer span.End()
+
+	signature, err := hex.DecodeString(header.Get(SimulatorSignatureHeader))
+	if err != nil || !hmac.Equal(signature, s.sign(body)) {
+		return nil, ErrInvalidSignature
+	}
+
+	notification := new(SimulatorNotification)
+	if err := json.Unmarshal(body, notification); err != nil {
+		return nil, fmt.Errorf("simulator: decode notification: %w", err)
+	}
+
+	return &Notification{
+		OrderID:   notification.OrderID,
+		Reference: notification.Reference,
+		Status:    notification.Status,
+		Amount:    notification.Amount,
+	}, nil
+}
+
+// Status reports the simulated state. A pending charge past its expiry is
+// reported expired, like a real provider would.
+func (s *Simulator) Status(ctx context.Context, orderID string) (*Notification, error) {
+	ctx, span := tracing.Start(ctx, "Simulator.Status")
+	defer span.End()
+
+	notification, expiresAt, err := s.load(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if notification.Status == StatusPending && expiresAt != 0 && time.Now().UnixMilli() > expiresAt {
+		notification.Status = StatusExpired
+	}
+
+	return notification, nil
+}
+
+func (s *Simulator) Refund(ctx context.Context, orderID string, amount int64, reason string) (*Notification, error) {
+	ctx, span := tracing.Start(ctx, "Simulator.Refund")
+	defer span.End()
+
+	notification, _, err := s.load(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if notification.Status != StatusPaid {
+		return nil, ErrNotRefundable
+	}
+
+	if err := s.Redis.HSet(ctx, simulatorKey(orderID), "status", StatusRefunded).Err(); err != nil {
+		return nil, err
+	}
+	notification.Status = StatusRefunded
+	notification.Amount = amount
+
+	return notification, nil
+}
+
+// Notify moves a simulated charge to status and returns the signed callback
+// a real provider would send for it.
+func (s *Simulator) Notify(ctx context.Context, orderID string, status string) (body []byte, signature string, err error) {
+	switch status {
+	case StatusPaid, StatusFailed, StatusExpired, StatusRefunded, StatusPending:
+	default:
+		return nil, "", fmt.Errorf("simulator: unknown status %q", status)
+	}
+
+	notification, _, err := s.load(ctx, orderID)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.Redis.HSet(ctx, simulatorKey(orderID), "status", status).Err(); err != nil {
+		return nil, "", err
+	}
+
+	body, err = json.Marshal(&SimulatorNotification{
+		OrderID:   orderID,
+		Reference: notification.Reference,
+		Status:    status,
+		Amount:    notification.Amount,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return body, s.Signature(body), nil
+}
+
+// Signature returns the value of SimulatorSignatureHeader for body.
+func (s *Simulator) Signature(body []byte) string {
+	return hex.EncodeToString(s.sign(body))
+}
+
+func (s *Simulator) sign(body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(s.Secret))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+func (s *Simulator) load(ctx context.Context, orderID string) (*Notification, int64, error) {
+	values, err := s.Redis.HGetAll(ctx, simulatorKey(orderID)).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, 0, err
+	}
+	if len(values) == 0 {
+		return nil, 0, ErrChargeNotFound
+	}
+
+	amount, _ := strconv.ParseInt(values["amount"], 10, 64)
+	expiresAt, _ := strconv.ParseInt(values["expires_at"], 10, 64)
+
+	return &Notification{
+		OrderID:   orderID,
+		Reference: values["reference"],
+		Status:    values["status"],
+		Amount:    amount,
+	}, expiresAt, nil
+}
+
+func simulatorKey(orderID string) string {
+	return "payment:simulator:" + orderID
+}
+
+// simulatorVANumber derives a stable 16 digit account number from the order.
+func simulatorVANumber(orderID string) string {
+	sum := sha256.Sum256([]byte(orderID))
+	digits := make([]byte, 16)
+	for i := range digits {
+		digits[i] = '0' + sum[i]%10
+	}
+	return string(digits)
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"streamhelper-backend/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type AlertTemplateRepository struct {
+	Repository[entity.AlertTemplate]
+	Log *logrus.Logger
+}
+
+func NewAlertTemplateRepository(log *logrus.Logger) *AlertTemplateRepository {
+	return &AlertTemplateRepository{
+		Log: log,
+	}
+}
+
+// FindAllByChannelId lists the templates of a channel by event type, lowest
+// tier first.
+func (r *AlertTemplateRepository) FindAllByChannelId(db *gorm.DB, channelId string) ([]entity.AlertTemplate, error) {
+	var templates []entity.AlertTemplate
+	err := db.Where("channel_id = ?", channelId).
+		Order("event_type").Order("min_amount").
+		Find(&templates).Error
+	return templates, err
+}
+
+func (r *AlertTemplateRepository) FindByIdAndChannelId(db *gorm.DB, template *entity.AlertTemplate, id string, channelId string) error {
+	return db.Where("id = ? AND channel_id = ?", id, channelId).Take(template).Error
+}
+
+// FindForEvent finds the template of the highest tier an event of amount
+// reaches.
+func (r *AlertTemplateRepository) FindForEvent(db *gorm.DB, template *entity.AlertTemplate, channelId string, eventType string, amount int64) error {
+	return db.Where("channel_id = ? AND event_type = ? AND min_amount <= ?", channelId, eventType, amount).
+		Order("min_amount DESC").
+		Take(template).Error
+}
+
+// CountByTier counts the templates other than excludeId that use the tier.
+func (r *AlertTemplateRepository) CountByTier(db *gorm.DB, channelId string, eventType string, minAmount int64, excludeId string) (int64, error) {
+	var total int64
+	err := db.Model(new(entity.AlertTemplate)).
+		Where("channel_id = ? AND event_type = ? AND min_amount = ? AND id <> ?", channelId, eventType, minAmount, excludeId).
+		Count(&total).Error
+	return total, err
+}
+
+func (r *AlertTemplateRepository) CountByChannelId(db *gorm.DB, channelId string) (int64, error) {
+	var total int64
+	err := db.Model(new(entity.AlertTemplate)).Where("channel_id = ?", channelId).Count(&total).Error
+	return total, err
+}
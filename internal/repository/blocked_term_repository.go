@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"streamhelper-backend/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type BlockedTermRepository struct {
+	Repository[entity.BlockedTerm]
+	Log *logrus.Logger
+}
+
+func NewBlockedTermRepository(log *logrus.Logger) *BlockedTermRepository {
+	return &BlockedTermRepository{
+		Log: log,
+	}
+}
+
+// FindAllByChannelId lists the terms of a channel, oldest first.
+func (r *BlockedTermRepository) FindAllByChannelId(db *gorm.DB, channelId string) ([]entity.BlockedTerm, error) {
+	var terms []entity.BlockedTerm
+	err := db.Where("channel_id = ?", channelId).Order("created_at").Order("id").Find(&terms).Error
+	return terms, err
+}
+
+func (r *BlockedTermRepository) FindByIdAndChannelId(db *gorm.DB, term *entity.BlockedTerm, id string, channelId string) error {
+	return db.Where("id = ? AND channel_id = ?", id, channelId).Take(term).Error
+}
+
+func (r *BlockedTermRepository) CountByPattern(db *gorm.DB, channelId string, kind string, pattern string) (int64, error) {
+	var total int64
+	err := db.Model(new(entity.BlockedTerm)).
+		Where("channel_id = ? AND kind = ? AND pattern = ?", channelId, kind, pattern).
+		Count(&total).Error
+	return total, err
+}
+
+func (r *BlockedTermRepository) CountByChannelId(db *gorm.DB, channelId string) (int64, error) {
+	var total int64
+	err := db.Model(new(entity.BlockedTerm)).Where("channel_id = ?", channelId).Count(&total).Error
+	return total, err
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"streamhelper-backend/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type ChannelRepository struct {
+	Repository[entity.Channel]
+	Log *logrus.Logger
+}
+
+func NewChannelRepository(log *logrus.Logger) *ChannelRepository {
+	return &ChannelRepository{
+		Log: log,
+	}
+}
+
+func (r *ChannelRepository) FindBySlug(db *gorm.DB, channel *entity.Channel, slug string) error {
+	return db.Where("slug = ?", slug).Take(channel).Error
+}
+
+func (r *ChannelRepository) FindByUserId(db *gorm.DB, channel *entity.Channel, userId string) error {
+	return db.Where("user_id = ?", userId).Take(channel).Error
+}
+
+func (r *ChannelRepository) FindByOverlayKey(db *gorm.DB, channel *entity.Channel, key string) error {
+	return db.Where("overlay_key = ?", key).Take(channel).Error
+}
+
+func (r *ChannelRepository) CountBySlug(db *gorm.DB, slug string) (int64, error) {
+	var total int64
+	err := db.Model(new(entity.Channel)).Where("slug = ?", slug).Count(&total).Error
+	return total, err
+}
+
+func (r *ChannelRepository) CountByUserId(db *gorm.DB, userId string) (int64, error) {
+	var total int64
+	err := db.Model(new(entity.Channel)).Where("user_id = ?", userId).Count(&total).Error
+	return total, err
+}
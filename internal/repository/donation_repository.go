@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DonationRepository struct {
+	Repository[entity.Donation]
+	Log *logrus.Logger
+}
+
+func NewDonationRepository(log *logrus.Logger) *DonationRepository {
+	return &DonationRepository{
+		Log: log,
+	}
+}
+
+// UpdateStatus saves the status and paid time of a donation only while it is
+// still in status from, and reports whether it was. Of a callback and a poll
+// racing to apply the same payment, only one changes it.
+func (r *DonationRepository) UpdateStatus(db *gorm.DB, donation *entity.Donation, from string) (bool, error) {
+	result := db.Model(donation).Where("status = ?", from).Updates(map[string]any{
+		"status":  donation.Status,
+		"paid_at": donation.PaidAt,
+	})
+	return result.RowsAffected == 1, result.Error
+}
+
+// FindByIdForUpdate finds a donation and keeps it locked until the
+// transaction ends.
+func (r *DonationRepository) FindByIdForUpdate(db *gorm.DB, donation *entity.Donation, id string) error {
+	return db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).Take(donation).Error
+}
+
+// Search returns one page of the donations of a channel, newest first, and
+// the total number of donations matching the filter.
+func (r *DonationRepository) Search(db *gorm.DB, channelId string, request *model.SearchDonationRequest) ([]entity.Donation, int64, error) {
+	var donations []entity.Donation
+	if err := db.Scopes(r.FilterDonation(channelId, request)).
+		Order("created_at DESC").Order("id").
+		Offset((request.Page - 1) * request.Size).Limit(request.Size).
+		Find(&donations).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	if err := db.Model(new(entity.Donation)).Scopes(r.FilterDonation(channelId, request)).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return donations, total, nil
+}
+
+func (r *DonationRepository) FilterDonation(channelId string, request *model.SearchDonationRequest) func(tx *gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		tx = tx.Where("channel_id = ?", channelId)
+		if request.Status != "" {
+			tx = tx.Where("status = ?", request.Status)
+		}
+		return tx
+	}
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// pgUniqueViolation is the SQLSTATE Postgres raises for a duplicate key.
+const pgUniqueViolation = "23505"
+
+// UniqueViolation returns the unique index err violated, or "" when err is
+// not a unique violation. Postgres names the index, SQLite only the column,
+// which is turned into the idx_<table>_<column> name the migrations use.
+func UniqueViolation(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		if pgErr.Code == pgUniqueViolation {
+			return pgErr.ConstraintName
+		}
+		return ""
+	}
+
+	// SQLite: "UNIQUE constraint failed: channels.slug (2067)"
+	_, column, ok := strings.Cut(err.Error(), "UNIQUE constraint failed: ")
+	if !ok {
+		return ""
+	}
+	column, _, _ = strings.Cut(column, " ")
+	return "idx_" + strings.ReplaceAll(column, ".", "_")
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type FilteredMessageRepository struct {
+	Repository[entity.FilteredMessage]
+	Log *logrus.Logger
+}
+
+func NewFilteredMessageRepository(log *logrus.Logger) *FilteredMessageRepository {
+	return &FilteredMessageRepository{
+		Log: log,
+	}
+}
+
+// Search returns one page of the filtered messages of a channel, newest
+// first, and the total number of messages matching the filter.
+func (r *FilteredMessageRepository) Search(db *gorm.DB, channelId string, request *model.SearchFilteredMessageRequest) ([]entity.FilteredMessage, int64, error) {
+	var messages []entity.FilteredMessage
+	if err := db.Scopes(r.FilterMessage(channelId, request)).
+		Order("created_at DESC").Order("id").
+		Offset((request.Page - 1) * request.Size).Limit(request.Size).
+		Find(&messages).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	if err := db.Model(new(entity.FilteredMessage)).Scopes(r.FilterMessage(channelId, request)).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return messages, total, nil
+}
+
+func (r *FilteredMessageRepository) FilterMessage(channelId string, request *model.SearchFilteredMessageRequest) func(tx *gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		tx = tx.Where("channel_id = ?", channelId)
+		if request.Action != "" {
+			tx = tx.Where("action = ?", request.Action)
+		}
+		return tx
+	}
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GoalRepository struct {
+	Repository[entity.Goal]
+	Log *logrus.Logger
+}
+
+func NewGoalRepository(log *logrus.Logger) *GoalRepository {
+	return &GoalRepository{
+		Log: log,
+	}
+}
+
+func (r *GoalRepository) FindByIdAndChannelId(db *gorm.DB, goal *entity.Goal, id string, channelId string) error {
+	return db.Where("id = ? AND channel_id = ?", id, channelId).Take(goal).Error
+}
+
+// FindAllOpenByChannelId lists the goals of a channel that have not ended at
+// now, the one starting first first.
+func (r *GoalRepository) FindAllOpenByChannelId(db *gorm.DB, channelId string, now int64) ([]entity.Goal, error) {
+	var goals []entity.Goal
+	err := db.Scopes(r.FilterOpen(channelId, now)).
+		Order("starts_at").Order("created_at").
+		Find(&goals).Error
+	return goals, err
+}
+
+// FindAllByPaidAt lists the goals of a channel a donation paid at paidAt
+// may count toward. The goals stay locked, in id order, until the
+// transaction ends, so two donations paid at once sum their progress one
+// after the other.
+func (r *GoalRepository) FindAllByPaidAt(db *gorm.DB, channelId string, paidAt int64) ([]entity.Goal, error) {
+	var goals []entity.Goal
+	err := db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("channel_id = ? AND starts_at <= ? AND (ends_at = 0 OR ends_at > ?)", channelId, paidAt, paidAt).
+		Order("id").
+		Find(&goals).Error
+	return goals, err
+}
+
+// UpdateProgress writes the amount of the goal and, when it is set, its
+// completion. A goal is only completed once, UpdateProgress reports whether
+// it was this time. Everything else a concurrent change made is kept.
+func (r *GoalRepository) UpdateProgress(db *gorm.DB, goal *entity.Goal) (bool, error) {
+	if err := db.Model(goal).Updates(map[string]any{"amount": goal.Amount}).Error; err != nil {
+		return false, err
+	}
+	if goal.CompletedAt == 0 {
+		return false, nil
+	}
+
+	result := db.Model(goal).Where("completed_at = ?", 0).Updates(map[string]any{"completed_at": goal.CompletedAt})
+	return result.RowsAffected == 1, result.Error
+}
+
+// SearchHistory returns one page of the goals of a channel that ended before
+// now, the latest first, and the total number of ended goals.
+func (r *GoalRepository) SearchHistory(db *gorm.DB, channelId string, request *model.SearchGoalHistoryRequest, now int64) ([]entity.Goal, int64, error) {
+	var goals []entity.Goal
+	if err := db.Scopes(r.FilterEnded(channelId, now)).
+		Order("ends_at DESC").Order("id").
+		Offset((request.Page - 1) * request.Size).Limit(request.Size).
+		Find(&goals).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	if err := db.Model(new(entity.Goal)).Scopes(r.FilterEnded(channelId, now)).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return goals, total, nil
+}
+
+func (r *GoalRepository) CountOpenByChannelId(db *gorm.DB, channelId string, now int64) (int64, error) {
+	var total int64
+	err := db.Model(new(entity.Goal)).Scopes(r.FilterOpen(channelId, now)).Count(&total).Error
+	return total, err
+}
+
+// SumProgress adds up the paid donations counting toward the goal.
+// Donations without a payment, like imported ones, only count when the goal
+// accepts every source.
+func (r *GoalRepository) SumProgress(db *gorm.DB, goal *entity.Goal) (int64, error) {
+	query := db.Model(new(entity.Donation)).
+		Where("donations.channel_id = ? AND donations.status = ? AND donations.currency = ? AND donations.paid_at >= ?",
+			goal.ChannelID, entity.DonationStatusPaid, goal.Currency, goal.StartsAt)
+	if goal.EndsAt != 0 {
+		query = query.Where("donations.paid_at < ?", goal.EndsAt)
+	}
+	if len(goal.Sources) > 0 {
+		query = query.Joins("JOIN payments ON payments.donation_id = donations.id").
+			Where("payments.method IN ?", goal.Sources)
+	}
+
+	var total int64
+	err := query.Select("COALESCE(SUM(donations.amount), 0)").Scan(&total).Error
+	return total, err
+}
+
+func (r *GoalRepository) FilterOpen(channelId string, now int64) func(tx *gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("channel_id = ? AND (ends_at = 0 OR ends_at > ?)", channelId, now)
+	}
+}
+
+func (r *GoalRepository) FilterEnded(channelId string, now int64) func(tx *gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("channel_id = ? AND ends_at <> 0 AND ends_at <= ?", channelId, now)
+	}
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/leaderboard"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// LeaderboardRepository sums donations the way the leaderboards rank them,
+// to rebuild a board from the database.
+type LeaderboardRepository struct {
+	Log *logrus.Logger
+}
+
+func NewLeaderboardRepository(log *logrus.Logger) *LeaderboardRepository {
+	return &LeaderboardRepository{
+		Log: log,
+	}
+}
+
+// SumByDonor returns the total of each donor over the paid donations of a
+// channel in currency, paid from `from` until `to`, where a zero `to` leaves
+// the end open. Anonymous donations are never ranked.
+func (r *LeaderboardRepository) SumByDonor(db *gorm.DB, channelId string, currency string, from int64, to int64) ([]leaderboard.Entry, error) {
+	var entries []leaderboard.Entry
+	err := r.ranked(db, channelId, currency, from, to).
+		Select("LOWER(TRIM(donor_name)) AS donor, MAX(donor_name) AS name, SUM(amount) AS amount").
+		Group("LOWER(TRIM(donor_name))").
+		Scan(&entries).Error
+	return entries, err
+}
+
+// FindRankedIds returns the ids of the donations SumByDonor sums.
+func (r *LeaderboardRepository) FindRankedIds(db *gorm.DB, channelId string, currency string, from int64, to int64) ([]string, error) {
+	var ids []string
+	err := r.ranked(db, channelId, currency, from, to).Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r *LeaderboardRepository) ranked(db *gorm.DB, channelId string, currency string, from int64, to int64) *gorm.DB {
+	query := db.Model(new(entity.Donation)).
+		Where("channel_id = ? AND status = ? AND currency = ? AND anonymous = ? AND paid_at >= ?",
+			channelId, entity.DonationStatusPaid, currency, false, from)
+	if to != 0 {
+		query = query.Where("paid_at < ?", to)
+	}
+	return query
+}
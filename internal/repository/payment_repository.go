@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"streamhelper-backend/internal/entity"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type PaymentRepository struct {
+	Repository[entity.Payment]
+	Log *logrus.Logger
+}
+
+func NewPaymentRepository(log *logrus.Logger) *PaymentRepository {
+	return &PaymentRepository{
+		Log: log,
+	}
+}
+
+func (r *PaymentRepository) FindByDonationId(db *gorm.DB, payment *entity.Payment, donationId string) error {
+	return db.Where("donation_id = ?", donationId).Take(payment).Error
+}
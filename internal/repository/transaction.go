@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// TransactionManager opens units of work. Use cases depend on it instead of
+// *gorm.DB so they can run against fakes without a database.
+type TransactionManager interface {
+	Begin(ctx context.Context) Transaction
+}
+
+// Transaction is a unit of work. DB is the handle repositories are given;
+// Rollback after Commit is a no-op, so it can always be deferred.
+type Transaction interface {
+	DB() *gorm.DB
+	Commit() error
+	Rollback() error
+}
+
+type GormTransactionManager struct {
+	DB *gorm.DB
+}
+
+func NewTransactionManager(db *gorm.DB) *GormTransactionManager {
+	return &GormTransactionManager{
+		DB: db,
+	}
+}
+
+func (m *GormTransactionManager) Begin(ctx context.Context) Transaction {
+	return &gormTransaction{tx: m.DB.WithContext(ctx).Begin()}
+}
+
+type gormTransaction struct {
+	tx        *gorm.DB
+	committed bool
+}
+
+func (t *gormTransaction) DB() *gorm.DB {
+	return t.tx
+}
+
+func (t *gormTransaction) Commit() error {
+	if err := t.tx.Commit().Error; err != nil {
+		return err
+	}
+	t.committed = true
+	return nil
+}
+
+func (t *gormTransaction) Rollback() error {
+	if t.committed {
+		return nil
+	}
+	return t.tx.Rollback().Error
+}
@@ -0,0 +1,123 @@
+package speech
+
+import (
+	"context"
+	"encoding/json"
+	"streamhelper-backend/internal/tracing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Job asks for the message of a paid donation to be read aloud. Attempts is
+// how often it failed before.
+type Job struct {
+	ChannelID  string `json:"channel_id"`
+	DonationID string `json:"donation_id"`
+	Attempts   int    `json:"attempts"`
+
+	// payload is the job as stored, to find it again in the processing set
+	payload string
+}
+
+// Queue holds the speech jobs of every instance in Redis. A reserved job is
+// invisible to other workers until it is done, retried or its timeout passes,
+// so a job whose worker died is picked up again by another one.
+type Queue struct {
+	Redis *redis.Client
+	// Timeout is how long a reserved job may take before it is handed out
+	// again.
+	Timeout time.Duration
+}
+
+func NewQueue(redisClient *redis.Client, timeout time.Duration) *Queue {
+	return &Queue{
+		Redis:   redisClient,
+		Timeout: timeout,
+	}
+}
+
+// reserveScript returns timed out jobs to the queue, then moves up to
+// ARGV[3] jobs into the processing set with a deadline of now + ARGV[2].
+var reserveScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local expired = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', now)
+for _, job in ipairs(expired) do
+	redis.call('ZREM', KEYS[2], job)
+	redis.call('RPUSH', KEYS[1], job)
+end
+local jobs = {}
+for i = 1, tonumber(ARGV[3]) do
+	local job = redis.call('LPOP', KEYS[1])
+	if not job then break end
+	redis.call('ZADD', KEYS[2], now + tonumber(ARGV[2]), job)
+	table.insert(jobs, job)
+end
+return jobs
+`)
+
+// Push adds a job to the end of the queue.
+func (q *Queue) Push(ctx context.Context, job *Job) error {
+	ctx, span := tracing.Start(ctx, "SpeechQueue.Push")
+	defer span.End()
+
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	if err := q.Redis.RPush(ctx, jobsKey, payload).Err(); err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+	return nil
+}
+
+// Reserve takes up to limit jobs from the front of the queue.
+func (q *Queue) Reserve(ctx context.Context, now time.Time, limit int) ([]Job, error) {
+	ctx, span := tracing.Start(ctx, "SpeechQueue.Reserve")
+	defer span.End()
+
+	payloads, err := reserveScript.Run(ctx, q.Redis, []string{jobsKey, processingKey},
+		now.UnixMilli(), q.Timeout.Milliseconds(), limit).StringSlice()
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	jobs := make([]Job, 0, len(payloads))
+	for _, payload := range payloads {
+		job := Job{payload: payload}
+		if err := json.Unmarshal([]byte(payload), &job); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// Done forgets a reserved job.
+func (q *Queue) Done(ctx context.Context, job *Job) error {
+	return q.Redis.ZRem(ctx, processingKey, job.payload).Err()
+}
+
+// Retry puts a reserved job back at the end of the queue with one more
+// attempt counted.
+func (q *Queue) Retry(ctx context.Context, job *Job) error {
+	retried := *job
+	retried.Attempts++
+	payload, err := json.Marshal(&retried)
+	if err != nil {
+		return err
+	}
+
+	pipe := q.Redis.TxPipeline()
+	pipe.ZRem(ctx, processingKey, job.payload)
+	pipe.RPush(ctx, jobsKey, payload)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+const (
+	jobsKey       = "speech:jobs"
+	processingKey = "speech:processing"
+)
@@ -0,0 +1,58 @@
+package speech
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrUnsupportedVoice = errors.New("speech: unsupported voice")
+	ErrNotFound         = errors.New("speech: audio not found")
+)
+
+// Synthesizer turns text into speech, implemented by Stub. Engines calling
+// out to a cloud service implement it the same way and are picked by
+// tts.engine.
+type Synthesizer interface {
+	Name() string
+	// Voices lists the voices the engine can speak with.
+	Voices() []Voice
+	Synthesize(ctx context.Context, request *Request) (*Audio, error)
+}
+
+// Voice is one voice of an engine. Language is a BCP 47 tag like "id-ID".
+type Voice struct {
+	ID       string
+	Language string
+	Name     string
+}
+
+type Request struct {
+	Text     string
+	Voice    string
+	Language string
+}
+
+// Audio is synthesized speech, ready to be stored and played by the overlay.
+type Audio struct {
+	Data        []byte
+	ContentType string
+	// Extension is the file extension without the dot, e.g. "wav".
+	Extension string
+	Duration  time.Duration
+}
+
+// FindVoice returns the voice with the given ID, or the first voice speaking
+// language when id is empty.
+func FindVoice(voices []Voice, id string, language string) (*Voice, error) {
+	for i := range voices {
+		if id != "" && voices[i].ID == id {
+			return &voices[i], nil
+		}
+		if id == "" && voices[i].Language == language {
+			return &voices[i], nil
+		}
+	}
+	return nil, ErrUnsupportedVoice
+}
@@ -0,0 +1,82 @@
+package speech
+
+import (
+	"context"
+	"errors"
+	"mime"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// validName keeps artifact names to plain file names, so a name taken from a
+// URL can never point outside the storage directory.
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*\.[a-z0-9]+$`)
+
+// FileStorage keeps audio as files in Dir, served by the API under BaseURL.
+// Instances behind a load balancer must share Dir, e.g. on a network volume.
+type FileStorage struct {
+	Dir     string
+	BaseURL string
+}
+
+func NewFileStorage(dir string, baseURL string) *FileStorage {
+	return &FileStorage{
+		Dir:     dir,
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// Put stores audio under name and returns the URL the overlay plays it from.
+// Storing a name again replaces the audio.
+func (s *FileStorage) Put(ctx context.Context, name string, audio *Audio) (string, error) {
+	if !validName.MatchString(name) {
+		return "", errors.New("speech: invalid artifact name " + name)
+	}
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return "", err
+	}
+
+	// written aside and renamed, so nobody ever reads half a file
+	file, err := os.CreateTemp(s.Dir, ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(audio.Data); err != nil {
+		_ = file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(file.Name(), filepath.Join(s.Dir, name)); err != nil {
+		return "", err
+	}
+
+	return s.BaseURL + "/" + name, nil
+}
+
+// Get returns the audio stored under name.
+func (s *FileStorage) Get(ctx context.Context, name string) (*Audio, error) {
+	if !validName.MatchString(name) {
+		return nil, ErrNotFound
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.Dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	extension := strings.TrimPrefix(filepath.Ext(name), ".")
+	contentType := mime.TypeByExtension("." + extension)
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return &Audio{Data: data, ContentType: contentType, Extension: extension}, nil
+}
@@ -0,0 +1,86 @@
+package speech
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"time"
+	"unicode/utf8"
+)
+
+// stubSampleRate is low on purpose, the stub's audio is only ever listened
+// to by tests and developers.
+const stubSampleRate = 8000
+
+// stubRuneDuration is roughly how long a person takes to say one character.
+const stubRuneDuration = 60 * time.Millisecond
+
+// Stub is an offline engine for development and tests. It never leaves the
+// machine and produces a WAV file of silence as long as it would take to read
+// the text aloud, so everything around synthesis can be exercised without a
+// speech service.
+type Stub struct{}
+
+func NewStub() *Stub {
+	return &Stub{}
+}
+
+func (s *Stub) Name() string {
+	return "stub"
+}
+
+func (s *Stub) Voices() []Voice {
+	return []Voice{
+		{ID: "id-ID-stub-female", Language: "id-ID", Name: "Stub (Indonesian, female)"},
+		{ID: "id-ID-stub-male", Language: "id-ID", Name: "Stub (Indonesian, male)"},
+		{ID: "en-US-stub-female", Language: "en-US", Name: "Stub (English, female)"},
+	}
+}
+
+func (s *Stub) Synthesize(ctx context.Context, request *Request) (*Audio, error) {
+	voice, err := FindVoice(s.Voices(), request.Voice, request.Language)
+	if err != nil {
+		return nil, err
+	}
+	if request.Language != "" && voice.Language != request.Language {
+		return nil, ErrUnsupportedVoice
+	}
+
+	duration := time.Duration(utf8.RuneCountInString(request.Text)) * stubRuneDuration
+	if duration < time.Second {
+		duration = time.Second
+	}
+
+	return &Audio{
+		Data:        silence(duration),
+		ContentType: "audio/wav",
+		Extension:   "wav",
+		Duration:    duration,
+	}, nil
+}
+
+// silence encodes duration of silence as 8-bit mono PCM in a WAV container.
+func silence(duration time.Duration) []byte {
+	samples := int(duration.Seconds() * stubSampleRate)
+
+	buffer := new(bytes.Buffer)
+	buffer.WriteString("RIFF")
+	_ = binary.Write(buffer, binary.LittleEndian, uint32(36+samples))
+	buffer.WriteString("WAVEfmt ")
+	for _, field := range []any{
+		uint32(16),             // size of the fmt chunk
+		uint16(1),              // PCM
+		uint16(1),              // mono
+		uint32(stubSampleRate), // samples per second
+		uint32(stubSampleRate), // bytes per second
+		uint16(1),              // bytes per sample
+		uint16(8),              // bits per sample
+	} {
+		_ = binary.Write(buffer, binary.LittleEndian, field)
+	}
+	buffer.WriteString("data")
+	_ = binary.Write(buffer, binary.LittleEndian, uint32(samples))
+	// unsigned 8-bit samples are silent at their midpoint
+	buffer.Write(bytes.Repeat([]byte{0x80}, samples))
+	return buffer.Bytes()
+}
@@ -0,0 +1,75 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"gorm.io/gorm"
+)
+
+const gormSpanKey = "tracing:span"
+
+// GormPlugin opens a client span around every GORM statement, parented to the
+// context passed through db.WithContext.
+type GormPlugin struct{}
+
+func NewGormPlugin() *GormPlugin {
+	return &GormPlugin{}
+}
+
+func (p *GormPlugin) Name() string {
+	return "tracing"
+}
+
+func (p *GormPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	registrations := []error{
+		callback.Create().Before("gorm:create").Register("tracing:before_create", p.before("create")),
+		callback.Create().After("gorm:create").Register("tracing:after_create", p.after),
+		callback.Query().Before("gorm:query").Register("tracing:before_query", p.before("query")),
+		callback.Query().After("gorm:query").Register("tracing:after_query", p.after),
+		callback.Update().Before("gorm:update").Register("tracing:before_update", p.before("update")),
+		callback.Update().After("gorm:update").Register("tracing:after_update", p.after),
+		callback.Delete().Before("gorm:delete").Register("tracing:before_delete", p.before("delete")),
+		callback.Delete().After("gorm:delete").Register("tracing:after_delete", p.after),
+		callback.Row().Before("gorm:row").Register("tracing:before_row", p.before("row")),
+		callback.Row().After("gorm:row").Register("tracing:after_row", p.after),
+		callback.Raw().Before("gorm:raw").Register("tracing:before_raw", p.before("raw")),
+		callback.Raw().After("gorm:raw").Register("tracing:after_raw", p.after),
+	}
+
+	return errors.Join(registrations...)
+}
+
+func (p *GormPlugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx, span := Start(db.Statement.Context, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemNamePostgreSQL,
+				semconv.DBOperationName(operation),
+			))
+		db.Statement.Context = ctx
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func (p *GormPlugin) after(db *gorm.DB) {
+	value, ok := db.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+	defer span.End()
+
+	if db.Statement.Table != "" {
+		span.SetAttributes(semconv.DBCollectionName(db.Statement.Table))
+	}
+	// parameterized SQL only, the bound values may contain secrets
+	span.SetAttributes(semconv.DBQueryText(db.Statement.SQL.String()))
+
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		RecordError(span, db.Error)
+	}
+}
@@ -0,0 +1,34 @@
+package tracing
+
+import (
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// LogrusHook copies the trace and span ID of entry.Context into the log
+// fields, so any log written with log.WithContext(ctx) can be joined to its
+// trace.
+type LogrusHook struct{}
+
+func NewLogrusHook() *LogrusHook {
+	return &LogrusHook{}
+}
+
+func (h *LogrusHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *LogrusHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+
+	spanContext := trace.SpanContextFromContext(entry.Context)
+	if !spanContext.IsValid() {
+		return nil
+	}
+
+	entry.Data["trace_id"] = spanContext.TraceID().String()
+	entry.Data["span_id"] = spanContext.SpanID().String()
+	return nil
+}
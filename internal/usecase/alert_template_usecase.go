@@ -0,0 +1,380 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"streamhelper-backend/internal/alert"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/model/converter"
+	"streamhelper-backend/internal/repository"
+	"streamhelper-backend/internal/tracing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// defaultAlertTemplate shows events that none of the channel's templates
+// apply to.
+var defaultAlertTemplate = entity.AlertTemplate{
+	Text:      "{{donor}} donated {{amount}}",
+	Animation: entity.AnimationFade,
+}
+
+// AlertTemplateUseCase manages how a channel's alerts look. Templates are
+// chosen by event type and amount tier when an alert is queued, see
+// AlertUseCase.Enqueue.
+type AlertTemplateUseCase struct {
+	TxManager          repository.TransactionManager
+	Log                *logrus.Logger
+	Validate           *validator.Validate
+	ChannelRepository  ChannelRepository
+	TemplateRepository AlertTemplateRepository
+}
+
+func NewAlertTemplateUseCase(txManager repository.TransactionManager, logger *logrus.Logger, validate *validator.Validate,
+	channelRepository ChannelRepository, templateRepository AlertTemplateRepository) *AlertTemplateUseCase {
+	return &AlertTemplateUseCase{
+		TxManager:          txManager,
+		Log:                logger,
+		Validate:           validate,
+		ChannelRepository:  channelRepository,
+		TemplateRepository: templateRepository,
+	}
+}
+
+func (c *AlertTemplateUseCase) Create(ctx context.Context, request *model.CreateAlertTemplateRequest) (*model.AlertTemplateResponse, error) {
+	ctx, span := tracing.Start(ctx, "AlertTemplateUseCase.Create")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, request.UserID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	total, err := c.TemplateRepository.CountByChannelId(tx.DB(), channel.ID)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed count alert templates : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if total >= entity.MaxAlertTemplates {
+		return nil, model.ErrAlertTemplateLimit
+	}
+
+	template := &entity.AlertTemplate{
+		ID:        uuid.NewString(),
+		ChannelID: channel.ID,
+		Name:      request.Name,
+		EventType: request.EventType,
+		MinAmount: request.MinAmount,
+		Text:      request.Text,
+		ImageURL:  request.ImageURL,
+		SoundURL:  request.SoundURL,
+		Duration:  request.Duration,
+		Animation: request.Animation,
+	}
+	if template.Animation == "" {
+		template.Animation = entity.AnimationFade
+	}
+	if err := c.checkTier(ctx, tx.DB(), template); err != nil {
+		return nil, err
+	}
+
+	if err := c.TemplateRepository.Create(tx.DB(), template); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed create alert template : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.AlertTemplateToResponse(template), nil
+}
+
+// Update changes only the fields that are sent. The event type of a template
+// is fixed, create another template instead.
+func (c *AlertTemplateUseCase) Update(ctx context.Context, request *model.UpdateAlertTemplateRequest) (*model.AlertTemplateResponse, error) {
+	ctx, span := tracing.Start(ctx, "AlertTemplateUseCase.Update")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	template, err := c.find(ctx, tx.DB(), request.UserID, request.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Name != "" {
+		template.Name = request.Name
+	}
+	if request.MinAmount != nil {
+		template.MinAmount = *request.MinAmount
+	}
+	if request.Text != "" {
+		template.Text = request.Text
+	}
+	if request.ImageURL != nil {
+		template.ImageURL = *request.ImageURL
+	}
+	if request.SoundURL != nil {
+		template.SoundURL = *request.SoundURL
+	}
+	if request.Duration != nil {
+		template.Duration = *request.Duration
+	}
+	if request.Animation != "" {
+		template.Animation = request.Animation
+	}
+	if err := c.checkTier(ctx, tx.DB(), template); err != nil {
+		return nil, err
+	}
+
+	if err := c.TemplateRepository.Update(tx.DB(), template); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed save alert template : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.AlertTemplateToResponse(template), nil
+}
+
+// Delete removes a template. Alerts already queued keep how they look.
+func (c *AlertTemplateUseCase) Delete(ctx context.Context, request *model.GetAlertTemplateRequest) (bool, error) {
+	ctx, span := tracing.Start(ctx, "AlertTemplateUseCase.Delete")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return false, model.NewValidationError(err)
+	}
+
+	template, err := c.find(ctx, tx.DB(), request.UserID, request.TemplateID)
+	if err != nil {
+		return false, err
+	}
+
+	if err := c.TemplateRepository.Delate(tx.DB(), template); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed delete alert template : %+v", err)
+		return false, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return false, fiber.ErrInternalServerError
+	}
+
+	return true, nil
+}
+
+func (c *AlertTemplateUseCase) Get(ctx context.Context, request *model.GetAlertTemplateRequest) (*model.AlertTemplateResponse, error) {
+	ctx, span := tracing.Start(ctx, "AlertTemplateUseCase.Get")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	template, err := c.find(ctx, tx.DB(), request.UserID, request.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.AlertTemplateToResponse(template), nil
+}
+
+func (c *AlertTemplateUseCase) List(ctx context.Context, request *model.ListAlertTemplateRequest) ([]model.AlertTemplateResponse, error) {
+	ctx, span := tracing.Start(ctx, "AlertTemplateUseCase.List")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, request.UserID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	templates, err := c.TemplateRepository.FindAllByChannelId(tx.DB(), channel.ID)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find alert templates : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.AlertTemplatesToResponse(templates), nil
+}
+
+// Preview renders the alert an event with the sample values would get,
+// without queueing anything.
+func (c *AlertTemplateUseCase) Preview(ctx context.Context, request *model.PreviewAlertTemplateRequest) (*model.AlertDisplay, error) {
+	ctx, span := tracing.Start(ctx, "AlertTemplateUseCase.Preview")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, request.UserID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	data := &model.AlertTemplateData{
+		Donor:    request.Donor,
+		Amount:   request.Amount,
+		Currency: channel.Currency,
+		Message:  request.Message,
+	}
+
+	template := &entity.AlertTemplate{Text: request.Text, Animation: entity.AnimationFade}
+	if request.Text == "" {
+		var err error
+		template, err = findAlertTemplate(tx.DB(), c.TemplateRepository, channel.ID, request.EventType, data.Amount)
+		if err != nil {
+			c.Log.WithContext(ctx).Warnf("Failed find alert template : %+v", err)
+			return nil, fiber.ErrInternalServerError
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	display, err := renderAlert(template, data)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed render alert template : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.AlertDisplayToResponse(display, alertDuration(channel, template).Milliseconds()), nil
+}
+
+func (c *AlertTemplateUseCase) find(ctx context.Context, db *gorm.DB, userID string, id string) (*entity.AlertTemplate, error) {
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(db, channel, userID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	template := new(entity.AlertTemplate)
+	if err := c.TemplateRepository.FindByIdAndChannelId(db, template, id, channel.ID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find alert template : %+v", err)
+		return nil, model.ErrAlertTemplateNotFound
+	}
+
+	return template, nil
+}
+
+// checkTier keeps the tiers of an event type distinct, otherwise it would be
+// undefined which template an event gets.
+func (c *AlertTemplateUseCase) checkTier(ctx context.Context, db *gorm.DB, template *entity.AlertTemplate) error {
+	total, err := c.TemplateRepository.CountByTier(db, template.ChannelID, template.EventType, template.MinAmount, template.ID)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed count alert templates by tier : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+	if total > 0 {
+		return model.ErrAlertTemplateTierTaken
+	}
+	return nil
+}
+
+// findAlertTemplate returns the template of the highest tier amount reaches,
+// or the default template when the channel has none for the event type.
+func findAlertTemplate(db *gorm.DB, repository AlertTemplateRepository, channelID string, eventType string, amount int64) (*entity.AlertTemplate, error) {
+	template := new(entity.AlertTemplate)
+	err := repository.FindForEvent(db, template, channelID, eventType, amount)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		fallback := defaultAlertTemplate
+		return &fallback, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// renderAlert fills the template with the event's values. Templates are
+// checked when saved, so only templates stored before a variable was removed
+// can fail.
+func renderAlert(template *entity.AlertTemplate, data *model.AlertTemplateData) (*alert.Display, error) {
+	parsed, err := alert.ParseTemplate(template.Text)
+	if err != nil {
+		return nil, err
+	}
+
+	text := parsed.Execute(map[string]string{
+		alert.VariableDonor:    data.Donor,
+		alert.VariableAmount:   alert.FormatAmount(data.Amount, data.Currency),
+		alert.VariableCurrency: data.Currency,
+		alert.VariableMessage:  data.Message,
+	})
+
+	return &alert.Display{
+		TemplateID: template.ID,
+		Text:       text,
+		ImageURL:   template.ImageURL,
+		SoundURL:   template.SoundURL,
+		Animation:  template.Animation,
+	}, nil
+}
+
+// alertDuration is how long an alert from the template stays on screen, at
+// least the channel's minimum.
+func alertDuration(channel *entity.Channel, template *entity.AlertTemplate) time.Duration {
+	seconds := max(channel.AlertDuration, template.Duration)
+	return time.Duration(seconds) * time.Second
+}
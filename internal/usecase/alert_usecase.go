@@ -0,0 +1,411 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"streamhelper-backend/internal/alert"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/model/converter"
+	"streamhelper-backend/internal/repository"
+	"streamhelper-backend/internal/tracing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// alertListLimit caps each list of the queue shown on the dashboard.
+const alertListLimit = 50
+
+// AlertUseCase decides when the overlay shows which alert. Events wait in
+// the channel's queue, optionally for approval first, and Tick starts them
+// one at a time for at least the channel's minimum duration.
+type AlertUseCase struct {
+	TxManager          repository.TransactionManager
+	Log                *logrus.Logger
+	Validate           *validator.Validate
+	ChannelRepository  ChannelRepository
+	TemplateRepository AlertTemplateRepository
+	Queue              AlertQueue
+	Events             EventPublisher
+}
+
+func NewAlertUseCase(txManager repository.TransactionManager, logger *logrus.Logger, validate *validator.Validate,
+	channelRepository ChannelRepository, templateRepository AlertTemplateRepository, queue AlertQueue,
+	events EventPublisher) *AlertUseCase {
+	return &AlertUseCase{
+		TxManager:          txManager,
+		Log:                logger,
+		Validate:           validate,
+		ChannelRepository:  channelRepository,
+		TemplateRepository: templateRepository,
+		Queue:              queue,
+		Events:             events,
+	}
+}
+
+// Enqueue queues an alert for a new event. It is shown once Tick reaches it,
+// or waits in review when the channel moderates alerts or the request holds
+// it. Events that are an AlertSource are rendered with the channel's
+// template for their type and amount, and play the speech of the request
+// if there is one.
+func (c *AlertUseCase) Enqueue(ctx context.Context, request *model.EnqueueAlertRequest) (*model.AlertResponse, error) {
+	ctx, span := tracing.Start(ctx, "AlertUseCase.Enqueue")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindById(tx.DB(), channel, request.ChannelID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by id : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	item := &alert.Alert{
+		ID:        request.Data.GetId(),
+		ChannelID: channel.ID,
+		Type:      request.Type,
+		Duration:  int64(channel.AlertDuration) * time.Second.Milliseconds(),
+	}
+
+	if source, ok := request.Data.(model.AlertSource); ok {
+		values := source.AlertTemplateData()
+		template, err := findAlertTemplate(tx.DB(), c.TemplateRepository, channel.ID, request.Type, values.Amount)
+		if err != nil {
+			c.Log.WithContext(ctx).Warnf("Failed find alert template : %+v", err)
+			return nil, fiber.ErrInternalServerError
+		}
+
+		item.Display, err = renderAlert(template, values)
+		if err != nil {
+			c.Log.WithContext(ctx).Warnf("Failed render alert template %s : %+v", template.ID, err)
+			item.Display, _ = renderAlert(&defaultAlertTemplate, values)
+		}
+		item.Duration = alertDuration(channel, template).Milliseconds()
+
+		if request.Speech != nil {
+			item.Display.SpeechURL = request.Speech.URL
+			// the alert stays until the message has been read out
+			item.Duration = max(item.Duration, request.Speech.Duration)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	var err error
+	item.Data, err = json.Marshal(request.Data)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed marshal alert data : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	item.Status, err = c.Queue.Enqueue(ctx, item, channel.AlertModeration || request.Hold)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed enqueue alert : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.AlertToResponse(item), nil
+}
+
+// Get returns the alert queue of the authenticated user's channel.
+func (c *AlertUseCase) Get(ctx context.Context, request *model.GetAlertQueueRequest) (*model.AlertQueueResponse, error) {
+	ctx, span := tracing.Start(ctx, "AlertUseCase.Get")
+	defer span.End()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel, err := c.channel(ctx, request.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.state(ctx, channel.ID)
+}
+
+// Pause holds back new alerts, for sensitive moments on stream. The alert on
+// screen finishes normally.
+func (c *AlertUseCase) Pause(ctx context.Context, request *model.GetAlertQueueRequest) (*model.AlertQueueResponse, error) {
+	ctx, span := tracing.Start(ctx, "AlertUseCase.Pause")
+	defer span.End()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel, err := c.channel(ctx, request.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.Queue.Pause(ctx, channel.ID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed pause alert queue : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return c.state(ctx, channel.ID)
+}
+
+func (c *AlertUseCase) Resume(ctx context.Context, request *model.GetAlertQueueRequest) (*model.AlertQueueResponse, error) {
+	ctx, span := tracing.Start(ctx, "AlertUseCase.Resume")
+	defer span.End()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel, err := c.channel(ctx, request.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.Queue.Resume(ctx, channel.ID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed resume alert queue : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return c.state(ctx, channel.ID)
+}
+
+// Approve moves an alert out of review to the end of the queue.
+func (c *AlertUseCase) Approve(ctx context.Context, request *model.AlertActionRequest) (*model.AlertResponse, error) {
+	ctx, span := tracing.Start(ctx, "AlertUseCase.Approve")
+	defer span.End()
+
+	return c.act(ctx, request, func(channelID string) error {
+		return c.Queue.Approve(ctx, channelID, request.AlertID)
+	})
+}
+
+// Reject drops an alert in review, it is never shown.
+func (c *AlertUseCase) Reject(ctx context.Context, request *model.AlertActionRequest) (*model.AlertResponse, error) {
+	ctx, span := tracing.Start(ctx, "AlertUseCase.Reject")
+	defer span.End()
+
+	return c.act(ctx, request, func(channelID string) error {
+		return c.Queue.Reject(ctx, channelID, request.AlertID)
+	})
+}
+
+// Skip takes an alert off screen right away, or out of the queue or review
+// before it is shown.
+func (c *AlertUseCase) Skip(ctx context.Context, request *model.AlertActionRequest) (*model.AlertResponse, error) {
+	ctx, span := tracing.Start(ctx, "AlertUseCase.Skip")
+	defer span.End()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel, err := c.channel(ctx, request.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := c.Queue.Skip(ctx, channel.ID, request.AlertID)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed skip alert : %+v", err)
+		return nil, alertError(err)
+	}
+
+	response, err := c.get(ctx, channel.ID, request.AlertID)
+	if err != nil {
+		return nil, err
+	}
+
+	if status == alert.StatusShowing {
+		if _, err := c.Events.Publish(ctx, channel.ID, model.EventTypeAlertSkipped, response); err != nil {
+			c.Log.WithContext(ctx).Warnf("Failed publish alert skipped event : %+v", err)
+		}
+	}
+
+	return response, nil
+}
+
+// Replay puts an alert that was shown or skipped back at the front of the
+// queue.
+func (c *AlertUseCase) Replay(ctx context.Context, request *model.AlertActionRequest) (*model.AlertResponse, error) {
+	ctx, span := tracing.Start(ctx, "AlertUseCase.Replay")
+	defer span.End()
+
+	return c.act(ctx, request, func(channelID string) error {
+		return c.Queue.Replay(ctx, channelID, request.AlertID)
+	})
+}
+
+// GetSettings returns how the authenticated user's channel shows alerts.
+func (c *AlertUseCase) GetSettings(ctx context.Context, request *model.GetAlertQueueRequest) (*model.AlertSettingsResponse, error) {
+	ctx, span := tracing.Start(ctx, "AlertUseCase.GetSettings")
+	defer span.End()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel, err := c.channel(ctx, request.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return converter.AlertSettingsToResponse(channel), nil
+}
+
+// UpdateSettings changes only the settings that are sent. They apply to
+// alerts queued afterwards.
+func (c *AlertUseCase) UpdateSettings(ctx context.Context, request *model.UpdateAlertSettingsRequest) (*model.AlertSettingsResponse, error) {
+	ctx, span := tracing.Start(ctx, "AlertUseCase.UpdateSettings")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, request.UserID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	if request.Moderation != nil {
+		channel.AlertModeration = *request.Moderation
+	}
+	if request.MinDuration != nil {
+		channel.AlertDuration = *request.MinDuration
+	}
+
+	if err := c.ChannelRepository.Update(tx.DB(), channel); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed save channel : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.AlertSettingsToResponse(channel), nil
+}
+
+// Tick finishes every alert whose time is up and starts the next alert of
+// each channel that is not paused. It is run by the alert scheduler worker,
+// on any number of instances at once.
+func (c *AlertUseCase) Tick(ctx context.Context, now time.Time) error {
+	ctx, span := tracing.Start(ctx, "AlertUseCase.Tick")
+	defer span.End()
+
+	channels, err := c.Queue.Active(ctx)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed list active alert queues : %+v", err)
+		return err
+	}
+
+	for _, channelID := range channels {
+		started, err := c.Queue.Advance(ctx, channelID, now)
+		if err != nil {
+			c.Log.WithContext(ctx).Warnf("Failed advance alert queue of channel %s : %+v", channelID, err)
+			continue
+		}
+		if started == nil {
+			continue
+		}
+
+		if _, err := c.Events.Publish(ctx, channelID, model.EventTypeAlert, converter.AlertToResponse(started)); err != nil {
+			c.Log.WithContext(ctx).Warnf("Failed publish alert event : %+v", err)
+		}
+	}
+
+	return nil
+}
+
+// act runs an action on an alert of the authenticated user's channel and
+// returns the alert afterwards.
+func (c *AlertUseCase) act(ctx context.Context, request *model.AlertActionRequest, action func(channelID string) error) (*model.AlertResponse, error) {
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel, err := c.channel(ctx, request.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := action(channel.ID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed change alert : %+v", err)
+		return nil, alertError(err)
+	}
+
+	return c.get(ctx, channel.ID, request.AlertID)
+}
+
+// channel returns the channel of the user, the owner of its alert queue.
+func (c *AlertUseCase) channel(ctx context.Context, userID string) (*entity.Channel, error) {
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, userID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return channel, nil
+}
+
+func (c *AlertUseCase) state(ctx context.Context, channelID string) (*model.AlertQueueResponse, error) {
+	state, err := c.Queue.State(ctx, channelID, alertListLimit)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed get alert queue : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.AlertStateToResponse(state), nil
+}
+
+func (c *AlertUseCase) get(ctx context.Context, channelID string, id string) (*model.AlertResponse, error) {
+	item, err := c.Queue.Get(ctx, channelID, id)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed get alert : %+v", err)
+		return nil, alertError(err)
+	}
+
+	return converter.AlertToResponse(item), nil
+}
+
+func alertError(err error) error {
+	switch {
+	case errors.Is(err, alert.ErrNotFound):
+		return model.ErrAlertNotFound
+	case errors.Is(err, alert.ErrConflict):
+		return model.ErrAlertStatus
+	}
+	return fiber.ErrInternalServerError
+}
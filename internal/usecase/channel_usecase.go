@@ -0,0 +1,271 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/model/converter"
+	"streamhelper-backend/internal/repository"
+	"streamhelper-backend/internal/tracing"
+	"streamhelper-backend/internal/util"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type ChannelUseCase struct {
+	TxManager         repository.TransactionManager
+	Log               *logrus.Logger
+	Validate          *validator.Validate
+	ChannelRepository ChannelRepository
+}
+
+func NewChannelUseCase(txManager repository.TransactionManager, logger *logrus.Logger, validate *validator.Validate,
+	channelRepository ChannelRepository) *ChannelUseCase {
+	return &ChannelUseCase{
+		TxManager:         txManager,
+		Log:               logger,
+		Validate:          validate,
+		ChannelRepository: channelRepository,
+	}
+}
+
+// Create opens the channel of a user. Every user owns at most one channel and
+// slugs are unique across all channels.
+func (c *ChannelUseCase) Create(ctx context.Context, request *model.CreateChannelRequest) (*model.ChannelResponse, error) {
+	ctx, span := tracing.Start(ctx, "ChannelUseCase.Create")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	request.Slug = normalizeSlug(request.Slug)
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	total, err := c.ChannelRepository.CountByUserId(tx.DB(), request.UserID)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed count channel by user : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if total > 0 {
+		return nil, model.ErrChannelExists
+	}
+
+	total, err = c.ChannelRepository.CountBySlug(tx.DB(), request.Slug)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed count channel by slug : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if total > 0 {
+		return nil, model.ErrSlugTaken
+	}
+
+	channel := &entity.Channel{
+		ID:              uuid.NewString(),
+		UserID:          request.UserID,
+		Slug:            request.Slug,
+		DisplayName:     request.DisplayName,
+		Bio:             request.Bio,
+		AvatarURL:       request.AvatarURL,
+		SocialLinks:     converter.SocialLinksToEntity(request.SocialLinks),
+		PlatformHandles: request.PlatformHandles,
+		Currency:        strings.ToUpper(request.Currency),
+		MinDonation:     request.MinDonation,
+		MaxDonation:     request.MaxDonation,
+		OverlayKey:      util.RandomToken(overlayKeyBytes),
+		AlertDuration:   entity.DefaultAlertDuration,
+		FilterAction:    entity.FilterActionMask,
+		TTSLanguage:     entity.DefaultTTSLanguage,
+		TTSMaxLength:    entity.DefaultTTSMaxLength,
+	}
+	if channel.Currency == "" {
+		channel.Currency = entity.DefaultCurrency
+	}
+	if channel.MinDonation == 0 {
+		channel.MinDonation = entity.DefaultMinDonation
+	}
+	if err := validateDonationRange(channel); err != nil {
+		return nil, err
+	}
+	if channel.PlatformHandles == nil {
+		channel.PlatformHandles = map[string]string{}
+	}
+
+	if err := c.ChannelRepository.Create(tx.DB(), channel); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed create channel : %+v", err)
+		return nil, channelConflict(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.ChannelToResponse(channel), nil
+}
+
+func (c *ChannelUseCase) Update(ctx context.Context, request *model.UpdateChannelRequest) (*model.ChannelResponse, error) {
+	ctx, span := tracing.Start(ctx, "ChannelUseCase.Update")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	request.Slug = normalizeSlug(request.Slug)
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, request.UserID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	if request.Slug != "" && request.Slug != channel.Slug {
+		total, err := c.ChannelRepository.CountBySlug(tx.DB(), request.Slug)
+		if err != nil {
+			c.Log.WithContext(ctx).Warnf("Failed count channel by slug : %+v", err)
+			return nil, fiber.ErrInternalServerError
+		}
+		if total > 0 {
+			return nil, model.ErrSlugTaken
+		}
+		channel.Slug = request.Slug
+	}
+
+	if request.DisplayName != "" {
+		channel.DisplayName = request.DisplayName
+	}
+	if request.Bio != nil {
+		channel.Bio = *request.Bio
+	}
+	if request.AvatarURL != nil {
+		channel.AvatarURL = *request.AvatarURL
+	}
+	if request.SocialLinks != nil {
+		channel.SocialLinks = converter.SocialLinksToEntity(request.SocialLinks)
+	}
+	if request.PlatformHandles != nil {
+		channel.PlatformHandles = request.PlatformHandles
+	}
+	if request.Currency != "" {
+		channel.Currency = strings.ToUpper(request.Currency)
+	}
+	if request.MinDonation != nil {
+		channel.MinDonation = *request.MinDonation
+	}
+	if request.MaxDonation != nil {
+		channel.MaxDonation = *request.MaxDonation
+	}
+	if err := validateDonationRange(channel); err != nil {
+		return nil, err
+	}
+
+	if err := c.ChannelRepository.Update(tx.DB(), channel); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed save channel : %+v", err)
+		return nil, channelConflict(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.ChannelToResponse(channel), nil
+}
+
+// Get returns the public profile of the channel addressed by slug.
+func (c *ChannelUseCase) Get(ctx context.Context, request *model.GetChannelRequest) (*model.ChannelResponse, error) {
+	ctx, span := tracing.Start(ctx, "ChannelUseCase.Get")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	request.Slug = normalizeSlug(request.Slug)
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindBySlug(tx.DB(), channel, request.Slug); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by slug : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.ChannelToResponse(channel), nil
+}
+
+// Current returns the channel owned by the authenticated user.
+func (c *ChannelUseCase) Current(ctx context.Context, request *model.GetCurrentChannelRequest) (*model.ChannelResponse, error) {
+	ctx, span := tracing.Start(ctx, "ChannelUseCase.Current")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, request.UserID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.ChannelToResponse(channel), nil
+}
+
+// validateDonationRange rejects a maximum below the minimum, 0 means the
+// channel accepts any amount above its minimum.
+func validateDonationRange(channel *entity.Channel) error {
+	if channel.MaxDonation != 0 && channel.MaxDonation < channel.MinDonation {
+		appError := model.NewAppError(http.StatusBadRequest, model.ErrCodeValidation, "Validation failed")
+		appError.Fields = []model.FieldError{{
+			Field:   "max_donation",
+			Rule:    "gtefield",
+			Param:   "min_donation",
+			Message: "max_donation must be greater than or equal to min_donation",
+		}}
+		return appError
+	}
+	return nil
+}
+
+// channelConflict maps a channel that lost the race to a concurrent request,
+// the counts above saw neither row, to the error the counts would have given.
+func channelConflict(err error) error {
+	switch repository.UniqueViolation(err) {
+	case "idx_channels_slug":
+		return model.ErrSlugTaken
+	case "idx_channels_user_id":
+		return model.ErrChannelExists
+	}
+	return fiber.ErrInternalServerError
+}
+
+func normalizeSlug(slug string) string {
+	return strings.ToLower(strings.TrimSpace(slug))
+}
@@ -0,0 +1,225 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"streamhelper-backend/internal/alert"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/event"
+	"streamhelper-backend/internal/leaderboard"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/payment"
+	"streamhelper-backend/internal/speech"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserRepository is the persistence UserUseCase needs. db is the handle of
+// the current transaction and is ignored by in-memory implementations.
+type UserRepository interface {
+	Create(db *gorm.DB, user *entity.User) error
+	Update(db *gorm.DB, user *entity.User) error
+	CountById(db *gorm.DB, id any) (int64, error)
+	FindById(db *gorm.DB, user *entity.User, id any) error
+	FindByToken(db *gorm.DB, user *entity.User, token string) error
+}
+
+// TokenService issues, checks and revokes access tokens.
+type TokenService interface {
+	CreateToken(ctx context.Context, auth *model.Auth) (string, error)
+	ParseToken(ctx context.Context, token string) (*model.Auth, error)
+	RevokeToken(ctx context.Context, token string) (bool, error)
+	RevokeUserTokens(ctx context.Context, id string) (int64, error)
+}
+
+// PasswordHasher hashes passwords and checks them against a stored hash.
+// Compare returns an error when the password does not match.
+type PasswordHasher interface {
+	Hash(ctx context.Context, password string) (string, error)
+	Compare(ctx context.Context, hash string, password string) error
+}
+
+// ChannelRepository is the persistence ChannelUseCase needs.
+type ChannelRepository interface {
+	Create(db *gorm.DB, channel *entity.Channel) error
+	Update(db *gorm.DB, channel *entity.Channel) error
+	FindById(db *gorm.DB, channel *entity.Channel, id any) error
+	FindBySlug(db *gorm.DB, channel *entity.Channel, slug string) error
+	FindByUserId(db *gorm.DB, channel *entity.Channel, userId string) error
+	FindByOverlayKey(db *gorm.DB, channel *entity.Channel, key string) error
+	CountBySlug(db *gorm.DB, slug string) (int64, error)
+	CountByUserId(db *gorm.DB, userId string) (int64, error)
+}
+
+// DonationRepository is the persistence DonationUseCase needs.
+type DonationRepository interface {
+	Create(db *gorm.DB, donation *entity.Donation) error
+	Update(db *gorm.DB, donation *entity.Donation) error
+	UpdateStatus(db *gorm.DB, donation *entity.Donation, from string) (bool, error)
+	FindById(db *gorm.DB, donation *entity.Donation, id any) error
+	FindByIdForUpdate(db *gorm.DB, donation *entity.Donation, id string) error
+	Search(db *gorm.DB, channelId string, request *model.SearchDonationRequest) ([]entity.Donation, int64, error)
+}
+
+// AlertTemplateRepository is the persistence of the templates alerts are
+// rendered with.
+type AlertTemplateRepository interface {
+	Create(db *gorm.DB, template *entity.AlertTemplate) error
+	Update(db *gorm.DB, template *entity.AlertTemplate) error
+	Delate(db *gorm.DB, template *entity.AlertTemplate) error
+	FindAllByChannelId(db *gorm.DB, channelId string) ([]entity.AlertTemplate, error)
+	FindByIdAndChannelId(db *gorm.DB, template *entity.AlertTemplate, id string, channelId string) error
+	FindForEvent(db *gorm.DB, template *entity.AlertTemplate, channelId string, eventType string, amount int64) error
+	CountByTier(db *gorm.DB, channelId string, eventType string, minAmount int64, excludeId string) (int64, error)
+	CountByChannelId(db *gorm.DB, channelId string) (int64, error)
+}
+
+// BlockedTermRepository is the persistence of the words and rules channels
+// block in donation messages.
+type BlockedTermRepository interface {
+	Create(db *gorm.DB, term *entity.BlockedTerm) error
+	Delate(db *gorm.DB, term *entity.BlockedTerm) error
+	FindAllByChannelId(db *gorm.DB, channelId string) ([]entity.BlockedTerm, error)
+	FindByIdAndChannelId(db *gorm.DB, term *entity.BlockedTerm, id string, channelId string) error
+	CountByPattern(db *gorm.DB, channelId string, kind string, pattern string) (int64, error)
+	CountByChannelId(db *gorm.DB, channelId string) (int64, error)
+}
+
+// FilteredMessageRepository is the audit of filtered donation messages.
+type FilteredMessageRepository interface {
+	Create(db *gorm.DB, message *entity.FilteredMessage) error
+	Search(db *gorm.DB, channelId string, request *model.SearchFilteredMessageRequest) ([]entity.FilteredMessage, int64, error)
+}
+
+// MessageFilter finds blocked words in donation messages, implemented by
+// MessageFilterUseCase.
+type MessageFilter interface {
+	Filter(ctx context.Context, request *model.FilterMessageRequest) (*model.FilterMessageResponse, error)
+}
+
+// GoalRepository is the persistence of donation goals and their progress.
+type GoalRepository interface {
+	Create(db *gorm.DB, goal *entity.Goal) error
+	Update(db *gorm.DB, goal *entity.Goal) error
+	Delate(db *gorm.DB, goal *entity.Goal) error
+	FindByIdAndChannelId(db *gorm.DB, goal *entity.Goal, id string, channelId string) error
+	FindAllOpenByChannelId(db *gorm.DB, channelId string, now int64) ([]entity.Goal, error)
+	FindAllByPaidAt(db *gorm.DB, channelId string, paidAt int64) ([]entity.Goal, error)
+	UpdateProgress(db *gorm.DB, goal *entity.Goal) (bool, error)
+	SearchHistory(db *gorm.DB, channelId string, request *model.SearchGoalHistoryRequest, now int64) ([]entity.Goal, int64, error)
+	CountOpenByChannelId(db *gorm.DB, channelId string, now int64) (int64, error)
+	SumProgress(db *gorm.DB, goal *entity.Goal) (int64, error)
+}
+
+// GoalTracker keeps goals in step with the donations counting toward them,
+// implemented by GoalUseCase.
+type GoalTracker interface {
+	Track(ctx context.Context, request *model.TrackGoalRequest) error
+}
+
+// LeaderboardRepository sums donations to rebuild leaderboards.
+type LeaderboardRepository interface {
+	SumByDonor(db *gorm.DB, channelId string, currency string, from int64, to int64) ([]leaderboard.Entry, error)
+	FindRankedIds(db *gorm.DB, channelId string, currency string, from int64, to int64) ([]string, error)
+}
+
+// LeaderboardStore holds the donor rankings of every channel, implemented by
+// leaderboard.Store.
+type LeaderboardStore interface {
+	Add(ctx context.Context, channelID string, boards []leaderboard.Board, donationID string, entry *leaderboard.Entry) error
+	Replace(ctx context.Context, channelID string, board *leaderboard.Board, entries []leaderboard.Entry, counted []string) error
+	Top(ctx context.Context, channelID string, board *leaderboard.Board, offset int64, limit int64) ([]leaderboard.Entry, int64, error)
+}
+
+// LeaderboardRecorder ranks donations as their payments change, implemented
+// by LeaderboardUseCase.
+type LeaderboardRecorder interface {
+	Record(ctx context.Context, request *model.RecordLeaderboardRequest) error
+}
+
+// PaymentRepository is the persistence of the charges behind donations.
+type PaymentRepository interface {
+	Create(db *gorm.DB, payment *entity.Payment) error
+	Update(db *gorm.DB, payment *entity.Payment) error
+	FindByDonationId(db *gorm.DB, payment *entity.Payment, donationId string) error
+}
+
+// PaymentProvider collects donations, implemented by payment.Midtrans and
+// payment.Simulator. The donation ID is the order ID in every call.
+type PaymentProvider interface {
+	Name() string
+	CreateCharge(ctx context.Context, request *payment.ChargeRequest) (*payment.Charge, error)
+	// VerifyNotification authenticates a callback and returns
+	// payment.ErrInvalidSignature when it was not sent by the provider.
+	VerifyNotification(ctx context.Context, header http.Header, body []byte) (*payment.Notification, error)
+	Status(ctx context.Context, orderID string) (*payment.Notification, error)
+	Refund(ctx context.Context, orderID string, amount int64, reason string) (*payment.Notification, error)
+}
+
+// EventPublisher appends events to a channel's event stream, implemented by
+// event.Hub.
+type EventPublisher interface {
+	Publish(ctx context.Context, channelID string, eventType string, data model.Event) (*event.Event, error)
+}
+
+// EventHub is the event stream overlays read from, implemented by event.Hub.
+type EventHub interface {
+	EventPublisher
+	Subscribe(ctx context.Context, channelID string, key string, buffer int) (*event.Subscription, error)
+	Revoke(ctx context.Context, channelID string, key string) error
+	Since(ctx context.Context, channelID string, afterID string, limit int64) ([]event.Event, bool, error)
+	Ack(ctx context.Context, channelID string, client string, id string) error
+	LastAck(ctx context.Context, channelID string, client string) (string, error)
+}
+
+// AlertQueue holds the alert queue of every channel, implemented by
+// alert.Queue.
+type AlertQueue interface {
+	Enqueue(ctx context.Context, alert *alert.Alert, review bool) (string, error)
+	Approve(ctx context.Context, channelID string, id string) error
+	Reject(ctx context.Context, channelID string, id string) error
+	Skip(ctx context.Context, channelID string, id string) (string, error)
+	Replay(ctx context.Context, channelID string, id string) error
+	Pause(ctx context.Context, channelID string) error
+	Resume(ctx context.Context, channelID string) error
+	Active(ctx context.Context) ([]string, error)
+	Advance(ctx context.Context, channelID string, now time.Time) (*alert.Alert, error)
+	Get(ctx context.Context, channelID string, id string) (*alert.Alert, error)
+	State(ctx context.Context, channelID string, limit int64) (*alert.State, error)
+}
+
+// AlertEnqueuer queues alerts for new events, implemented by AlertUseCase.
+type AlertEnqueuer interface {
+	Enqueue(ctx context.Context, request *model.EnqueueAlertRequest) (*model.AlertResponse, error)
+}
+
+// Synthesizer reads text aloud, implemented by speech.Stub.
+type Synthesizer interface {
+	Name() string
+	Voices() []speech.Voice
+	Synthesize(ctx context.Context, request *speech.Request) (*speech.Audio, error)
+}
+
+// SpeechStorage keeps synthesized audio, implemented by speech.FileStorage.
+// Get returns speech.ErrNotFound for unknown names.
+type SpeechStorage interface {
+	Put(ctx context.Context, name string, audio *speech.Audio) (string, error)
+	Get(ctx context.Context, name string) (*speech.Audio, error)
+}
+
+// SpeechQueue holds the speech jobs of every instance, implemented by
+// speech.Queue.
+type SpeechQueue interface {
+	Push(ctx context.Context, job *speech.Job) error
+	Reserve(ctx context.Context, now time.Time, limit int) ([]speech.Job, error)
+	Done(ctx context.Context, job *speech.Job) error
+	Retry(ctx context.Context, job *speech.Job) error
+}
+
+// SpeechSubmitter has donation messages read aloud, implemented by
+// SpeechUseCase. Submit reports false when the channel does not use
+// text-to-speech.
+type SpeechSubmitter interface {
+	Submit(ctx context.Context, request *model.SubmitSpeechRequest) (bool, error)
+}
@@ -0,0 +1,356 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/model/converter"
+	"streamhelper-backend/internal/payment"
+	"streamhelper-backend/internal/repository"
+	"streamhelper-backend/internal/tracing"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type DonationUseCase struct {
+	TxManager                 repository.TransactionManager
+	Log                       *logrus.Logger
+	Validate                  *validator.Validate
+	ChannelRepository         ChannelRepository
+	DonationRepository        DonationRepository
+	PaymentRepository         PaymentRepository
+	FilteredMessageRepository FilteredMessageRepository
+	PaymentProvider           PaymentProvider
+	Filter                    MessageFilter
+	// PaymentExpiry is how long the donor has to pay before the charge expires.
+	PaymentExpiry time.Duration
+}
+
+func NewDonationUseCase(txManager repository.TransactionManager, logger *logrus.Logger, validate *validator.Validate,
+	channelRepository ChannelRepository, donationRepository DonationRepository, paymentRepository PaymentRepository,
+	filteredMessageRepository FilteredMessageRepository, paymentProvider PaymentProvider, filter MessageFilter,
+	paymentExpiry time.Duration) *DonationUseCase {
+	return &DonationUseCase{
+		TxManager:                 txManager,
+		Log:                       logger,
+		Validate:                  validate,
+		ChannelRepository:         channelRepository,
+		DonationRepository:        donationRepository,
+		PaymentRepository:         paymentRepository,
+		FilteredMessageRepository: filteredMessageRepository,
+		PaymentProvider:           paymentProvider,
+		Filter:                    filter,
+		PaymentExpiry:             paymentExpiry,
+	}
+}
+
+// Create records a pending donation to the channel addressed by slug and
+// charges it at the payment provider. The amount must be within the channel's
+// accepted range and in its currency. A message with blocked words is masked,
+// held for review or rejected, as the channel chose. The response tells the
+// donor how to pay. A donation the provider could not charge is kept as
+// failed.
+func (c *DonationUseCase) Create(ctx context.Context, request *model.CreateDonationRequest) (*model.DonationResponse, error) {
+	ctx, span := tracing.Start(ctx, "DonationUseCase.Create")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	request.Slug = normalizeSlug(request.Slug)
+	request.DonorName = strings.TrimSpace(request.DonorName)
+	request.Currency = strings.ToUpper(request.Currency)
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindBySlug(tx.DB(), channel, request.Slug); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by slug : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	if request.Currency != "" && request.Currency != channel.Currency {
+		return nil, donationFieldError(model.ErrCurrencyNotAccepted, "currency", "eq", channel.Currency)
+	}
+	if request.Amount < channel.MinDonation {
+		return nil, donationFieldError(model.ErrDonationAmountOutOfRange, "amount", "min", strconv.FormatInt(channel.MinDonation, 10))
+	}
+	if channel.MaxDonation != 0 && request.Amount > channel.MaxDonation {
+		return nil, donationFieldError(model.ErrDonationAmountOutOfRange, "amount", "max", strconv.FormatInt(channel.MaxDonation, 10))
+	}
+
+	donation := &entity.Donation{
+		ID:        uuid.NewString(),
+		ChannelID: channel.ID,
+		DonorName: request.DonorName,
+		Message:   request.Message,
+		Amount:    request.Amount,
+		Currency:  channel.Currency,
+		Status:    entity.DonationStatusPending,
+		Anonymous: request.Anonymous,
+	}
+
+	filtered, err := c.Filter.Filter(ctx, &model.FilterMessageRequest{ChannelID: channel.ID, Text: donation.Message})
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed filter donation message : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if len(filtered.Matches) > 0 {
+		rejected, err := c.applyFilter(ctx, tx, channel, donation, filtered)
+		if err != nil {
+			return nil, err
+		}
+		if rejected {
+			return nil, donationFieldError(model.ErrMessageRejected, "message", "blocked", "")
+		}
+	}
+
+	if err := c.DonationRepository.Create(tx.DB(), donation); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed create donation : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	// the donation is kept before the provider is asked for a charge, so
+	// every charge it creates has a donation its callbacks can find, and no
+	// connection is held while waiting for it
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	charge, err := c.PaymentProvider.CreateCharge(ctx, &payment.ChargeRequest{
+		OrderID:      donation.ID,
+		Amount:       donation.Amount,
+		Currency:     donation.Currency,
+		Method:       request.PaymentMethod,
+		Bank:         request.Bank,
+		CustomerName: donation.DonorName,
+		Description:  "Donation to " + channel.DisplayName,
+		Expiry:       c.PaymentExpiry,
+	})
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed create charge : %+v", err)
+		c.fail(ctx, donation)
+		if errors.Is(err, payment.ErrUnsupportedMethod) {
+			return nil, model.ErrPaymentMethodNotSupported
+		}
+		if errors.Is(err, payment.ErrUnsupportedCurrency) {
+			return nil, model.ErrPaymentCurrencyNotSupported
+		}
+		return nil, model.ErrPaymentProvider.Wrap(err)
+	}
+
+	donationPayment := &entity.Payment{
+		ID:          uuid.NewString(),
+		DonationID:  donation.ID,
+		Provider:    c.PaymentProvider.Name(),
+		Reference:   charge.Reference,
+		Method:      charge.Method,
+		Bank:        charge.Bank,
+		Status:      charge.Status,
+		Amount:      donation.Amount,
+		Currency:    donation.Currency,
+		QRString:    charge.QRString,
+		RedirectURL: charge.RedirectURL,
+		VANumber:    charge.VANumber,
+		ExpiresAt:   charge.ExpiresAt,
+	}
+
+	tx = c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.PaymentRepository.Create(tx.DB(), donationPayment); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed create payment : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	response := converter.DonationToResponse(donation)
+	response.Payment = converter.PaymentToResponse(donationPayment)
+	return response, nil
+}
+
+// fail marks a donation the provider could not charge as failed. The request
+// may be gone by now, the donation is marked all the same.
+func (c *DonationUseCase) fail(ctx context.Context, donation *entity.Donation) {
+	ctx = context.WithoutCancel(ctx)
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	donation.Status = entity.DonationStatusFailed
+	if _, err := c.DonationRepository.UpdateStatus(tx.DB(), donation, entity.DonationStatusPending); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed update donation : %+v", err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+	}
+}
+
+// Import stores a donation with the given ID unless it already exists and
+// reports whether it was created. Paid donations are marked paid at their
+// creation time.
+func (c *DonationUseCase) Import(ctx context.Context, request *model.ImportDonationRequest) (bool, error) {
+	ctx, span := tracing.Start(ctx, "DonationUseCase.Import")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	request.Slug = normalizeSlug(request.Slug)
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return false, model.NewValidationError(err)
+	}
+
+	if err := c.DonationRepository.FindById(tx.DB(), new(entity.Donation), request.ID); err == nil {
+		return false, nil
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindBySlug(tx.DB(), channel, request.Slug); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by slug : %+v", err)
+		return false, model.ErrChannelNotFound
+	}
+
+	donation := &entity.Donation{
+		ID:        request.ID,
+		ChannelID: channel.ID,
+		DonorName: request.DonorName,
+		Message:   request.Message,
+		Amount:    request.Amount,
+		Currency:  channel.Currency,
+		Status:    request.Status,
+		Anonymous: request.Anonymous,
+		CreatedAt: request.CreatedAt,
+		UpdatedAt: request.CreatedAt,
+	}
+	if donation.Status == entity.DonationStatusPaid {
+		donation.PaidAt = request.CreatedAt
+	}
+
+	if err := c.DonationRepository.Create(tx.DB(), donation); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed create donation : %+v", err)
+		return false, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return false, fiber.ErrInternalServerError
+	}
+
+	return true, nil
+}
+
+// Search pages through the donations of the authenticated user's channel.
+func (c *DonationUseCase) Search(ctx context.Context, request *model.SearchDonationRequest) (*model.PageResponse[model.DonationResponse], error) {
+	ctx, span := tracing.Start(ctx, "DonationUseCase.Search")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, request.UserID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	donations, total, err := c.DonationRepository.Search(tx.DB(), channel.ID, request)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed search donations : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	responses := make([]model.DonationResponse, len(donations))
+	for i := range donations {
+		responses[i] = *converter.DonationToResponse(&donations[i])
+	}
+
+	return &model.PageResponse[model.DonationResponse]{
+		Data: responses,
+		PageMetadata: model.PageMetadata{
+			Page:      request.Page,
+			Size:      request.Size,
+			TotalItem: total,
+			TotalPage: (total + int64(request.Size) - 1) / int64(request.Size),
+		},
+	}, nil
+}
+
+// applyFilter records a message with blocked words in the audit and masks or
+// holds it. A rejected donation is not stored, only the audit is committed.
+func (c *DonationUseCase) applyFilter(ctx context.Context, tx repository.Transaction, channel *entity.Channel,
+	donation *entity.Donation, filtered *model.FilterMessageResponse) (bool, error) {
+	audit := &entity.FilteredMessage{
+		ID:         uuid.NewString(),
+		ChannelID:  channel.ID,
+		DonationID: donation.ID,
+		Action:     channel.FilterAction,
+		Original:   donation.Message,
+		Filtered:   filtered.Text,
+		Matches:    filtered.Matches,
+	}
+
+	switch channel.FilterAction {
+	case entity.FilterActionReject:
+		audit.DonationID = ""
+	case entity.FilterActionHold:
+		donation.Held = true
+	default:
+		audit.Action = entity.FilterActionMask
+		donation.Message = filtered.Text
+	}
+
+	if err := c.FilteredMessageRepository.Create(tx.DB(), audit); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed create filtered message : %+v", err)
+		return false, fiber.ErrInternalServerError
+	}
+
+	if audit.Action != entity.FilterActionReject {
+		return false, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return false, fiber.ErrInternalServerError
+	}
+	return true, nil
+}
+
+// donationFieldError copies err and points it at the offending field, so
+// clients can show the accepted bound next to the input.
+func donationFieldError(err *model.AppError, field string, rule string, param string) *model.AppError {
+	appError := model.NewAppError(http.StatusBadRequest, err.Code, err.Message)
+	appError.Fields = []model.FieldError{{
+		Field:   field,
+		Rule:    rule,
+		Param:   param,
+		Message: err.Message,
+	}}
+	return appError
+}
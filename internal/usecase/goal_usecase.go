@@ -0,0 +1,533 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/model/converter"
+	"streamhelper-backend/internal/repository"
+	"streamhelper-backend/internal/tracing"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// GoalUseCase manages the donation goals of channels. Progress is summed
+// from paid donations whenever a goal or a payment changes, and every change
+// of a running goal is pushed to the channel's event stream for the overlay.
+type GoalUseCase struct {
+	TxManager          repository.TransactionManager
+	Log                *logrus.Logger
+	Validate           *validator.Validate
+	ChannelRepository  ChannelRepository
+	DonationRepository DonationRepository
+	GoalRepository     GoalRepository
+	Events             EventPublisher
+}
+
+func NewGoalUseCase(txManager repository.TransactionManager, logger *logrus.Logger, validate *validator.Validate,
+	channelRepository ChannelRepository, donationRepository DonationRepository, goalRepository GoalRepository,
+	events EventPublisher) *GoalUseCase {
+	return &GoalUseCase{
+		TxManager:          txManager,
+		Log:                logger,
+		Validate:           validate,
+		ChannelRepository:  channelRepository,
+		DonationRepository: donationRepository,
+		GoalRepository:     goalRepository,
+		Events:             events,
+	}
+}
+
+// Create starts a goal of the authenticated user's channel. Donations paid
+// since StartsAt count right away, so a goal may start in the past.
+func (c *GoalUseCase) Create(ctx context.Context, request *model.CreateGoalRequest) (*model.GoalResponse, error) {
+	ctx, span := tracing.Start(ctx, "GoalUseCase.Create")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	request.Title = strings.TrimSpace(request.Title)
+	request.Currency = strings.ToUpper(request.Currency)
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, request.UserID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	if request.Currency != "" && request.Currency != channel.Currency {
+		return nil, donationFieldError(model.ErrCurrencyNotAccepted, "currency", "eq", channel.Currency)
+	}
+
+	now := time.Now().UnixMilli()
+	goal := &entity.Goal{
+		ID:           uuid.NewString(),
+		ChannelID:    channel.ID,
+		Title:        request.Title,
+		TargetAmount: request.TargetAmount,
+		Currency:     channel.Currency,
+		Sources:      compactSources(request.Sources),
+		StartsAt:     request.StartsAt,
+		EndsAt:       request.EndsAt,
+	}
+	if goal.StartsAt == 0 {
+		goal.StartsAt = now
+	}
+	if goal.EndsAt != 0 && goal.EndsAt <= goal.StartsAt {
+		return nil, model.ErrGoalDates
+	}
+
+	total, err := c.GoalRepository.CountOpenByChannelId(tx.DB(), channel.ID, now)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed count goals : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if total >= entity.MaxOpenGoals {
+		return nil, model.ErrGoalLimit
+	}
+
+	completed, err := c.refresh(tx.DB(), goal, now)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed sum goal progress : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := c.GoalRepository.Create(tx.DB(), goal); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed create goal : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	c.publish(ctx, goal, now, completed)
+	return converter.GoalToResponse(goal, now), nil
+}
+
+// Update changes a goal that has not ended, its progress is summed again.
+func (c *GoalUseCase) Update(ctx context.Context, request *model.UpdateGoalRequest) (*model.GoalResponse, error) {
+	ctx, span := tracing.Start(ctx, "GoalUseCase.Update")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if request.Title != nil {
+		*request.Title = strings.TrimSpace(*request.Title)
+	}
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	now := time.Now().UnixMilli()
+	goal, err := c.find(ctx, tx.DB(), request.UserID, request.GoalID)
+	if err != nil {
+		return nil, err
+	}
+	if goal.Ended(now) {
+		return nil, model.ErrGoalEnded
+	}
+
+	if request.Title != nil {
+		goal.Title = *request.Title
+	}
+	if request.TargetAmount != nil {
+		goal.TargetAmount = *request.TargetAmount
+	}
+	if request.Sources != nil {
+		goal.Sources = compactSources(*request.Sources)
+	}
+	if request.EndsAt != nil {
+		goal.EndsAt = *request.EndsAt
+	}
+	if goal.EndsAt != 0 && goal.EndsAt <= goal.StartsAt {
+		return nil, model.ErrGoalDates
+	}
+
+	completed, err := c.refresh(tx.DB(), goal, now)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed sum goal progress : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := c.GoalRepository.Update(tx.DB(), goal); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed save goal : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	c.publish(ctx, goal, now, completed)
+	return converter.GoalToResponse(goal, now), nil
+}
+
+// End ends a goal now, it moves to the history. A goal that has not started
+// yet ends with nothing counted.
+func (c *GoalUseCase) End(ctx context.Context, request *model.GetGoalRequest) (*model.GoalResponse, error) {
+	ctx, span := tracing.Start(ctx, "GoalUseCase.End")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	now := time.Now().UnixMilli()
+	goal, err := c.find(ctx, tx.DB(), request.UserID, request.GoalID)
+	if err != nil {
+		return nil, err
+	}
+	if goal.Ended(now) {
+		return nil, model.ErrGoalEnded
+	}
+
+	goal.StartsAt = min(goal.StartsAt, now)
+	goal.EndsAt = now
+	if _, err := c.refresh(tx.DB(), goal, now); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed sum goal progress : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := c.GoalRepository.Update(tx.DB(), goal); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed save goal : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	response := converter.GoalToResponse(goal, now)
+	if _, err := c.Events.Publish(ctx, goal.ChannelID, model.EventTypeGoalEnded, response); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed publish goal event : %+v", err)
+	}
+	return response, nil
+}
+
+// Delete removes a goal, from the history too.
+func (c *GoalUseCase) Delete(ctx context.Context, request *model.GetGoalRequest) (bool, error) {
+	ctx, span := tracing.Start(ctx, "GoalUseCase.Delete")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return false, model.NewValidationError(err)
+	}
+
+	goal, err := c.find(ctx, tx.DB(), request.UserID, request.GoalID)
+	if err != nil {
+		return false, err
+	}
+
+	if err := c.GoalRepository.Delate(tx.DB(), goal); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed delete goal : %+v", err)
+		return false, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return false, fiber.ErrInternalServerError
+	}
+
+	now := time.Now().UnixMilli()
+	if !goal.Ended(now) {
+		// the overlay takes the bar off screen
+		if _, err := c.Events.Publish(ctx, goal.ChannelID, model.EventTypeGoalEnded, converter.GoalToResponse(goal, now)); err != nil {
+			c.Log.WithContext(ctx).Warnf("Failed publish goal event : %+v", err)
+		}
+	}
+	return true, nil
+}
+
+func (c *GoalUseCase) Get(ctx context.Context, request *model.GetGoalRequest) (*model.GoalResponse, error) {
+	ctx, span := tracing.Start(ctx, "GoalUseCase.Get")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	goal, err := c.find(ctx, tx.DB(), request.UserID, request.GoalID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.GoalToResponse(goal, time.Now().UnixMilli()), nil
+}
+
+// List returns the goals of the authenticated user's channel that have not
+// ended, scheduled ones included.
+func (c *GoalUseCase) List(ctx context.Context, request *model.ListGoalRequest) ([]model.GoalResponse, error) {
+	ctx, span := tracing.Start(ctx, "GoalUseCase.List")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, request.UserID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	now := time.Now().UnixMilli()
+	goals, err := c.GoalRepository.FindAllOpenByChannelId(tx.DB(), channel.ID, now)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find goals : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.GoalsToResponse(goals, now), nil
+}
+
+// ListChannel returns the running goals of a channel for its public page.
+func (c *GoalUseCase) ListChannel(ctx context.Context, request *model.ListChannelGoalRequest) ([]model.GoalResponse, error) {
+	ctx, span := tracing.Start(ctx, "GoalUseCase.ListChannel")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	request.Slug = normalizeSlug(request.Slug)
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindBySlug(tx.DB(), channel, request.Slug); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by slug : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	now := time.Now().UnixMilli()
+	goals, err := c.GoalRepository.FindAllOpenByChannelId(tx.DB(), channel.ID, now)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find goals : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	running := slices.DeleteFunc(goals, func(goal entity.Goal) bool {
+		return goal.StartsAt > now
+	})
+	return converter.GoalsToResponse(running, now), nil
+}
+
+// History pages through the ended goals of the authenticated user's channel.
+func (c *GoalUseCase) History(ctx context.Context, request *model.SearchGoalHistoryRequest) (*model.PageResponse[model.GoalResponse], error) {
+	ctx, span := tracing.Start(ctx, "GoalUseCase.History")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, request.UserID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	now := time.Now().UnixMilli()
+	goals, total, err := c.GoalRepository.SearchHistory(tx.DB(), channel.ID, request, now)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed search goals : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return &model.PageResponse[model.GoalResponse]{
+		Data: converter.GoalsToResponse(goals, now),
+		PageMetadata: model.PageMetadata{
+			Page:      request.Page,
+			Size:      request.Size,
+			TotalItem: total,
+			TotalPage: (total + int64(request.Size) - 1) / int64(request.Size),
+		},
+	}, nil
+}
+
+// Track sums the goals a donation counts toward again after its payment was
+// paid or refunded, and publishes those still running that changed.
+func (c *GoalUseCase) Track(ctx context.Context, request *model.TrackGoalRequest) error {
+	ctx, span := tracing.Start(ctx, "GoalUseCase.Track")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return model.NewValidationError(err)
+	}
+
+	donation := new(entity.Donation)
+	if err := c.DonationRepository.FindById(tx.DB(), donation, request.DonationID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find donation by id : %+v", err)
+		return model.ErrDonationNotFound
+	}
+	if donation.PaidAt == 0 {
+		return nil
+	}
+
+	goals, err := c.GoalRepository.FindAllByPaidAt(tx.DB(), donation.ChannelID, donation.PaidAt)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find goals : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	now := time.Now().UnixMilli()
+	var changed []entity.Goal
+	var completed []bool
+	for i := range goals {
+		goal := &goals[i]
+		amount := goal.Amount
+		if _, err := c.refresh(tx.DB(), goal, now); err != nil {
+			c.Log.WithContext(ctx).Warnf("Failed sum goal progress : %+v", err)
+			return fiber.ErrInternalServerError
+		}
+		if goal.Amount == amount {
+			continue
+		}
+
+		justCompleted, err := c.GoalRepository.UpdateProgress(tx.DB(), goal)
+		if err != nil {
+			c.Log.WithContext(ctx).Warnf("Failed save goal progress : %+v", err)
+			return fiber.ErrInternalServerError
+		}
+		changed = append(changed, *goal)
+		completed = append(completed, justCompleted)
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	for i := range changed {
+		// a refund may change the total of a goal in the history, the
+		// overlay no longer shows it
+		if !changed[i].Ended(now) {
+			c.publish(ctx, &changed[i], now, completed[i])
+		}
+	}
+	return nil
+}
+
+// refresh sums the goal's progress and marks it completed when it reaches
+// its target for the first time, which it reports.
+func (c *GoalUseCase) refresh(db *gorm.DB, goal *entity.Goal, now int64) (bool, error) {
+	amount, err := c.GoalRepository.SumProgress(db, goal)
+	if err != nil {
+		return false, err
+	}
+
+	goal.Amount = amount
+	if goal.CompletedAt != 0 || goal.Amount < goal.TargetAmount {
+		return false, nil
+	}
+	goal.CompletedAt = now
+	return true, nil
+}
+
+// publish pushes a running goal to the overlay, followed by the completion
+// when it just reached its target.
+func (c *GoalUseCase) publish(ctx context.Context, goal *entity.Goal, now int64, completed bool) {
+	if goal.StartsAt > now {
+		return
+	}
+
+	response := converter.GoalToResponse(goal, now)
+	if _, err := c.Events.Publish(ctx, goal.ChannelID, model.EventTypeGoalProgress, response); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed publish goal event : %+v", err)
+	}
+	if !completed {
+		return
+	}
+	if _, err := c.Events.Publish(ctx, goal.ChannelID, model.EventTypeGoalCompleted, response); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed publish goal event : %+v", err)
+	}
+}
+
+// find returns a goal of the user's channel.
+func (c *GoalUseCase) find(ctx context.Context, db *gorm.DB, userID string, goalID string) (*entity.Goal, error) {
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(db, channel, userID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	goal := new(entity.Goal)
+	if err := c.GoalRepository.FindByIdAndChannelId(db, goal, goalID, channel.ID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find goal : %+v", err)
+		return nil, model.ErrGoalNotFound
+	}
+	return goal, nil
+}
+
+// compactSources sorts sources and drops repeats.
+func compactSources(sources []string) []string {
+	if len(sources) == 0 {
+		return []string{}
+	}
+	sorted := slices.Clone(sources)
+	slices.Sort(sorted)
+	return slices.Compact(sorted)
+}
@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"streamhelper-backend/internal/model"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	HealthStatusUp   = "UP"
+	HealthStatusDown = "DOWN"
+)
+
+// HealthCheck reports whether a dependency is usable. It should return
+// quickly and respect ctx cancellation.
+type HealthCheck func(ctx context.Context) error
+
+type HealthUseCase struct {
+	Log     *logrus.Logger
+	Timeout time.Duration
+
+	mu     sync.RWMutex
+	names  []string
+	checks map[string]HealthCheck
+	ready  atomic.Bool
+}
+
+func NewHealthUseCase(logger *logrus.Logger) *HealthUseCase {
+	return &HealthUseCase{
+		Log:     logger,
+		Timeout: 2 * time.Second,
+		checks:  map[string]HealthCheck{},
+	}
+}
+
+// Register adds a named readiness check. Registering the same name again
+// replaces the previous check.
+func (c *HealthUseCase) Register(name string, check HealthCheck) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.checks[name]; !ok {
+		c.names = append(c.names, name)
+	}
+	c.checks[name] = check
+}
+
+// SetReady flips readiness, e.g. to false as soon as graceful shutdown starts
+// so the load balancer stops routing new traffic here.
+func (c *HealthUseCase) SetReady(ready bool) {
+	c.ready.Store(ready)
+}
+
+func (c *HealthUseCase) Liveness(ctx context.Context) *model.HealthResponse {
+	return &model.HealthResponse{Status: HealthStatusUp}
+}
+
+func (c *HealthUseCase) Readiness(ctx context.Context) *model.HealthResponse {
+	c.mu.RLock()
+	names := append([]string{}, c.names...)
+	checks := make([]HealthCheck, len(names))
+	for i, name := range names {
+		checks[i] = c.checks[name]
+	}
+	c.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	results := make([]model.HealthCheckResponse, len(names))
+	var wg sync.WaitGroup
+	for i := range names {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = c.run(ctx, names[i], checks[i])
+		}(i)
+	}
+	wg.Wait()
+
+	response := &model.HealthResponse{
+		Status: HealthStatusUp,
+		Checks: make(map[string]model.HealthCheckResponse, len(names)+1),
+	}
+	for i, name := range names {
+		response.Checks[name] = results[i]
+		if results[i].Status != HealthStatusUp {
+			response.Status = HealthStatusDown
+		}
+	}
+
+	if !c.ready.Load() {
+		response.Status = HealthStatusDown
+		response.Checks["lifecycle"] = model.HealthCheckResponse{
+			Status: HealthStatusDown,
+			Error:  "not accepting traffic",
+		}
+	}
+
+	return response
+}
+
+func (c *HealthUseCase) run(ctx context.Context, name string, check HealthCheck) model.HealthCheckResponse {
+	start := time.Now()
+	err := check(ctx)
+	result := model.HealthCheckResponse{
+		Status:    HealthStatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+
+	// readiness is public, the cause only goes to the log
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Health check %s failed : %+v", name, err)
+		result.Status = HealthStatusDown
+		result.Error = "unavailable"
+	}
+
+	return result
+}
+
+func DatabaseHealthCheck(db *gorm.DB) HealthCheck {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+func RedisHealthCheck(client *redis.Client) HealthCheck {
+	return func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	}
+}
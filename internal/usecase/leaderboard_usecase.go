@@ -0,0 +1,399 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/leaderboard"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/model/converter"
+	"streamhelper-backend/internal/repository"
+	"streamhelper-backend/internal/tracing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// LeaderboardUseCase ranks the donors of each channel on its all-time,
+// monthly, weekly and stream boards. Boards live in Redis and are updated
+// as payments are confirmed or refunded; anonymous donations are never
+// ranked. Channels that show rankings on their overlay get the top of a
+// current board whenever it changes.
+type LeaderboardUseCase struct {
+	TxManager             repository.TransactionManager
+	Log                   *logrus.Logger
+	Validate              *validator.Validate
+	ChannelRepository     ChannelRepository
+	DonationRepository    DonationRepository
+	LeaderboardRepository LeaderboardRepository
+	Store                 LeaderboardStore
+	Events                EventPublisher
+	// Location is where weeks and months begin.
+	Location *time.Location
+	// Top is how many donors overlays are sent.
+	Top int64
+}
+
+func NewLeaderboardUseCase(txManager repository.TransactionManager, logger *logrus.Logger, validate *validator.Validate,
+	channelRepository ChannelRepository, donationRepository DonationRepository, leaderboardRepository LeaderboardRepository,
+	store LeaderboardStore, events EventPublisher, location *time.Location, top int64) *LeaderboardUseCase {
+	return &LeaderboardUseCase{
+		TxManager:             txManager,
+		Log:                   logger,
+		Validate:              validate,
+		ChannelRepository:     channelRepository,
+		DonationRepository:    donationRepository,
+		LeaderboardRepository: leaderboardRepository,
+		Store:                 store,
+		Events:                events,
+		Location:              location,
+		Top:                   top,
+	}
+}
+
+// Record adds a paid donation to the boards of the time it was paid, or
+// takes a refunded one off them again.
+func (c *LeaderboardUseCase) Record(ctx context.Context, request *model.RecordLeaderboardRequest) error {
+	ctx, span := tracing.Start(ctx, "LeaderboardUseCase.Record")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return model.NewValidationError(err)
+	}
+
+	donation := new(entity.Donation)
+	if err := c.DonationRepository.FindById(tx.DB(), donation, request.DonationID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find donation by id : %+v", err)
+		return model.ErrDonationNotFound
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindById(tx.DB(), channel, donation.ChannelID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by id : %+v", err)
+		return model.ErrChannelNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	if donation.PaidAt == 0 || donation.Anonymous || donation.Currency != channel.Currency {
+		return nil
+	}
+	entry := &leaderboard.Entry{
+		Donor:  leaderboard.DonorKey(donation.DonorName),
+		Name:   donation.DonorName,
+		Amount: donation.Amount,
+	}
+	switch donation.Status {
+	case entity.DonationStatusPaid:
+	case entity.DonationStatusRefunded:
+		entry.Amount = -entry.Amount
+	default:
+		return nil
+	}
+
+	// only the boards overlays show right now are published
+	boards := leaderboard.Boards(time.UnixMilli(donation.PaidAt), c.Location, channel.StreamStartedAt)
+	now := time.Now()
+	var shown []leaderboard.Board
+	for _, board := range boards {
+		if channel.OverlayRankings && leaderboard.Current(board.Kind, now, c.Location, channel.StreamStartedAt).Period == board.Period {
+			shown = append(shown, board)
+		}
+	}
+
+	before := make([][]leaderboard.Entry, len(shown))
+	for i := range shown {
+		top, _, err := c.Store.Top(ctx, channel.ID, &shown[i], 0, c.Top)
+		if err != nil {
+			c.Log.WithContext(ctx).Warnf("Failed read leaderboard : %+v", err)
+			return fiber.ErrInternalServerError
+		}
+		before[i] = top
+	}
+
+	if err := c.Store.Add(ctx, channel.ID, boards, donation.ID, entry); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed update leaderboards : %+v", err)
+		return fiber.ErrInternalServerError
+	}
+
+	for i := range shown {
+		top, _, err := c.Store.Top(ctx, channel.ID, &shown[i], 0, c.Top)
+		if err != nil {
+			c.Log.WithContext(ctx).Warnf("Failed read leaderboard : %+v", err)
+			return fiber.ErrInternalServerError
+		}
+		if !slices.Equal(before[i], top) {
+			c.publish(ctx, channel, &shown[i], top)
+		}
+	}
+	return nil
+}
+
+// GetSettings returns whether the authenticated user's channel shows
+// rankings on its overlay.
+func (c *LeaderboardUseCase) GetSettings(ctx context.Context, request *model.GetLeaderboardSettingsRequest) (*model.LeaderboardSettingsResponse, error) {
+	ctx, span := tracing.Start(ctx, "LeaderboardUseCase.GetSettings")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, request.UserID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.LeaderboardSettingsToResponse(channel), nil
+}
+
+func (c *LeaderboardUseCase) UpdateSettings(ctx context.Context, request *model.UpdateLeaderboardSettingsRequest) (*model.LeaderboardSettingsResponse, error) {
+	ctx, span := tracing.Start(ctx, "LeaderboardUseCase.UpdateSettings")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, request.UserID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	if request.Overlay != nil {
+		channel.OverlayRankings = *request.Overlay
+	}
+
+	if err := c.ChannelRepository.Update(tx.DB(), channel); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed save channel : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.LeaderboardSettingsToResponse(channel), nil
+}
+
+// Get returns a page of a board of the authenticated user's channel.
+func (c *LeaderboardUseCase) Get(ctx context.Context, request *model.GetLeaderboardRequest) (*model.LeaderboardResponse, error) {
+	ctx, span := tracing.Start(ctx, "LeaderboardUseCase.Get")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, request.UserID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return c.page(ctx, channel, request.Board, request.Page, request.Size)
+}
+
+// GetChannel returns a page of a board of a channel by its slug, for its
+// public page and overlay.
+func (c *LeaderboardUseCase) GetChannel(ctx context.Context, request *model.GetChannelLeaderboardRequest) (*model.LeaderboardResponse, error) {
+	ctx, span := tracing.Start(ctx, "LeaderboardUseCase.GetChannel")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	request.Slug = normalizeSlug(request.Slug)
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindBySlug(tx.DB(), channel, request.Slug); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by slug : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return c.page(ctx, channel, request.Board, request.Page, request.Size)
+}
+
+// StartStream starts a new stream board for the authenticated user's
+// channel. Only donations paid from now on count toward it.
+func (c *LeaderboardUseCase) StartStream(ctx context.Context, request *model.StartStreamRequest) (*model.LeaderboardResponse, error) {
+	ctx, span := tracing.Start(ctx, "LeaderboardUseCase.StartStream")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, request.UserID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	now := time.Now()
+	channel.StreamStartedAt = now.UnixMilli()
+	if err := c.ChannelRepository.Update(tx.DB(), channel); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed save channel : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	board := leaderboard.Current(leaderboard.KindStream, now, c.Location, channel.StreamStartedAt)
+	c.publish(ctx, channel, &board, nil)
+
+	return converter.LeaderboardToResponse(&board, channel.Currency, nil, 0), nil
+}
+
+// Rebuild sums the current boards of the authenticated user's channel again
+// from its donations, for when Redis lost or missed updates.
+func (c *LeaderboardUseCase) Rebuild(ctx context.Context, request *model.RebuildLeaderboardRequest) (bool, error) {
+	ctx, span := tracing.Start(ctx, "LeaderboardUseCase.Rebuild")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return false, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, request.UserID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return false, model.ErrChannelNotFound
+	}
+
+	boards := leaderboard.Boards(time.Now(), c.Location, channel.StreamStartedAt)
+	entries := make([][]leaderboard.Entry, len(boards))
+	counted := make([][]string, len(boards))
+	for i, board := range boards {
+		var from, to int64
+		if !board.Start.IsZero() {
+			from = board.Start.UnixMilli()
+		}
+		if !board.End.IsZero() {
+			to = board.End.UnixMilli()
+		}
+
+		sums, err := c.LeaderboardRepository.SumByDonor(tx.DB(), channel.ID, channel.Currency, from, to)
+		if err != nil {
+			c.Log.WithContext(ctx).Warnf("Failed sum donations by donor : %+v", err)
+			return false, fiber.ErrInternalServerError
+		}
+		entries[i] = sums
+
+		ids, err := c.LeaderboardRepository.FindRankedIds(tx.DB(), channel.ID, channel.Currency, from, to)
+		if err != nil {
+			c.Log.WithContext(ctx).Warnf("Failed find ranked donations : %+v", err)
+			return false, fiber.ErrInternalServerError
+		}
+		counted[i] = ids
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return false, fiber.ErrInternalServerError
+	}
+
+	for i := range boards {
+		if err := c.Store.Replace(ctx, channel.ID, &boards[i], entries[i], counted[i]); err != nil {
+			c.Log.WithContext(ctx).Warnf("Failed replace leaderboard : %+v", err)
+			return false, fiber.ErrInternalServerError
+		}
+		if !channel.OverlayRankings {
+			continue
+		}
+
+		top, _, err := c.Store.Top(ctx, channel.ID, &boards[i], 0, c.Top)
+		if err != nil {
+			c.Log.WithContext(ctx).Warnf("Failed read leaderboard : %+v", err)
+			return false, fiber.ErrInternalServerError
+		}
+		c.publish(ctx, channel, &boards[i], top)
+	}
+	return true, nil
+}
+
+func (c *LeaderboardUseCase) page(ctx context.Context, channel *entity.Channel, kind string, page int, size int) (*model.LeaderboardResponse, error) {
+	board := leaderboard.Current(kind, time.Now(), c.Location, channel.StreamStartedAt)
+	offset := int64((page - 1) * size)
+	entries, total, err := c.Store.Top(ctx, channel.ID, &board, offset, int64(size))
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed read leaderboard : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	response := converter.LeaderboardToResponse(&board, channel.Currency, entries, offset)
+	response.Paging = model.PageMetadata{
+		Page:      page,
+		Size:      size,
+		TotalItem: total,
+		TotalPage: (total + int64(size) - 1) / int64(size),
+	}
+	return response, nil
+}
+
+// publish sends the top of a board to the channel's overlays if it shows
+// rankings. The board is already updated, so a failure is only logged.
+func (c *LeaderboardUseCase) publish(ctx context.Context, channel *entity.Channel, board *leaderboard.Board, top []leaderboard.Entry) {
+	if !channel.OverlayRankings {
+		return
+	}
+
+	response := converter.LeaderboardToResponse(board, channel.Currency, top, 0)
+	if _, err := c.Events.Publish(ctx, channel.ID, model.EventTypeLeaderboard, response); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed publish leaderboard event : %+v", err)
+	}
+}
@@ -0,0 +1,400 @@
+package usecase
+
+import (
+	"context"
+	"regexp"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/model/converter"
+	"streamhelper-backend/internal/moderation"
+	"streamhelper-backend/internal/repository"
+	"streamhelper-backend/internal/tracing"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// MessageFilterUseCase finds blocked words in donation messages, from the
+// global wordlist and the channel's own words and rules, and keeps the audit
+// of what was filtered. What happens to a message is decided by the
+// channel's filter action, see DonationUseCase.Create.
+type MessageFilterUseCase struct {
+	TxManager                 repository.TransactionManager
+	Log                       *logrus.Logger
+	Validate                  *validator.Validate
+	ChannelRepository         ChannelRepository
+	BlockedTermRepository     BlockedTermRepository
+	FilteredMessageRepository FilteredMessageRepository
+	// Words is the global wordlist every channel starts with.
+	Words []string
+}
+
+func NewMessageFilterUseCase(txManager repository.TransactionManager, logger *logrus.Logger, validate *validator.Validate,
+	channelRepository ChannelRepository, blockedTermRepository BlockedTermRepository,
+	filteredMessageRepository FilteredMessageRepository, words []string) *MessageFilterUseCase {
+	return &MessageFilterUseCase{
+		TxManager:                 txManager,
+		Log:                       logger,
+		Validate:                  validate,
+		ChannelRepository:         channelRepository,
+		BlockedTermRepository:     blockedTermRepository,
+		FilteredMessageRepository: filteredMessageRepository,
+		Words:                     words,
+	}
+}
+
+// Filter masks the blocked words in a message to a channel.
+func (c *MessageFilterUseCase) Filter(ctx context.Context, request *model.FilterMessageRequest) (*model.FilterMessageResponse, error) {
+	ctx, span := tracing.Start(ctx, "MessageFilterUseCase.Filter")
+	defer span.End()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	if strings.TrimSpace(request.Text) == "" {
+		return &model.FilterMessageResponse{Text: request.Text}, nil
+	}
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	filter, err := c.filter(ctx, tx.DB(), request.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	result := filter.Check(request.Text)
+	return &model.FilterMessageResponse{
+		Text:    result.Text,
+		Matches: result.Matches,
+	}, nil
+}
+
+// Check runs the filter of the authenticated user's channel on a text, so the
+// streamer can try their words and rules.
+func (c *MessageFilterUseCase) Check(ctx context.Context, request *model.CheckMessageRequest) (*model.FilterMessageResponse, error) {
+	ctx, span := tracing.Start(ctx, "MessageFilterUseCase.Check")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, request.UserID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	filter, err := c.filter(ctx, tx.DB(), channel.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	result := filter.Check(request.Text)
+	response := &model.FilterMessageResponse{
+		Text:    result.Text,
+		Matches: result.Matches,
+	}
+	if result.Blocked() {
+		response.Action = channel.FilterAction
+	}
+	return response, nil
+}
+
+func (c *MessageFilterUseCase) GetSettings(ctx context.Context, request *model.GetMessageFilterRequest) (*model.MessageFilterSettingsResponse, error) {
+	ctx, span := tracing.Start(ctx, "MessageFilterUseCase.GetSettings")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, request.UserID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.MessageFilterSettingsToResponse(channel), nil
+}
+
+// UpdateSettings changes the filter action. It applies to donations made
+// afterwards.
+func (c *MessageFilterUseCase) UpdateSettings(ctx context.Context, request *model.UpdateMessageFilterSettingsRequest) (*model.MessageFilterSettingsResponse, error) {
+	ctx, span := tracing.Start(ctx, "MessageFilterUseCase.UpdateSettings")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, request.UserID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	channel.FilterAction = request.Action
+	if err := c.ChannelRepository.Update(tx.DB(), channel); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed save channel : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.MessageFilterSettingsToResponse(channel), nil
+}
+
+func (c *MessageFilterUseCase) ListTerms(ctx context.Context, request *model.GetMessageFilterRequest) ([]model.BlockedTermResponse, error) {
+	ctx, span := tracing.Start(ctx, "MessageFilterUseCase.ListTerms")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, request.UserID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	terms, err := c.BlockedTermRepository.FindAllByChannelId(tx.DB(), channel.ID)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find blocked terms : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.BlockedTermsToResponse(terms), nil
+}
+
+// CreateTerm blocks a word or a regular expression in the channel's donation
+// messages. Words are stored lowercase.
+func (c *MessageFilterUseCase) CreateTerm(ctx context.Context, request *model.CreateBlockedTermRequest) (*model.BlockedTermResponse, error) {
+	ctx, span := tracing.Start(ctx, "MessageFilterUseCase.CreateTerm")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	request.Pattern = strings.TrimSpace(request.Pattern)
+	if request.Kind == entity.BlockedTermWord {
+		request.Pattern = strings.ToLower(request.Pattern)
+	}
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	switch request.Kind {
+	case entity.BlockedTermWord:
+		if !moderation.IsWord(request.Pattern) {
+			return nil, model.ErrInvalidFilterRule
+		}
+	case entity.BlockedTermRegex:
+		if _, err := moderation.CompileRule(request.Pattern); err != nil {
+			c.Log.WithContext(ctx).Warnf("Invalid filter rule : %+v", err)
+			return nil, model.ErrInvalidFilterRule
+		}
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, request.UserID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	total, err := c.BlockedTermRepository.CountByChannelId(tx.DB(), channel.ID)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed count blocked terms : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if total >= entity.MaxBlockedTerms {
+		return nil, model.ErrBlockedTermLimit
+	}
+
+	total, err = c.BlockedTermRepository.CountByPattern(tx.DB(), channel.ID, request.Kind, request.Pattern)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed count blocked terms by pattern : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	if total > 0 {
+		return nil, model.ErrBlockedTermExists
+	}
+
+	term := &entity.BlockedTerm{
+		ID:        uuid.NewString(),
+		ChannelID: channel.ID,
+		Kind:      request.Kind,
+		Pattern:   request.Pattern,
+	}
+	if err := c.BlockedTermRepository.Create(tx.DB(), term); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed create blocked term : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.BlockedTermToResponse(term), nil
+}
+
+func (c *MessageFilterUseCase) DeleteTerm(ctx context.Context, request *model.DeleteBlockedTermRequest) (bool, error) {
+	ctx, span := tracing.Start(ctx, "MessageFilterUseCase.DeleteTerm")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return false, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, request.UserID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return false, model.ErrChannelNotFound
+	}
+
+	term := new(entity.BlockedTerm)
+	if err := c.BlockedTermRepository.FindByIdAndChannelId(tx.DB(), term, request.TermID, channel.ID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find blocked term : %+v", err)
+		return false, model.ErrBlockedTermNotFound
+	}
+
+	if err := c.BlockedTermRepository.Delate(tx.DB(), term); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed delete blocked term : %+v", err)
+		return false, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return false, fiber.ErrInternalServerError
+	}
+
+	return true, nil
+}
+
+// SearchAudit returns one page of the messages the channel's filter acted
+// on, newest first.
+func (c *MessageFilterUseCase) SearchAudit(ctx context.Context, request *model.SearchFilteredMessageRequest) (*model.PageResponse[model.FilteredMessageResponse], error) {
+	ctx, span := tracing.Start(ctx, "MessageFilterUseCase.SearchAudit")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, request.UserID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	messages, total, err := c.FilteredMessageRepository.Search(tx.DB(), channel.ID, request)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed search filtered messages : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	responses := make([]model.FilteredMessageResponse, len(messages))
+	for i := range messages {
+		responses[i] = *converter.FilteredMessageToResponse(&messages[i])
+	}
+
+	return &model.PageResponse[model.FilteredMessageResponse]{
+		Data: responses,
+		PageMetadata: model.PageMetadata{
+			Page:      request.Page,
+			Size:      request.Size,
+			TotalItem: total,
+			TotalPage: (total + int64(request.Size) - 1) / int64(request.Size),
+		},
+	}, nil
+}
+
+// filter builds the filter of a channel from the global wordlist and its
+// own terms.
+func (c *MessageFilterUseCase) filter(ctx context.Context, db *gorm.DB, channelID string) (*moderation.Filter, error) {
+	terms, err := c.BlockedTermRepository.FindAllByChannelId(db, channelID)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find blocked terms : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	words := append([]string(nil), c.Words...)
+	var rules []*regexp.Regexp
+	for _, term := range terms {
+		switch term.Kind {
+		case entity.BlockedTermWord:
+			words = append(words, term.Pattern)
+		case entity.BlockedTermRegex:
+			rule, err := moderation.CompileRule(term.Pattern)
+			if err != nil {
+				c.Log.WithContext(ctx).Warnf("Skipping invalid filter rule %s : %+v", term.ID, err)
+				continue
+			}
+			rules = append(rules, rule)
+		}
+	}
+
+	return moderation.NewFilter(words, rules), nil
+}
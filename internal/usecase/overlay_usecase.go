@@ -0,0 +1,254 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/event"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/repository"
+	"streamhelper-backend/internal/tracing"
+	"streamhelper-backend/internal/util"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// overlayKeyBytes is the entropy of an overlay key, 32 URL-safe characters.
+const overlayKeyBytes = 24
+
+// OverlaySession is one connected overlay. Replay holds the events it missed,
+// oldest first, then Subscription delivers the live ones. Live events may
+// repeat the end of Replay and must be skipped by ID. Gap is set when it
+// missed more than Replay holds.
+type OverlaySession struct {
+	ChannelID    string
+	Client       string
+	LastEventID  string
+	Replay       []event.Event
+	Gap          bool
+	Subscription *event.Subscription
+}
+
+type OverlayUseCase struct {
+	TxManager         repository.TransactionManager
+	Log               *logrus.Logger
+	Validate          *validator.Validate
+	ChannelRepository ChannelRepository
+	Hub               EventHub
+	// ReplayLimit caps how many missed events are replayed on connect.
+	ReplayLimit int64
+	// Buffer is how many live events a session may fall behind before it
+	// is disconnected.
+	Buffer int
+}
+
+func NewOverlayUseCase(txManager repository.TransactionManager, logger *logrus.Logger, validate *validator.Validate,
+	channelRepository ChannelRepository, hub EventHub, replayLimit int64, buffer int) *OverlayUseCase {
+	return &OverlayUseCase{
+		TxManager:         txManager,
+		Log:               logger,
+		Validate:          validate,
+		ChannelRepository: channelRepository,
+		Hub:               hub,
+		ReplayLimit:       replayLimit,
+		Buffer:            buffer,
+	}
+}
+
+// Get returns the overlay key of the authenticated user's channel.
+func (c *OverlayUseCase) Get(ctx context.Context, request *model.GetOverlayRequest) (*model.OverlayResponse, error) {
+	ctx, span := tracing.Start(ctx, "OverlayUseCase.Get")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, request.UserID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return &model.OverlayResponse{ChannelID: channel.ID, Key: channel.OverlayKey}, nil
+}
+
+// RotateKey replaces the overlay key, for when a browser source URL leaked.
+// Overlays connected with the old key are disconnected, the dashboard feed
+// stays open.
+func (c *OverlayUseCase) RotateKey(ctx context.Context, request *model.GetOverlayRequest) (*model.OverlayResponse, error) {
+	ctx, span := tracing.Start(ctx, "OverlayUseCase.RotateKey")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, request.UserID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	oldKey := channel.OverlayKey
+	channel.OverlayKey = util.RandomToken(overlayKeyBytes)
+	if err := c.ChannelRepository.Update(tx.DB(), channel); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed update channel : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	// the key is rotated either way, a failed revocation only leaves the old
+	// overlays connected until they reconnect
+	if err := c.Hub.Revoke(ctx, channel.ID, sessionKey(oldKey)); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed revoke overlay sessions : %+v", err)
+	}
+
+	return &model.OverlayResponse{ChannelID: channel.ID, Key: channel.OverlayKey}, nil
+}
+
+// Connect authenticates an overlay by its key and opens a session that
+// resumes after LastEventID, or after the client's last acknowledged event.
+// The caller must close the session's subscription.
+func (c *OverlayUseCase) Connect(ctx context.Context, request *model.ConnectOverlayRequest) (*OverlaySession, error) {
+	ctx, span := tracing.Start(ctx, "OverlayUseCase.Connect")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.ErrInvalidOverlayKey
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByOverlayKey(tx.DB(), channel, request.Key); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by overlay key : %+v", err)
+		return nil, model.ErrInvalidOverlayKey
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	lastEventID := request.LastEventID
+	if lastEventID == "" {
+		lastAck, err := c.Hub.LastAck(ctx, channel.ID, request.Client)
+		if err != nil {
+			c.Log.WithContext(ctx).Warnf("Failed get last acknowledged event : %+v", err)
+			return nil, fiber.ErrInternalServerError
+		}
+		lastEventID = lastAck
+	}
+
+	return c.open(ctx, channel.ID, sessionKey(channel.OverlayKey), request.Client, lastEventID)
+}
+
+// Feed opens a session on the authenticated user's channel for the
+// dashboard. It resumes after LastEventID, without it only new events are
+// delivered. The caller must close the session's subscription.
+func (c *OverlayUseCase) Feed(ctx context.Context, request *model.GetEventFeedRequest) (*OverlaySession, error) {
+	ctx, span := tracing.Start(ctx, "OverlayUseCase.Feed")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, request.UserID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return c.open(ctx, channel.ID, "", "", request.LastEventID)
+}
+
+func (c *OverlayUseCase) open(ctx context.Context, channelID string, key string, client string, lastEventID string) (*OverlaySession, error) {
+	// subscribe before reading the history, an event published in between
+	// then arrives twice rather than never
+	subscription, err := c.Hub.Subscribe(ctx, channelID, key, c.Buffer)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed subscribe to channel events : %+v", err)
+		return nil, fiber.ErrServiceUnavailable
+	}
+
+	replay, gap, err := c.Hub.Since(ctx, channelID, lastEventID, c.ReplayLimit)
+	if err != nil {
+		subscription.Close()
+		c.Log.WithContext(ctx).Warnf("Failed read missed events : %+v", err)
+		if errors.Is(err, event.ErrInvalidID) {
+			return nil, model.ErrInvalidEventID
+		}
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return &OverlaySession{
+		ChannelID:    channelID,
+		Client:       client,
+		LastEventID:  lastEventID,
+		Replay:       replay,
+		Gap:          gap,
+		Subscription: subscription,
+	}, nil
+}
+
+// sessionKey groups the sessions opened with an overlay key without passing
+// the key itself around.
+func sessionKey(overlayKey string) string {
+	sum := sha256.Sum256([]byte(overlayKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// Ack records that the client handled every event up to EventID.
+func (c *OverlayUseCase) Ack(ctx context.Context, request *model.AckOverlayRequest) error {
+	ctx, span := tracing.Start(ctx, "OverlayUseCase.Ack")
+	defer span.End()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return model.NewValidationError(err)
+	}
+
+	if err := c.Hub.Ack(ctx, request.ChannelID, request.Client, request.EventID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed acknowledge event : %+v", err)
+		if errors.Is(err, event.ErrInvalidID) {
+			return model.ErrInvalidEventID
+		}
+		return fiber.ErrInternalServerError
+	}
+
+	return nil
+}
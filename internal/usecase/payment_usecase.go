@@ -0,0 +1,332 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/metrics"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/model/converter"
+	"streamhelper-backend/internal/payment"
+	"streamhelper-backend/internal/repository"
+	"streamhelper-backend/internal/tracing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// PaymentUseCase keeps donations in step with their charges at the payment
+// provider, from callbacks, status polling and refunds.
+type PaymentUseCase struct {
+	TxManager          repository.TransactionManager
+	Log                *logrus.Logger
+	Validate           *validator.Validate
+	ChannelRepository  ChannelRepository
+	DonationRepository DonationRepository
+	PaymentRepository  PaymentRepository
+	Provider           PaymentProvider
+	Events             EventPublisher
+	Alerts             AlertEnqueuer
+	Speech             SpeechSubmitter
+	Goals              GoalTracker
+	Leaderboards       LeaderboardRecorder
+	Metrics            *metrics.Metrics
+}
+
+func NewPaymentUseCase(txManager repository.TransactionManager, logger *logrus.Logger, validate *validator.Validate,
+	channelRepository ChannelRepository, donationRepository DonationRepository, paymentRepository PaymentRepository,
+	provider PaymentProvider, events EventPublisher, alerts AlertEnqueuer, speech SpeechSubmitter,
+	goals GoalTracker, leaderboards LeaderboardRecorder, metrics *metrics.Metrics) *PaymentUseCase {
+	return &PaymentUseCase{
+		TxManager:          txManager,
+		Log:                logger,
+		Validate:           validate,
+		ChannelRepository:  channelRepository,
+		DonationRepository: donationRepository,
+		PaymentRepository:  paymentRepository,
+		Provider:           provider,
+		Events:             events,
+		Alerts:             alerts,
+		Speech:             speech,
+		Goals:              goals,
+		Leaderboards:       leaderboards,
+		Metrics:            metrics,
+	}
+}
+
+// Notify applies a provider callback. Callbacks that repeat or contradict an
+// already final status are acknowledged without changing anything, so the
+// provider stops retrying.
+func (c *PaymentUseCase) Notify(ctx context.Context, request *model.PaymentNotificationRequest) (bool, error) {
+	ctx, span := tracing.Start(ctx, "PaymentUseCase.Notify")
+	defer span.End()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return false, model.NewValidationError(err)
+	}
+
+	if request.Provider != c.Provider.Name() {
+		return false, model.ErrPaymentProviderNotFound
+	}
+
+	notification, err := c.Provider.VerifyNotification(ctx, request.Header, request.Body)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed verify payment notification : %+v", err)
+		if errors.Is(err, payment.ErrInvalidSignature) {
+			return false, model.ErrInvalidSignature
+		}
+		return false, fiber.ErrBadRequest
+	}
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	donation, donationPayment, err := c.find(ctx, tx, notification.OrderID, false)
+	if err != nil {
+		return false, err
+	}
+
+	if notification.Amount != donation.Amount {
+		c.Log.WithContext(ctx).Warnf("Payment notification amount %d does not match donation %s amount %d",
+			notification.Amount, donation.ID, donation.Amount)
+		return false, fiber.ErrBadRequest
+	}
+
+	changed, err := c.apply(ctx, tx, donation, donationPayment, notification)
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return false, fiber.ErrInternalServerError
+	}
+
+	if changed {
+		c.changed(ctx, donation, donationPayment)
+	}
+
+	return true, nil
+}
+
+// Get returns the donation with its payment instructions. While the donation
+// is pending the provider is asked for the latest status, in case a callback
+// was lost.
+func (c *PaymentUseCase) Get(ctx context.Context, request *model.GetDonationPaymentRequest) (*model.DonationResponse, error) {
+	ctx, span := tracing.Start(ctx, "PaymentUseCase.Get")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	donation, donationPayment, err := c.find(ctx, tx, request.DonationID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := false
+	if donation.Status == entity.DonationStatusPending {
+		// a provider outage must not break the tipping page, the stored
+		// status is still correct as far as we know
+		notification, err := c.Provider.Status(ctx, donation.ID)
+		if err != nil {
+			c.Log.WithContext(ctx).Warnf("Failed poll payment status : %+v", err)
+		} else if changed, err = c.apply(ctx, tx, donation, donationPayment, notification); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if changed {
+		c.changed(ctx, donation, donationPayment)
+	}
+
+	response := converter.DonationToResponse(donation)
+	response.Payment = converter.PaymentToResponse(donationPayment)
+	return response, nil
+}
+
+// Refund gives a paid donation back to the donor. Only the owner of the
+// channel that received it may refund it.
+func (c *PaymentUseCase) Refund(ctx context.Context, request *model.RefundDonationRequest) (*model.DonationResponse, error) {
+	ctx, span := tracing.Start(ctx, "PaymentUseCase.Refund")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, request.UserID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	// the donation stays locked while the provider refunds it, a second
+	// refund waits and finds it refunded
+	donation, donationPayment, err := c.find(ctx, tx, request.DonationID, true)
+	if err != nil {
+		return nil, err
+	}
+	if donation.ChannelID != channel.ID {
+		return nil, model.ErrDonationNotFound
+	}
+	if donation.Status != entity.DonationStatusPaid {
+		return nil, model.ErrDonationNotRefundable
+	}
+
+	notification, err := c.Provider.Refund(ctx, donation.ID, donation.Amount, request.Reason)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed refund payment : %+v", err)
+		if errors.Is(err, payment.ErrNotRefundable) {
+			return nil, model.ErrDonationNotRefundable
+		}
+		return nil, model.ErrPaymentProvider.Wrap(err)
+	}
+
+	changed, err := c.apply(ctx, tx, donation, donationPayment, notification)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if changed {
+		c.changed(ctx, donation, donationPayment)
+	}
+
+	return converter.DonationToResponse(donation), nil
+}
+
+// find loads the donation with its payment, with lock the donation stays
+// locked until the transaction ends.
+func (c *PaymentUseCase) find(ctx context.Context, tx repository.Transaction, donationId string, lock bool) (*entity.Donation, *entity.Payment, error) {
+	donation := new(entity.Donation)
+	var err error
+	if lock {
+		err = c.DonationRepository.FindByIdForUpdate(tx.DB(), donation, donationId)
+	} else {
+		err = c.DonationRepository.FindById(tx.DB(), donation, donationId)
+	}
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find donation by id : %+v", err)
+		return nil, nil, model.ErrDonationNotFound
+	}
+
+	donationPayment := new(entity.Payment)
+	if err := c.PaymentRepository.FindByDonationId(tx.DB(), donationPayment, donation.ID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find payment by donation : %+v", err)
+		return nil, nil, model.ErrDonationNotFound
+	}
+
+	return donation, donationPayment, nil
+}
+
+// apply moves the donation and its payment to the notified status and reports
+// whether anything changed. The donation only moves from the status it was
+// read in, so when a callback and a poll apply the same status at once only
+// one of them reports the change.
+func (c *PaymentUseCase) apply(ctx context.Context, tx repository.Transaction, donation *entity.Donation,
+	donationPayment *entity.Payment, notification *payment.Notification) (bool, error) {
+	if !donation.CanMoveTo(notification.Status) {
+		return false, nil
+	}
+
+	from := donation.Status
+	donation.Status = notification.Status
+	if donation.Status == entity.DonationStatusPaid {
+		donation.PaidAt = time.Now().UnixMilli()
+	}
+	updated, err := c.DonationRepository.UpdateStatus(tx.DB(), donation, from)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed update donation : %+v", err)
+		return false, fiber.ErrInternalServerError
+	}
+	if !updated {
+		return false, nil
+	}
+
+	donationPayment.Status = notification.Status
+	if notification.Reference != "" {
+		donationPayment.Reference = notification.Reference
+	}
+	if err := c.PaymentRepository.Update(tx.DB(), donationPayment); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed update payment : %+v", err)
+		return false, fiber.ErrInternalServerError
+	}
+
+	return true, nil
+}
+
+// changed reports a committed status change. Goals and leaderboards count
+// paid donations and drop refunded ones. A paid donation is published to the
+// channel's event stream and queued as an alert, after its message is read
+// aloud when the channel uses text-to-speech; the donation stays paid when
+// any of it fails, so failures are only logged.
+func (c *PaymentUseCase) changed(ctx context.Context, donation *entity.Donation, donationPayment *entity.Payment) {
+	c.Metrics.PaymentStatusChanged(donationPayment.Provider, donationPayment.Status)
+
+	if donation.Status == entity.DonationStatusPaid || donation.Status == entity.DonationStatusRefunded {
+		if err := c.Goals.Track(ctx, &model.TrackGoalRequest{DonationID: donation.ID}); err != nil {
+			c.Log.WithContext(ctx).Warnf("Failed track donation goals : %+v", err)
+		}
+		if err := c.Leaderboards.Record(ctx, &model.RecordLeaderboardRequest{DonationID: donation.ID}); err != nil {
+			c.Log.WithContext(ctx).Warnf("Failed record donation on leaderboards : %+v", err)
+		}
+	}
+
+	if donation.Status != entity.DonationStatusPaid {
+		return
+	}
+	donationEvent := converter.DonationToEvent(donation)
+	published := donationEvent
+	if donation.Held {
+		// a held message is only shown once the streamer approves its alert
+		hidden := *donationEvent
+		hidden.Message = ""
+		published = &hidden
+	}
+	if _, err := c.Events.Publish(ctx, donation.ChannelID, model.EventTypeDonation, published); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed publish donation event : %+v", err)
+	}
+
+	if donation.Message != "" {
+		// the speech worker queues the alert once the message is read aloud
+		submitted, err := c.Speech.Submit(ctx, &model.SubmitSpeechRequest{ChannelID: donation.ChannelID, DonationID: donation.ID})
+		if err != nil {
+			c.Log.WithContext(ctx).Warnf("Failed submit donation speech : %+v", err)
+		}
+		if submitted {
+			return
+		}
+	}
+
+	_, err := c.Alerts.Enqueue(ctx, &model.EnqueueAlertRequest{
+		ChannelID: donation.ChannelID,
+		Type:      model.EventTypeDonation,
+		Data:      donationEvent,
+		Hold:      donation.Held,
+	})
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed enqueue donation alert : %+v", err)
+	}
+}
@@ -0,0 +1,338 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/model/converter"
+	"streamhelper-backend/internal/repository"
+	"streamhelper-backend/internal/speech"
+	"streamhelper-backend/internal/tracing"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// SpeechUseCase reads donation messages aloud. Paid donations of channels
+// using text-to-speech become jobs, and Tick synthesizes them and queues
+// their alert with the audio. A job that keeps failing still queues its
+// alert, without audio, so no donation goes unnoticed.
+type SpeechUseCase struct {
+	TxManager          repository.TransactionManager
+	Log                *logrus.Logger
+	Validate           *validator.Validate
+	ChannelRepository  ChannelRepository
+	DonationRepository DonationRepository
+	Synthesizer        Synthesizer
+	Storage            SpeechStorage
+	Queue              SpeechQueue
+	Alerts             AlertEnqueuer
+	// Batch is how many jobs one Tick takes on, Attempts how often a job is
+	// tried before its alert is queued without audio.
+	Batch    int
+	Attempts int
+}
+
+func NewSpeechUseCase(txManager repository.TransactionManager, logger *logrus.Logger, validate *validator.Validate,
+	channelRepository ChannelRepository, donationRepository DonationRepository, synthesizer Synthesizer,
+	storage SpeechStorage, queue SpeechQueue, alerts AlertEnqueuer, batch int, attempts int) *SpeechUseCase {
+	return &SpeechUseCase{
+		TxManager:          txManager,
+		Log:                logger,
+		Validate:           validate,
+		ChannelRepository:  channelRepository,
+		DonationRepository: donationRepository,
+		Synthesizer:        synthesizer,
+		Storage:            storage,
+		Queue:              queue,
+		Alerts:             alerts,
+		Batch:              batch,
+		Attempts:           attempts,
+	}
+}
+
+// Submit queues a job for a paid donation when its channel reads messages
+// aloud and reports whether it did. The job queues the donation's alert, the
+// caller must not.
+func (c *SpeechUseCase) Submit(ctx context.Context, request *model.SubmitSpeechRequest) (bool, error) {
+	ctx, span := tracing.Start(ctx, "SpeechUseCase.Submit")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return false, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindById(tx.DB(), channel, request.ChannelID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by id : %+v", err)
+		return false, model.ErrChannelNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return false, fiber.ErrInternalServerError
+	}
+
+	if !channel.TTSEnabled {
+		return false, nil
+	}
+
+	if err := c.Queue.Push(ctx, &speech.Job{ChannelID: channel.ID, DonationID: request.DonationID}); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed push speech job : %+v", err)
+		return false, fiber.ErrInternalServerError
+	}
+	return true, nil
+}
+
+// Tick works through a batch of jobs. It is run by the speech worker, on any
+// number of instances at once.
+func (c *SpeechUseCase) Tick(ctx context.Context, now time.Time) error {
+	ctx, span := tracing.Start(ctx, "SpeechUseCase.Tick")
+	defer span.End()
+
+	jobs, err := c.Queue.Reserve(ctx, now, c.Batch)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed reserve speech jobs : %+v", err)
+		return err
+	}
+
+	for i := range jobs {
+		job := &jobs[i]
+
+		err := c.process(ctx, job, true)
+		if err != nil && job.Attempts+1 < c.Attempts {
+			c.Log.WithContext(ctx).Warnf("Failed speech job of donation %s, retrying : %+v", job.DonationID, err)
+			if err := c.Queue.Retry(ctx, job); err != nil {
+				c.Log.WithContext(ctx).Warnf("Failed retry speech job : %+v", err)
+			}
+			continue
+		}
+		if err != nil {
+			c.Log.WithContext(ctx).Warnf("Failed speech job of donation %s, alerting without audio : %+v", job.DonationID, err)
+			if err := c.process(ctx, job, false); err != nil {
+				c.Log.WithContext(ctx).Warnf("Failed alert donation %s : %+v", job.DonationID, err)
+			}
+		}
+
+		if err := c.Queue.Done(ctx, job); err != nil {
+			c.Log.WithContext(ctx).Warnf("Failed finish speech job : %+v", err)
+		}
+	}
+
+	return nil
+}
+
+// GetSettings returns how the authenticated user's channel reads messages
+// aloud.
+func (c *SpeechUseCase) GetSettings(ctx context.Context, request *model.GetSpeechSettingsRequest) (*model.SpeechSettingsResponse, error) {
+	ctx, span := tracing.Start(ctx, "SpeechUseCase.GetSettings")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, request.UserID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.SpeechSettingsToResponse(channel), nil
+}
+
+// UpdateSettings changes only the settings that are sent. The voice must be
+// one of the engine's and speak the language, see ListVoices. They apply to
+// donations paid afterwards.
+func (c *SpeechUseCase) UpdateSettings(ctx context.Context, request *model.UpdateSpeechSettingsRequest) (*model.SpeechSettingsResponse, error) {
+	ctx, span := tracing.Start(ctx, "SpeechUseCase.UpdateSettings")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindByUserId(tx.DB(), channel, request.UserID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find channel by user : %+v", err)
+		return nil, model.ErrChannelNotFound
+	}
+
+	if request.Enabled != nil {
+		channel.TTSEnabled = *request.Enabled
+	}
+	if request.MaxLength != nil {
+		channel.TTSMaxLength = *request.MaxLength
+	}
+	if request.Voice != nil || request.Language != nil {
+		voiceID, language := channel.TTSVoice, channel.TTSLanguage
+		if request.Language != nil {
+			voiceID, language = "", *request.Language
+		}
+		if request.Voice != nil {
+			voiceID = *request.Voice
+		}
+
+		voice, err := speech.FindVoice(c.Synthesizer.Voices(), voiceID, language)
+		if err != nil || (request.Language != nil && voice.Language != language) {
+			c.Log.WithContext(ctx).Warnf("Unsupported voice %q for language %q", voiceID, language)
+			return nil, model.ErrSpeechVoiceNotSupported
+		}
+		channel.TTSVoice = voice.ID
+		channel.TTSLanguage = voice.Language
+	}
+
+	if err := c.ChannelRepository.Update(tx.DB(), channel); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed save channel : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.SpeechSettingsToResponse(channel), nil
+}
+
+// ListVoices returns the voices of the speech engine.
+func (c *SpeechUseCase) ListVoices(ctx context.Context, request *model.GetSpeechSettingsRequest) ([]model.SpeechVoiceResponse, error) {
+	ctx, span := tracing.Start(ctx, "SpeechUseCase.ListVoices")
+	defer span.End()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	return converter.SpeechVoicesToResponse(c.Synthesizer.Voices()), nil
+}
+
+// Audio returns stored speech for the overlay to play.
+func (c *SpeechUseCase) Audio(ctx context.Context, request *model.GetSpeechAudioRequest) (*model.SpeechAudioResponse, error) {
+	ctx, span := tracing.Start(ctx, "SpeechUseCase.Audio")
+	defer span.End()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	audio, err := c.Storage.Get(ctx, request.Name)
+	if errors.Is(err, speech.ErrNotFound) {
+		return nil, model.ErrSpeechAudioNotFound
+	}
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed get speech audio : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return &model.SpeechAudioResponse{Data: audio.Data, ContentType: audio.ContentType}, nil
+}
+
+// process queues the alert of a job's donation, with its message read aloud
+// when withSpeech is set and the channel still uses text-to-speech.
+func (c *SpeechUseCase) process(ctx context.Context, job *speech.Job, withSpeech bool) error {
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	donation := new(entity.Donation)
+	if err := c.DonationRepository.FindById(tx.DB(), donation, job.DonationID); err != nil {
+		return err
+	}
+	channel := new(entity.Channel)
+	if err := c.ChannelRepository.FindById(tx.DB(), channel, job.ChannelID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	var alertSpeech *model.AlertSpeech
+	text := speechText(donation.Message, channel.TTSMaxLength)
+	if withSpeech && channel.TTSEnabled && text != "" {
+		audio, err := c.Synthesizer.Synthesize(ctx, &speech.Request{
+			Text:     text,
+			Voice:    channel.TTSVoice,
+			Language: channel.TTSLanguage,
+		})
+		if err != nil {
+			return err
+		}
+
+		url, err := c.Storage.Put(ctx, donation.ID+"."+audio.Extension, audio)
+		if err != nil {
+			return err
+		}
+		alertSpeech = &model.AlertSpeech{URL: url, Duration: audio.Duration.Milliseconds()}
+	}
+
+	_, err := c.Alerts.Enqueue(ctx, &model.EnqueueAlertRequest{
+		ChannelID: donation.ChannelID,
+		Type:      model.EventTypeDonation,
+		Data:      converter.DonationToEvent(donation),
+		Hold:      donation.Held,
+		Speech:    alertSpeech,
+	})
+	return err
+}
+
+// speechText returns what is read aloud of a message. Words the filter
+// masked are left out, and the text is cut after the last whole word that
+// fits in maxLength characters.
+func speechText(message string, maxLength int) string {
+	var builder strings.Builder
+	length := 0
+	for _, word := range strings.Fields(message) {
+		if strings.Contains(word, "*") && strings.TrimFunc(word, isMaskRune) == "" {
+			continue
+		}
+
+		size := utf8.RuneCountInString(word)
+		if length > 0 {
+			size++
+		}
+		if length+size > maxLength {
+			if length == 0 {
+				// a single word longer than the limit is cut
+				builder.WriteString(string([]rune(word)[:maxLength]))
+			}
+			break
+		}
+
+		if length > 0 {
+			builder.WriteByte(' ')
+		}
+		builder.WriteString(word)
+		length += size
+	}
+	return builder.String()
+}
+
+func isMaskRune(r rune) bool {
+	return r == '*' || unicode.IsPunct(r)
+}
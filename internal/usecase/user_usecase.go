@@ -3,57 +3,64 @@ package usecase
 import (
 	"context"
 	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/metrics"
 	"streamhelper-backend/internal/model"
 	"streamhelper-backend/internal/model/converter"
 	"streamhelper-backend/internal/repository"
-	"streamhelper-backend/internal/util"
+	"streamhelper-backend/internal/tracing"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/crypto/bcrypt"
-	"gorm.io/gorm"
 )
 
 type UserUseCase struct {
-	DB 					*gorm.DB
+	TxManager			repository.TransactionManager
 	Log					*logrus.Logger
 	Validate			*validator.Validate
-	UserRepository		*repository.UserRepository
-	TokenUtil			*util.TokenUtil
+	UserRepository		UserRepository
+	TokenService		TokenService
+	PasswordHasher		PasswordHasher
+	Metrics				*metrics.Metrics
 }
 
-func NewUserUserCase(db *gorm.DB, logger *logrus.Logger, validate *validator.Validate, 
-					userRepository *repository.UserRepository, tokenUtil *util.TokenUtil) *UserUseCase{
+func NewUserUserCase(txManager repository.TransactionManager, logger *logrus.Logger, validate *validator.Validate, 
+					userRepository UserRepository, tokenService TokenService, passwordHasher PasswordHasher, metrics *metrics.Metrics) *UserUseCase{
 		return &UserUseCase{
-			DB: db,
+			TxManager: txManager,
 			Log: logger,
 			Validate: validate,
 			UserRepository: userRepository ,
-			TokenUtil: tokenUtil,
+			TokenService: tokenService,
+			PasswordHasher: passwordHasher,
+			Metrics: metrics,
 
 		}
 	}
 
 func (c *UserUseCase) Verify(ctx context.Context, request *model.VerifyUserRequest) (*model.Auth, error){
-	tx := c.DB.WithContext(ctx).Begin()
+	ctx, span := tracing.Start(ctx, "UserUseCase.Verify")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
 	defer tx.Rollback()
 
 	err := c.Validate.Struct(request)
 	if err != nil {
-		c.Log.Warnf("invalid request body : %+v", err)
-		return nil, fiber.ErrBadRequest
+		c.Log.WithContext(ctx).Warnf("invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
 	}
 
 	user := new(entity.User)
 
-	if err := c.UserRepository.FindByToken(tx, user, request.Token); err != nil {
-		c.Log.Warnf("Failed find user by token : %+v", err)
+	if err := c.UserRepository.FindByToken(tx.DB(), user, request.Token); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find user by token : %+v", err)
 		return nil, fiber.ErrNotFound
 	}
 
-	if err := tx.Commit().Error; err != nil {
-		c.Log.Warnf("Failed commit transaction : %+v", err)
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
 		return nil, fiber.ErrInternalServerError
 	}
 
@@ -62,114 +69,131 @@ func (c *UserUseCase) Verify(ctx context.Context, request *model.VerifyUserReque
 }
 
 func (c *UserUseCase) Create(ctx context.Context, request *model.RegisterUserRequest) (*model.UserResponse, error){
-	tx := c.DB.WithContext(ctx).Begin()
+	ctx, span := tracing.Start(ctx, "UserUseCase.Create")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
 	defer tx.Rollback()
 
 	err := c.Validate.Struct(request)
 	if err != nil {
-		c.Log.Warnf("Invalid request body : %+v", err)
-		return nil, fiber.ErrBadRequest
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
 	}
 
-	total, err := c.UserRepository.CountById(tx, request.ID)
+	total, err := c.UserRepository.CountById(tx.DB(), request.ID)
 	if err != nil {
-		c.Log.Warnf("Failed count user from database : %+v", err)
+		c.Log.WithContext(ctx).Warnf("Failed count user from database : %+v", err)
 		return  nil, fiber.ErrInternalServerError
 	}
 
 	if total > 0 {
-		c.Log.Warnf("User already exists : %+v", err)
-		return nil, fiber.ErrConflict
+		c.Log.WithContext(ctx).Warnf("User already exists : %+v", err)
+		return nil, model.ErrUserAlreadyExists
 	}
 
-	password ,err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	password, err := c.PasswordHasher.Hash(ctx, request.Password)
 	if err != nil {
-		c.Log.Warnf("Failed to generate bcrype hash : %+v", err)
+		c.Log.WithContext(ctx).Warnf("Failed to generate bcrype hash : %+v", err)
 		return nil, fiber.ErrInternalServerError
 	}
 
 	user := &entity.User{
 		ID: request.ID,
-		Password: string(password),
+		Password: password,
 		Name: request.Name,
+		Language: request.Language,
 	}
 
 
-	if err := c.UserRepository.Create(tx, user); err != nil {
-		c.Log.Warnf("Failed crete user to database : %+v ", err)
+	if err := c.UserRepository.Create(tx.DB(), user); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed crete user to database : %+v ", err)
 		return  nil, fiber.ErrInternalServerError
 	}
 
-	if err := tx.Commit().Error; err != nil {
-		c.Log.Warnf("Failed commit transaction : %+v", err)
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
 		return nil, fiber.ErrInternalServerError
 	}
 
+	c.Metrics.UserRegistered()
 	return  converter.UserToResponse(user),nil
 }
 
 func (c *UserUseCase) Login(ctx context.Context, request *model.LoginUserRequest) (*model.UserResponse, error) {
-	tx := c.DB.WithContext(ctx).Begin()
+	ctx, span := tracing.Start(ctx, "UserUseCase.Login")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
 	defer tx.Rollback()
 
 	if err := c.Validate.Struct(request); err != nil {
-		c.Log.Warnf("Failed request body : %+v", err)
-		return nil, fiber.ErrBadRequest
+		c.Log.WithContext(ctx).Warnf("Failed request body : %+v", err)
+		return nil, model.NewValidationError(err)
 	}
 
 	user := new(entity.User)
-	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
-		c.Log.Warnf("Failed find user by id : %+v", err)
-		return nil, fiber.ErrUnauthorized
+	if err := c.UserRepository.FindById(tx.DB(), user, request.ID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find user by id : %+v", err)
+		c.Metrics.LoginFailed()
+		return nil, model.ErrInvalidCredentials
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password)); err != nil {
-		c.Log.Warnf("Failed to compare user password with bcrype hash : %+v", err)
-		return nil, fiber.ErrUnauthorized
+	if err := c.PasswordHasher.Compare(ctx, user.Password, request.Password); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed to compare user password with bcrype hash : %+v", err)
+		c.Metrics.LoginFailed()
+		return nil, model.ErrInvalidCredentials
 	}
 
-	token , err := c.TokenUtil.CreateToken(ctx, &model.Auth{ID : user.ID})
+	if user.Disabled() {
+		c.Log.WithContext(ctx).Warnf("Disabled user tried to login : %s", user.ID)
+		c.Metrics.LoginFailed()
+		return nil, model.ErrUserDisabled
+	}
+
+	token , err := c.TokenService.CreateToken(ctx, &model.Auth{ID : user.ID})
 	if err != nil {
-		c.Log.Warnf("Failed creating token : %+v", err)
+		c.Log.WithContext(ctx).Warnf("Failed creating token : %+v", err)
 		return nil, fiber.ErrInternalServerError
 	}
 	
 	user.Token = token
-	if err := c.UserRepository.Update(tx, user); err != nil {
-		c.Log.Warnf("Failed save user : %+v", err)
+	if err := c.UserRepository.Update(tx.DB(), user); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed save user : %+v", err)
 		return nil, fiber.ErrInternalServerError
 	}
 	
-	if err := tx.Commit().Error; err != nil {
-		c.Log.Warnf("Failed commit transaction : %+v", err)
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
 		return nil, fiber.ErrInternalServerError
 	}
-	
-
-
 
+	c.Metrics.LoginSucceeded()
 	return converter.UserToTokenResponse(&entity.User{
 		Token: token,
 	}), nil
 }
 
 func (c *UserUseCase) Current(ctx context.Context, request *model.GetUserRequest) (*model.UserResponse, error) {
-	tx := c.DB.WithContext(ctx).Begin()
+	ctx, span := tracing.Start(ctx, "UserUseCase.Current")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
 	defer tx.Rollback()
 
 	if err := c.Validate.Struct(request); err != nil {
-		c.Log.Warnf("Invalid request body : %+v", err)
-		return nil, fiber.ErrBadRequest
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
 	}
 
 	user := new(entity.User)
-	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
-		c.Log.Warnf("Failed find user by id : %+v", err)
-		return nil, fiber.ErrNotFound
+	if err := c.UserRepository.FindById(tx.DB(), user, request.ID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find user by id : %+v", err)
+		return nil, model.ErrUserNotFound
 	}
 
-	if err := tx.Commit().Error; err != nil {
-		c.Log.Warnf("Failed commit transaction : %+v", err)
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
 		return nil, fiber.ErrInternalServerError
 	}
 
@@ -177,28 +201,31 @@ func (c *UserUseCase) Current(ctx context.Context, request *model.GetUserRequest
 }
 
 func (c *UserUseCase) Logout(ctx context.Context, request *model.LogoutUserRequest) (bool, error) {
-	tx := c.DB.WithContext(ctx).Begin()
+	ctx, span := tracing.Start(ctx, "UserUseCase.Logout")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
 	defer tx.Rollback()
 
 	if err := c.Validate.Struct(request); err != nil {
-		c.Log.Warnf("Invalid request body : %+v", err)
-		return false, fiber.ErrBadRequest
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return false, model.NewValidationError(err)
 	}
 
 	user := new(entity.User)
-	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
-		c.Log.Warnf("Failed find user by id : %+v", err)
-		return false, fiber.ErrNotFound
+	if err := c.UserRepository.FindById(tx.DB(), user, request.ID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find user by id : %+v", err)
+		return false, model.ErrUserNotFound
 	}
 
 	user.Token = ""
 
-	if err := c.UserRepository.Update(tx, user); err != nil {
-		c.Log.Warnf("Failed save user : %+v", err)
+	if err := c.UserRepository.Update(tx.DB(), user); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed save user : %+v", err)
 	}
 
-	if err := tx.Commit().Error; err != nil {
-		c.Log.Warnf("Failed commit transaction : %+v", err)
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
 		return false, fiber.ErrInternalServerError
 	}
 
@@ -206,41 +233,217 @@ func (c *UserUseCase) Logout(ctx context.Context, request *model.LogoutUserReque
 }
 
 func (c *UserUseCase) Update(ctx context.Context, request *model.UpdateUserRequest) (*model.UserResponse, error) {
-	tx := c.DB.WithContext(ctx).Begin()
+	ctx, span := tracing.Start(ctx, "UserUseCase.Update")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
 	defer tx.Rollback()
 
 	if err := c.Validate.Struct(request); err != nil {
-		c.Log.Warnf("Invalid request body : %+v", err)
-		return nil, fiber.ErrBadRequest
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
 	}
 
 	user := new(entity.User)
-	if err := c.UserRepository.FindById(tx, user, request.ID); err != nil {
-		c.Log.Warnf("Failed find user by id : %+v", err)
-		return nil, fiber.ErrNotFound
+	if err := c.UserRepository.FindById(tx.DB(), user, request.ID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find user by id : %+v", err)
+		return nil, model.ErrUserNotFound
 	}
 
 	if request.Name != "" {
 		user.Name = request.Name
 	}
 
+	if request.Language != "" {
+		user.Language = request.Language
+	}
+
 	if request.Password != "" {
-		password , err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+		password, err := c.PasswordHasher.Hash(ctx, request.Password)
 		if err != nil {
-			c.Log.Warnf("Failed to generate bcrype hash : %+v", err)
+			c.Log.WithContext(ctx).Warnf("Failed to generate bcrype hash : %+v", err)
 			return nil, fiber.ErrInternalServerError
 		}
-		user.Password = string(password)
+		user.Password = password
 	}
 
-	if err := c.UserRepository.Update(tx, user); err != nil {
-		c.Log.Warnf("Failed save user : %+v", err)
+	if err := c.UserRepository.Update(tx.DB(), user); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed save user : %+v", err)
 	}
 
-	if err := tx.Commit().Error; err != nil {
-		c.Log.Warnf("Failed commit transaction : %+v", err)
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
 		return nil, fiber.ErrInternalServerError
 	}
 
 	return converter.UserToResponse(user), nil
-}
\ No newline at end of file
+}
+
+// Language returns the preferred language stored on the user's profile, or
+// "" when none was chosen.
+func (c *UserUseCase) Language(ctx context.Context, id string) (string, error) {
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx.DB(), user, id); err != nil {
+		return "", err
+	}
+
+	return user.Language, nil
+}
+
+// Disable blocks the user from logging in and revokes every active session.
+func (c *UserUseCase) Disable(ctx context.Context, request *model.DisableUserRequest) (*model.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserUseCase.Disable")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx.DB(), user, request.ID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find user by id : %+v", err)
+		return nil, model.ErrUserNotFound
+	}
+
+	if !user.Disabled() {
+		user.DisabledAt = time.Now().UnixMilli()
+	}
+	user.Token = ""
+
+	if err := c.UserRepository.Update(tx.DB(), user); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed save user : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	// sessions are only revoked once the change is kept, calling again
+	// revokes whatever a failure here left
+	if _, err := c.TokenService.RevokeUserTokens(ctx, user.ID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed revoke user tokens : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.UserToResponse(user), nil
+}
+
+// ResetPassword sets a new password and revokes every active session.
+func (c *UserUseCase) ResetPassword(ctx context.Context, request *model.ResetPasswordRequest) (*model.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserUseCase.ResetPassword")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx.DB(), user, request.ID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find user by id : %+v", err)
+		return nil, model.ErrUserNotFound
+	}
+
+	password, err := c.PasswordHasher.Hash(ctx, request.Password)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed to generate bcrype hash : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+	user.Password = password
+	user.Token = ""
+
+	if err := c.UserRepository.Update(tx.DB(), user); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed save user : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	// sessions are only revoked once the change is kept, calling again
+	// revokes whatever a failure here left
+	if _, err := c.TokenService.RevokeUserTokens(ctx, user.ID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed revoke user tokens : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.UserToResponse(user), nil
+}
+
+func (c *UserUseCase) SetRole(ctx context.Context, request *model.SetUserRoleRequest) (*model.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserUseCase.SetRole")
+	defer span.End()
+
+	tx := c.TxManager.Begin(ctx)
+	defer tx.Rollback()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return nil, model.NewValidationError(err)
+	}
+
+	user := new(entity.User)
+	if err := c.UserRepository.FindById(tx.DB(), user, request.ID); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed find user by id : %+v", err)
+		return nil, model.ErrUserNotFound
+	}
+
+	user.Role = request.Role
+	if err := c.UserRepository.Update(tx.DB(), user); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed save user : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	if err := tx.Commit(); err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed commit transaction : %+v", err)
+		return nil, fiber.ErrInternalServerError
+	}
+
+	return converter.UserToResponse(user), nil
+}
+
+// RevokeTokens revokes a single token, or every token of a user when only
+// the user ID is given. It returns the number of tokens revoked.
+func (c *UserUseCase) RevokeTokens(ctx context.Context, request *model.RevokeTokenRequest) (int64, error) {
+	ctx, span := tracing.Start(ctx, "UserUseCase.RevokeTokens")
+	defer span.End()
+
+	if err := c.Validate.Struct(request); err != nil {
+		c.Log.WithContext(ctx).Warnf("Invalid request body : %+v", err)
+		return 0, model.NewValidationError(err)
+	}
+
+	if request.Token != "" {
+		revoked, err := c.TokenService.RevokeToken(ctx, request.Token)
+		if err != nil {
+			c.Log.WithContext(ctx).Warnf("Failed revoke token : %+v", err)
+			return 0, fiber.ErrInternalServerError
+		}
+		if !revoked {
+			return 0, nil
+		}
+		return 1, nil
+	}
+
+	revoked, err := c.TokenService.RevokeUserTokens(ctx, request.ID)
+	if err != nil {
+		c.Log.WithContext(ctx).Warnf("Failed revoke user tokens : %+v", err)
+		return 0, fiber.ErrInternalServerError
+	}
+
+	return revoked, nil
+}
@@ -0,0 +1,92 @@
+package util
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+type logFieldsKey struct{}
+
+// logFields is shared by every context derived from the request context, so
+// fields added late (the user ID after authentication) show up in all logs
+// written for that request.
+type logFields struct {
+	mu     sync.RWMutex
+	fields logrus.Fields
+}
+
+// WithLogFields returns a context carrying a fresh set of log fields.
+func WithLogFields(ctx context.Context, fields logrus.Fields) context.Context {
+	holder := &logFields{fields: logrus.Fields{}}
+	if parent, ok := ctx.Value(logFieldsKey{}).(*logFields); ok {
+		for key, value := range parent.snapshot() {
+			holder.fields[key] = value
+		}
+	}
+	for key, value := range fields {
+		holder.fields[key] = value
+	}
+
+	return context.WithValue(ctx, logFieldsKey{}, holder)
+}
+
+// AddLogField sets a field on the fields already carried by ctx. It does
+// nothing when ctx has none.
+func AddLogField(ctx context.Context, key string, value any) {
+	holder, ok := ctx.Value(logFieldsKey{}).(*logFields)
+	if !ok {
+		return
+	}
+
+	holder.mu.Lock()
+	defer holder.mu.Unlock()
+	holder.fields[key] = value
+}
+
+// LogFields returns a copy of the fields carried by ctx.
+func LogFields(ctx context.Context) logrus.Fields {
+	holder, ok := ctx.Value(logFieldsKey{}).(*logFields)
+	if !ok {
+		return logrus.Fields{}
+	}
+
+	return holder.snapshot()
+}
+
+func (h *logFields) snapshot() logrus.Fields {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	fields := make(logrus.Fields, len(h.fields))
+	for key, value := range h.fields {
+		fields[key] = value
+	}
+	return fields
+}
+
+// ContextFieldsHook adds the fields carried by entry.Context to the entry, so
+// log.WithContext(ctx) is all a component needs for request correlation.
+type ContextFieldsHook struct{}
+
+func NewContextFieldsHook() *ContextFieldsHook {
+	return &ContextFieldsHook{}
+}
+
+func (h *ContextFieldsHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *ContextFieldsHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+
+	for key, value := range LogFields(entry.Context) {
+		if _, exists := entry.Data[key]; !exists {
+			entry.Data[key] = value
+		}
+	}
+	return nil
+}
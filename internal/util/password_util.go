@@ -0,0 +1,37 @@
+package util
+
+import (
+	"context"
+	"streamhelper-backend/internal/tracing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type PasswordUtil struct {
+	Cost int
+}
+
+func NewPasswordUtil(cost int) *PasswordUtil {
+	return &PasswordUtil{
+		Cost: cost,
+	}
+}
+
+func (p *PasswordUtil) Hash(ctx context.Context, password string) (string, error) {
+	_, span := tracing.Start(ctx, "bcrypt.GenerateFromPassword")
+	defer span.End()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), p.Cost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+func (p *PasswordUtil) Compare(ctx context.Context, hash string, password string) error {
+	_, span := tracing.Start(ctx, "bcrypt.CompareHashAndPassword")
+	defer span.End()
+
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+}
@@ -0,0 +1,16 @@
+package util
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+)
+
+// RandomToken returns a URL-safe random string built from n random bytes.
+func RandomToken(n int) string {
+	buffer := make([]byte, n)
+	if _, err := rand.Read(buffer); err != nil {
+		// crypto/rand never fails on supported platforms
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buffer)
+}
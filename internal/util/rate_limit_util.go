@@ -0,0 +1,74 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindowScript keeps one sorted-set member per accepted request,
+// scored by its timestamp. Running it as a script makes the check-and-add
+// atomic across instances and prefork workers.
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+local member = ARGV[4]
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+if count < limit then
+	redis.call('ZADD', key, now, member)
+	redis.call('PEXPIRE', key, window)
+	return {1, limit - count - 1, window}
+end
+
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+local reset = window
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+return {0, 0, reset}
+`)
+
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the oldest counted request leaves the window.
+	Reset time.Duration
+}
+
+type RateLimiter struct {
+	Redis *redis.Client
+}
+
+func NewRateLimiter(redisClient *redis.Client) *RateLimiter {
+	return &RateLimiter{
+		Redis: redisClient,
+	}
+}
+
+// Allow counts one request against key and reports whether it fits within
+// limit requests per window.
+func (r *RateLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (*RateLimitResult, error) {
+	now := time.Now().UnixMilli()
+	member := fmt.Sprintf("%d-%d", now, rand.Int64())
+
+	values, err := slidingWindowScript.Run(ctx, r.Redis, []string{key},
+		now, window.Milliseconds(), limit, member).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	return &RateLimitResult{
+		Allowed:   values[0] == 1,
+		Limit:     limit,
+		Remaining: int(values[1]),
+		Reset:     time.Duration(values[2]) * time.Millisecond,
+	}, nil
+}
@@ -39,6 +39,15 @@ func (t TokenUtil) CreateToken(ctx context.Context, auth *model.Auth) (string, e
 		return  "", err
 	}
 
+	// index the token by user so every session can be revoked at once
+	userTokens := userTokensKey(auth.ID)
+	if err := t.Redis.SAdd(ctx, userTokens, jwtToken).Err(); err != nil {
+		return "", err
+	}
+	if err := t.Redis.Expire(ctx, userTokens, time.Hour*25*30).Err(); err != nil {
+		return "", err
+	}
+
 	return  jwtToken, nil
 }
 
@@ -73,4 +82,53 @@ func (t *TokenUtil) ParseToken(ctx context.Context, jwtToken string) (*model.Aut
 	}
 
 	return auth, nil
-}
\ No newline at end of file
+}
+
+// RevokeToken invalidates a single token. It returns false when the token
+// was unknown or already expired.
+func (t *TokenUtil) RevokeToken(ctx context.Context, jwtToken string) (bool, error) {
+	id, err := t.Redis.Get(ctx, jwtToken).Result()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := t.Redis.Del(ctx, jwtToken).Err(); err != nil {
+		return false, err
+	}
+	if err := t.Redis.SRem(ctx, userTokensKey(id), jwtToken).Err(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// RevokeUserTokens invalidates every token issued to a user and returns how
+// many were still active.
+func (t *TokenUtil) RevokeUserTokens(ctx context.Context, id string) (int64, error) {
+	userTokens := userTokensKey(id)
+	tokens, err := t.Redis.SMembers(ctx, userTokens).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	var revoked int64
+	if len(tokens) > 0 {
+		revoked, err = t.Redis.Del(ctx, tokens...).Result()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := t.Redis.Del(ctx, userTokens).Err(); err != nil {
+		return revoked, err
+	}
+
+	return revoked, nil
+}
+
+func userTokensKey(id string) string {
+	return "user_tokens:" + id
+}
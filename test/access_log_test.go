@@ -0,0 +1,48 @@
+package test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"streamhelper-backend/internal/delivery/http/middleware"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// logAccess sends one JSON request through the access log and returns what
+// it logged.
+func logAccess(t *testing.T, config middleware.AccessLogConfig) string {
+	output := new(bytes.Buffer)
+	log := logrus.New()
+	log.SetOutput(output)
+	log.SetLevel(logrus.DebugLevel)
+	log.SetFormatter(&logrus.JSONFormatter{})
+
+	app := fiber.New()
+	app.Use(middleware.NewAccessLog(log, config))
+	app.Post("/", func(ctx *fiber.Ctx) error { return ctx.SendStatus(fiber.StatusNoContent) })
+
+	request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"donor_name":"Nadia","password":"hayolo"}`))
+	request.Header.Set("Content-Type", "application/json")
+	_, err := app.Test(request, -1)
+	assert.Nil(t, err)
+
+	return output.String()
+}
+
+func TestAccessLogBody(t *testing.T) {
+	t.Parallel()
+
+	// bodies stay out of the logs even at debug level unless asked for
+	logged := logAccess(t, middleware.AccessLogConfig{SampleRate: 1, Redact: []string{"password"}})
+	assert.Contains(t, logged, `"msg":"access"`)
+	assert.NotContains(t, logged, "Nadia")
+
+	logged = logAccess(t, middleware.AccessLogConfig{SampleRate: 1, Redact: []string{"password"}, Body: true})
+	assert.Contains(t, logged, "Nadia")
+	assert.NotContains(t, logged, "hayolo")
+}
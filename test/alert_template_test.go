@@ -0,0 +1,194 @@
+package test
+
+import (
+	"net/http"
+	"streamhelper-backend/internal/alert"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createAlertTemplate(t *testing.T, env *Env, user *entity.User, body string) *model.AlertTemplateResponse {
+	response, template := sendJSON[*model.AlertTemplateResponse](t, env, user, http.MethodPost, "/api/users/_current/channel/alert-templates", body)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	return template.Data
+}
+
+func TestParseAlertTemplate(t *testing.T) {
+	template, err := alert.ParseTemplate("{{ donor }} tipped {{amount}}: {{message}}")
+	assert.Nil(t, err)
+	assert.Equal(t, "Nadia tipped Rp10.000: hi", template.Execute(map[string]string{
+		alert.VariableDonor:   "Nadia",
+		alert.VariableAmount:  "Rp10.000",
+		alert.VariableMessage: "hi",
+	}))
+
+	// values are inserted as text, never evaluated
+	template, err = alert.ParseTemplate("{{donor}}!")
+	assert.Nil(t, err)
+	assert.Equal(t, "{{amount}}!", template.Execute(map[string]string{alert.VariableDonor: "{{amount}}"}))
+
+	template, err = alert.ParseTemplate("no variables")
+	assert.Nil(t, err)
+	assert.Equal(t, "no variables", template.Execute(nil))
+
+	for _, text := range []string{"{{donor", "donor}}", "{{password}}", "{{.Donor}}", "{{}}", "{{donor | upper}}"} {
+		_, err := alert.ParseTemplate(text)
+		assert.ErrorIs(t, err, alert.ErrTemplateSyntax, text)
+	}
+
+	template, err = alert.ParseTemplate("{{message}}{{message}}")
+	assert.Nil(t, err)
+	rendered := template.Execute(map[string]string{alert.VariableMessage: strings.Repeat("a", alert.MaxRenderedLength)})
+	assert.Len(t, rendered, alert.MaxRenderedLength)
+}
+
+func TestFormatAlertAmount(t *testing.T) {
+	assert.Equal(t, "Rp0", alert.FormatAmount(0, "IDR"))
+	assert.Equal(t, "Rp999", alert.FormatAmount(999, "IDR"))
+	assert.Equal(t, "Rp10.000", alert.FormatAmount(10000, "IDR"))
+	assert.Equal(t, "Rp1.000.000", alert.FormatAmount(1000000, "idr"))
+	assert.Equal(t, "USD 12,345", alert.FormatAmount(12345, "usd"))
+}
+
+func TestAlertTemplateCrud(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+
+	created := createAlertTemplate(t, env, user, `{"name":"Big tip","event_type":"donation","min_amount":100000,"text":"WOW {{donor}} sent {{amount}}","image_url":"https://cdn.example.com/wow.gif","duration":10}`)
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, "Big tip", created.Name)
+	assert.Equal(t, int64(100000), created.MinAmount)
+	assert.Equal(t, entity.AnimationFade, created.Animation)
+	assert.Equal(t, 10, created.Duration)
+
+	response, found := sendJSON[*model.AlertTemplateResponse](t, env, user, http.MethodGet, "/api/users/_current/channel/alert-templates/"+created.ID, "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, created.Text, found.Data.Text)
+
+	response, updated := sendJSON[*model.AlertTemplateResponse](t, env, user, http.MethodPatch, "/api/users/_current/channel/alert-templates/"+created.ID, `{"animation":"bounce","image_url":""}`)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, entity.AnimationBounce, updated.Data.Animation)
+	assert.Empty(t, updated.Data.ImageURL)
+	assert.Equal(t, "Big tip", updated.Data.Name)
+
+	createAlertTemplate(t, env, user, `{"name":"Small tip","event_type":"donation","text":"{{donor}} sent {{amount}}"}`)
+
+	response, templates := sendJSON[[]model.AlertTemplateResponse](t, env, user, http.MethodGet, "/api/users/_current/channel/alert-templates", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Len(t, templates.Data, 2)
+	assert.Equal(t, "Small tip", templates.Data[0].Name)
+	assert.Equal(t, "Big tip", templates.Data[1].Name)
+
+	response, deleted := sendJSON[bool](t, env, user, http.MethodDelete, "/api/users/_current/channel/alert-templates/"+created.ID, "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.True(t, deleted.Data)
+
+	response, _ = sendJSON[*model.AlertTemplateResponse](t, env, user, http.MethodGet, "/api/users/_current/channel/alert-templates/"+created.ID, "")
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+func TestAlertTemplateInvalid(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+
+	for _, body := range []string{
+		`{"name":"Bad","event_type":"donation","text":"{{donor}} {{password}}"}`,
+		`{"name":"Bad","event_type":"donation","text":"{{donor"}`,
+		`{"name":"Bad","event_type":"follow","text":"{{donor}}"}`,
+		`{"name":"Bad","event_type":"donation","text":"{{donor}}","animation":"explode"}`,
+		`{"name":"Bad","event_type":"donation","text":"{{donor}}","sound_url":"javascript:alert(1)"}`,
+		`{"name":"Bad","event_type":"donation","text":"{{donor}}","duration":61}`,
+	} {
+		response, _ := sendJSON[*model.AlertTemplateResponse](t, env, user, http.MethodPost, "/api/users/_current/channel/alert-templates", body)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode, body)
+	}
+}
+
+func TestAlertTemplateTierTaken(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+
+	createAlertTemplate(t, env, user, `{"name":"Tier 1","event_type":"donation","min_amount":10000,"text":"{{donor}}"}`)
+	second := createAlertTemplate(t, env, user, `{"name":"Tier 2","event_type":"donation","min_amount":50000,"text":"{{donor}}"}`)
+
+	response, _ := sendJSON[*model.AlertTemplateResponse](t, env, user, http.MethodPost, "/api/users/_current/channel/alert-templates", `{"name":"Again","event_type":"donation","min_amount":10000,"text":"{{donor}}"}`)
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+
+	response, _ = sendJSON[*model.AlertTemplateResponse](t, env, user, http.MethodPatch, "/api/users/_current/channel/alert-templates/"+second.ID, `{"min_amount":10000}`)
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+
+	// keeping its own tier is fine
+	response, _ = sendJSON[*model.AlertTemplateResponse](t, env, user, http.MethodPatch, "/api/users/_current/channel/alert-templates/"+second.ID, `{"min_amount":50000,"name":"Tier two"}`)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
+
+func TestAlertTemplateSelectedByTier(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+
+	// without templates the default is used
+	PayDonation(t, env)
+	queue := getAlertQueue(t, env, user)
+	assert.Equal(t, "Nadia donated Rp25.000", queue.Queue[0].Display.Text)
+	assert.Empty(t, queue.Queue[0].Display.TemplateID)
+
+	createAlertTemplate(t, env, user, `{"name":"Small","event_type":"donation","text":"{{donor}} says thanks"}`)
+	medium := createAlertTemplate(t, env, user, `{"name":"Medium","event_type":"donation","min_amount":20000,"text":"{{donor}} tipped {{amount}}!","sound_url":"https://cdn.example.com/coin.mp3","animation":"zoom","duration":12}`)
+	createAlertTemplate(t, env, user, `{"name":"Large","event_type":"donation","min_amount":1000000,"text":"HUGE"}`)
+
+	donation := PayDonation(t, env)
+	queue = getAlertQueue(t, env, user)
+	assert.Len(t, queue.Queue, 2)
+	item := queue.Queue[1]
+	assert.Equal(t, donation.ID, item.ID)
+	assert.Equal(t, medium.ID, item.Display.TemplateID)
+	assert.Equal(t, "Nadia tipped Rp25.000!", item.Display.Text)
+	assert.Equal(t, "https://cdn.example.com/coin.mp3", item.Display.SoundURL)
+	assert.Equal(t, entity.AnimationZoom, item.Display.Animation)
+	assert.Equal(t, int64(12000), item.Duration)
+}
+
+func TestAlertTemplatePreview(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+
+	createAlertTemplate(t, env, user, `{"name":"Small","event_type":"donation","text":"{{donor}} says thanks","duration":2}`)
+	large := createAlertTemplate(t, env, user, `{"name":"Large","event_type":"donation","min_amount":1000000,"text":"{{donor}} sent {{amount}}: {{message}}","animation":"slide","duration":20}`)
+
+	response, display := sendJSON[*model.AlertDisplay](t, env, user, http.MethodPost, "/api/users/_current/channel/alert-templates/_preview", `{"event_type":"donation","donor":"Budi","amount":1500000,"message":"<b>hi</b>"}`)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, large.ID, display.Data.TemplateID)
+	assert.Equal(t, "Budi sent Rp1.500.000: <b>hi</b>", display.Data.Text)
+	assert.Equal(t, entity.AnimationSlide, display.Data.Animation)
+	assert.Equal(t, int64(20000), display.Data.Duration)
+
+	// the channel's minimum duration wins over a shorter template
+	response, display = sendJSON[*model.AlertDisplay](t, env, user, http.MethodPost, "/api/users/_current/channel/alert-templates/_preview", `{"event_type":"donation","donor":"Budi","amount":5000}`)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "Budi says thanks", display.Data.Text)
+	assert.Equal(t, int64(entity.DefaultAlertDuration*1000), display.Data.Duration)
+
+	response, display = sendJSON[*model.AlertDisplay](t, env, user, http.MethodPost, "/api/users/_current/channel/alert-templates/_preview", `{"event_type":"donation","donor":"Budi","amount":5000,"text":"{{currency}} {{amount}} from {{donor}}"}`)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Empty(t, display.Data.TemplateID)
+	assert.Equal(t, "IDR Rp5.000 from Budi", display.Data.Text)
+
+	response, _ = sendJSON[*model.AlertDisplay](t, env, user, http.MethodPost, "/api/users/_current/channel/alert-templates/_preview", `{"event_type":"donation","text":"{{secret}}"}`)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestAlertTemplateWithoutChannel(t *testing.T) {
+	env := NewEnv(t)
+	LoginUser(t, env)
+
+	user := new(entity.User)
+	err := env.DB.Where("id = ?", "Mousetri").First(user).Error
+	assert.Nil(t, err)
+
+	response, _ := sendJSON[[]model.AlertTemplateResponse](t, env, user, http.MethodGet, "/api/users/_current/channel/alert-templates", "")
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
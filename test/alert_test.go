@@ -0,0 +1,234 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"streamhelper-backend/internal/alert"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/payment"
+	"streamhelper-backend/internal/usecase"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// PayDonation creates a donation to the channel created by CreateChannel and
+// marks it paid, which queues its alert.
+func PayDonation(t *testing.T, env *Env) *model.DonationResponse {
+	donation := Donate(t, env)
+	response := SendSimulatorNotification(t, env, donation.ID, payment.StatusPaid)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	return donation
+}
+
+func getAlertQueue(t *testing.T, env *Env, user *entity.User) *model.AlertQueueResponse {
+	response, queue := sendJSON[*model.AlertQueueResponse](t, env, user, http.MethodGet, "/api/users/_current/channel/alerts", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	return queue.Data
+}
+
+func tickAlerts(t *testing.T, env *Env, now time.Time) {
+	err := env.Application.AlertUseCase.Tick(context.Background(), now)
+	assert.Nil(t, err)
+}
+
+func TestAlertQueue(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+	donation := PayDonation(t, env)
+
+	queue := getAlertQueue(t, env, user)
+	assert.Nil(t, queue.Current)
+	assert.Len(t, queue.Queue, 1)
+	assert.Equal(t, donation.ID, queue.Queue[0].ID)
+	assert.Equal(t, alert.StatusQueued, queue.Queue[0].Status)
+	assert.Equal(t, int64(entity.DefaultAlertDuration*1000), queue.Queue[0].Duration)
+
+	shown := new(model.DonationEvent)
+	assert.Nil(t, json.Unmarshal(queue.Queue[0].Data, shown))
+	assert.Equal(t, "Nadia", shown.DonorName)
+
+	now := time.Now()
+	tickAlerts(t, env, now)
+	queue = getAlertQueue(t, env, user)
+	assert.Equal(t, donation.ID, queue.Current.ID)
+	assert.Equal(t, alert.StatusShowing, queue.Current.Status)
+	assert.Equal(t, now.UnixMilli()+queue.Current.Duration, queue.Until)
+	assert.Empty(t, queue.Queue)
+
+	// the minimum duration has not passed yet
+	tickAlerts(t, env, now.Add(time.Second))
+	assert.NotNil(t, getAlertQueue(t, env, user).Current)
+
+	tickAlerts(t, env, now.Add(entity.DefaultAlertDuration*time.Second))
+	queue = getAlertQueue(t, env, user)
+	assert.Nil(t, queue.Current)
+	assert.Len(t, queue.History, 1)
+	assert.Equal(t, alert.StatusShown, queue.History[0].Status)
+
+	overlay := getOverlay(t, env, user, http.MethodGet, "/api/users/_current/channel/overlay")
+	events, _, err := env.Application.EventHub.Since(context.Background(), overlay.ChannelID, "0-0", 10)
+	assert.Nil(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, model.EventTypeDonation, events[0].Type)
+	assert.Equal(t, model.EventTypeAlert, events[1].Type)
+}
+
+func TestAlertModeration(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+
+	response, settings := sendJSON[*model.AlertSettingsResponse](t, env, user, http.MethodPatch, "/api/users/_current/channel/alerts/_settings", `{"moderation":true,"min_duration":10}`)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.True(t, settings.Data.Moderation)
+	assert.Equal(t, 10, settings.Data.MinDuration)
+
+	approved := PayDonation(t, env)
+	rejected := PayDonation(t, env)
+
+	queue := getAlertQueue(t, env, user)
+	assert.Len(t, queue.Review, 2)
+	assert.Empty(t, queue.Queue)
+
+	// nothing is shown before it is approved
+	tickAlerts(t, env, time.Now())
+	assert.Nil(t, getAlertQueue(t, env, user).Current)
+
+	response, item := sendJSON[*model.AlertResponse](t, env, user, http.MethodPost, "/api/users/_current/channel/alerts/"+approved.ID+"/_approve", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, alert.StatusQueued, item.Data.Status)
+	assert.Equal(t, int64(10000), item.Data.Duration)
+
+	response, _ = sendJSON[*model.AlertResponse](t, env, user, http.MethodPost, "/api/users/_current/channel/alerts/"+approved.ID+"/_approve", "")
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+
+	response, item = sendJSON[*model.AlertResponse](t, env, user, http.MethodPost, "/api/users/_current/channel/alerts/"+rejected.ID+"/_reject", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, alert.StatusRejected, item.Data.Status)
+
+	// a rejected alert was never shown, there is nothing to replay
+	response, _ = sendJSON[*model.AlertResponse](t, env, user, http.MethodPost, "/api/users/_current/channel/alerts/"+rejected.ID+"/_replay", "")
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+
+	tickAlerts(t, env, time.Now())
+	queue = getAlertQueue(t, env, user)
+	assert.Equal(t, approved.ID, queue.Current.ID)
+	assert.Empty(t, queue.Review)
+}
+
+func TestAlertSettingsInvalid(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+
+	response, _ := sendJSON[*model.AlertSettingsResponse](t, env, user, http.MethodPatch, "/api/users/_current/channel/alerts/_settings", `{"min_duration":0}`)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	response, settings := sendJSON[*model.AlertSettingsResponse](t, env, user, http.MethodGet, "/api/users/_current/channel/alerts/_settings", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.False(t, settings.Data.Moderation)
+	assert.Equal(t, entity.DefaultAlertDuration, settings.Data.MinDuration)
+}
+
+func TestAlertPause(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+	first := PayDonation(t, env)
+	PayDonation(t, env)
+
+	response, paused := sendJSON[*model.AlertQueueResponse](t, env, user, http.MethodPost, "/api/users/_current/channel/alerts/_pause", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.True(t, paused.Data.Paused)
+
+	tickAlerts(t, env, time.Now())
+	queue := getAlertQueue(t, env, user)
+	assert.Nil(t, queue.Current)
+	assert.Len(t, queue.Queue, 2)
+
+	response, resumed := sendJSON[*model.AlertQueueResponse](t, env, user, http.MethodPost, "/api/users/_current/channel/alerts/_resume", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.False(t, resumed.Data.Paused)
+
+	tickAlerts(t, env, time.Now())
+	queue = getAlertQueue(t, env, user)
+	assert.Equal(t, first.ID, queue.Current.ID)
+	assert.Len(t, queue.Queue, 1)
+}
+
+func TestAlertSkipAndReplay(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+	donation := PayDonation(t, env)
+
+	now := time.Now()
+	tickAlerts(t, env, now)
+
+	response, item := sendJSON[*model.AlertResponse](t, env, user, http.MethodPost, "/api/users/_current/channel/alerts/"+donation.ID+"/_skip", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, alert.StatusSkipped, item.Data.Status)
+
+	queue := getAlertQueue(t, env, user)
+	assert.Nil(t, queue.Current)
+	assert.Equal(t, donation.ID, queue.History[0].ID)
+
+	response, item = sendJSON[*model.AlertResponse](t, env, user, http.MethodPost, "/api/users/_current/channel/alerts/"+donation.ID+"/_replay", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, alert.StatusQueued, item.Data.Status)
+
+	tickAlerts(t, env, now.Add(time.Second))
+	queue = getAlertQueue(t, env, user)
+	assert.Equal(t, donation.ID, queue.Current.ID)
+	assert.Empty(t, queue.History)
+
+	overlay := getOverlay(t, env, user, http.MethodGet, "/api/users/_current/channel/overlay")
+	events, _, err := env.Application.EventHub.Since(context.Background(), overlay.ChannelID, "0-0", 10)
+	assert.Nil(t, err)
+	types := make([]string, len(events))
+	for i := range events {
+		types[i] = events[i].Type
+	}
+	assert.Equal(t, []string{model.EventTypeDonation, model.EventTypeAlert, model.EventTypeAlertSkipped, model.EventTypeAlert}, types)
+}
+
+func TestAlertNotFound(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+
+	response, _ := sendJSON[*model.AlertResponse](t, env, user, http.MethodPost, "/api/users/_current/channel/alerts/missing/_skip", "")
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+// Every instance runs the scheduler, and the queue lives in Redis, so a
+// restarted or second instance carries on where the first one stopped.
+func TestAlertQueueSharedByInstances(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+	for i := 0; i < 3; i++ {
+		PayDonation(t, env)
+	}
+
+	instances := make([]*usecase.AlertUseCase, 4)
+	for i := range instances {
+		instances[i] = usecase.NewAlertUseCase(env.Application.AlertUseCase.TxManager, env.Log, env.Validate,
+			env.Application.AlertUseCase.ChannelRepository, env.Application.AlertUseCase.TemplateRepository,
+			alert.NewQueue(env.Redis, env.Config.Alert.History), env.Application.EventHub)
+	}
+
+	now := time.Now()
+	var wg sync.WaitGroup
+	for _, instance := range instances {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Nil(t, instance.Tick(context.Background(), now))
+		}()
+	}
+	wg.Wait()
+
+	queue := getAlertQueue(t, env, user)
+	assert.NotNil(t, queue.Current)
+	assert.Len(t, queue.Queue, 2)
+}
@@ -0,0 +1,231 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/repository"
+	"streamhelper-backend/internal/usecase"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// CreateChannel logs Mousetri in and opens the "mousetri-live" channel.
+func CreateChannel(t *testing.T, env *Env) *entity.User {
+	LoginUser(t, env)
+
+	user := new(entity.User)
+	err := env.DB.Where("id = ?", "Mousetri").First(user).Error
+	assert.Nil(t, err)
+
+	requestBody := model.CreateChannelRequest{
+		Slug:        "Mousetri-Live",
+		DisplayName: "Mousetri Live",
+		Bio:         "Main game santai",
+		SocialLinks: []model.SocialLink{{Label: "Instagram", URL: "https://instagram.com/mousetri"}},
+		PlatformHandles: map[string]string{
+			entity.PlatformYouTube: "@mousetri",
+		},
+	}
+
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/channels", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", user.Token)
+
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.ChannelResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "mousetri-live", responseBody.Data.Slug)
+	assert.Equal(t, requestBody.DisplayName, responseBody.Data.DisplayName)
+	assert.NotEmpty(t, responseBody.Data.ID)
+
+	return user
+}
+
+func TestCreateChannel(t *testing.T) {
+	CreateChannel(t, NewEnv(t))
+}
+
+func TestCreateSecondChannel(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/channels", strings.NewReader(`{"slug":"another-one","display_name":"Another"}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", user.Token)
+
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.ChannelResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+	assert.Equal(t, model.ErrCodeChannelExists, responseBody.Code)
+}
+
+func TestCreateChannelSlugTaken(t *testing.T) {
+	env := NewEnv(t)
+	CreateChannel(t, env)
+
+	_, err := env.Application.UserUseCase.Create(context.Background(), &model.RegisterUserRequest{
+		ID: "Other", Password: "hayolo", Name: "Other",
+	})
+	assert.Nil(t, err)
+
+	_, err = env.Application.ChannelUseCase.Create(context.Background(), &model.CreateChannelRequest{
+		UserID: "Other", Slug: "mousetri-live", DisplayName: "Copycat",
+	})
+	assert.ErrorIs(t, err, model.ErrSlugTaken)
+}
+
+// racingChannelRepository counts nothing, like a request whose checks ran
+// before a concurrent one inserted its channel.
+type racingChannelRepository struct {
+	*repository.ChannelRepository
+}
+
+func (r racingChannelRepository) CountBySlug(db *gorm.DB, slug string) (int64, error) {
+	return 0, nil
+}
+
+func (r racingChannelRepository) CountByUserId(db *gorm.DB, userId string) (int64, error) {
+	return 0, nil
+}
+
+func TestCreateChannelConcurrently(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+	assert.Nil(t, env.DB.Create(&entity.User{ID: "Budi", Name: "Budi", Password: "secret", Language: "id"}).Error)
+
+	channelUseCase := usecase.NewChannelUseCase(repository.NewTransactionManager(env.DB), env.Log, env.Validate,
+		racingChannelRepository{repository.NewChannelRepository(env.Log)})
+	ctx := context.Background()
+
+	_, err := channelUseCase.Create(ctx, &model.CreateChannelRequest{UserID: user.ID, Slug: "another-one", DisplayName: "Another"})
+	assert.ErrorIs(t, err, model.ErrChannelExists)
+
+	_, err = channelUseCase.Create(ctx, &model.CreateChannelRequest{UserID: "Budi", Slug: "mousetri-live", DisplayName: "Budi"})
+	assert.ErrorIs(t, err, model.ErrSlugTaken)
+
+	_, err = channelUseCase.Create(ctx, &model.CreateChannelRequest{UserID: "Budi", Slug: "budi-live", DisplayName: "Budi"})
+	assert.Nil(t, err)
+
+	_, err = channelUseCase.Update(ctx, &model.UpdateChannelRequest{UserID: "Budi", Slug: "mousetri-live"})
+	assert.ErrorIs(t, err, model.ErrSlugTaken)
+}
+
+func TestCreateChannelInvalidSlug(t *testing.T) {
+	env := NewEnv(t)
+	LoginUser(t, env)
+
+	user := new(entity.User)
+	err := env.DB.Where("id = ?", "Mousetri").First(user).Error
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/channels", strings.NewReader(`{"slug":"no spaces!","display_name":"Bad","platform_handles":{"myspace":"x"}}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Accept-Language", "en")
+	request.Header.Set("Authorization", user.Token)
+
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.ChannelResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, model.ErrCodeValidation, responseBody.Code)
+	assert.Len(t, responseBody.Fields, 2)
+	assert.Equal(t, "slug", responseBody.Fields[0].Field)
+	assert.Equal(t, "slug must be 3 to 50 lowercase letters, digits or single dashes", responseBody.Fields[0].Message)
+}
+
+func TestGetChannel(t *testing.T) {
+	env := NewEnv(t)
+	CreateChannel(t, env)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/channels/mousetri-live", nil)
+	request.Header.Set("Accept", "application/json")
+
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.ChannelResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "Mousetri Live", responseBody.Data.DisplayName)
+	assert.Equal(t, "@mousetri", responseBody.Data.PlatformHandles[entity.PlatformYouTube])
+	assert.Len(t, responseBody.Data.SocialLinks, 1)
+}
+
+func TestGetChannelNotFound(t *testing.T) {
+	env := NewEnv(t)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/channels/nobody-here", nil)
+	request.Header.Set("Accept", "application/json")
+
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+func TestUpdateChannel(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+
+	request := httptest.NewRequest(http.MethodPatch, "/api/users/_current/channel", strings.NewReader(`{"slug":"mousetri","bio":""}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", user.Token)
+
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.ChannelResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "mousetri", responseBody.Data.Slug)
+	assert.Equal(t, "", responseBody.Data.Bio)
+	assert.Equal(t, "Mousetri Live", responseBody.Data.DisplayName)
+}
@@ -0,0 +1,250 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/payment"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateDonation(t *testing.T) {
+	env := NewEnv(t)
+	CreateChannel(t, env)
+
+	requestBody := model.CreateDonationRequest{
+		DonorName:     "Nadia",
+		Message:       "Semangat!",
+		Amount:        25000,
+		Anonymous:     true,
+		PaymentMethod: payment.MethodQRIS,
+	}
+
+	bodyJson, err := json.Marshal(requestBody)
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/channels/mousetri-live/donations", strings.NewReader(string(bodyJson)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.DonationResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.NotEmpty(t, responseBody.Data.ID)
+	assert.Equal(t, entity.DonationStatusPending, responseBody.Data.Status)
+	assert.Equal(t, entity.DefaultCurrency, responseBody.Data.Currency)
+	assert.Equal(t, requestBody.Amount, responseBody.Data.Amount)
+	assert.True(t, responseBody.Data.Anonymous)
+	assert.Equal(t, "simulator", responseBody.Data.Payment.Provider)
+	assert.Equal(t, payment.StatusPending, responseBody.Data.Payment.Status)
+	assert.NotEmpty(t, responseBody.Data.Payment.QRString)
+	assert.NotZero(t, responseBody.Data.Payment.ExpiresAt)
+}
+
+func TestCreateDonationBankTransfer(t *testing.T) {
+	env := NewEnv(t)
+	CreateChannel(t, env)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/channels/mousetri-live/donations", strings.NewReader(`{"donor_name":"Nadia","amount":25000,"payment_method":"bank_transfer","bank":"bca"}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.DonationResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, payment.BankBCA, responseBody.Data.Payment.Bank)
+	assert.Len(t, responseBody.Data.Payment.VANumber, 16)
+}
+
+func TestCreateDonationBankTransferWithoutBank(t *testing.T) {
+	env := NewEnv(t)
+	CreateChannel(t, env)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/channels/mousetri-live/donations", strings.NewReader(`{"donor_name":"Nadia","amount":25000,"payment_method":"bank_transfer"}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.DonationResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, model.ErrCodeValidation, responseBody.Code)
+	assert.Equal(t, "bank", responseBody.Fields[0].Field)
+}
+
+func TestCreateDonationChannelNotFound(t *testing.T) {
+	env := NewEnv(t)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/channels/nobody-here/donations", strings.NewReader(`{"donor_name":"Nadia","amount":25000,"payment_method":"qris"}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+func TestCreateDonationOutOfRange(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+
+	request := httptest.NewRequest(http.MethodPatch, "/api/users/_current/channel", strings.NewReader(`{"min_donation":5000,"max_donation":100000}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", user.Token)
+
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	for _, amount := range []int64{4999, 100001} {
+		request := httptest.NewRequest(http.MethodPost, "/api/channels/mousetri-live/donations", strings.NewReader(fmt.Sprintf(`{"donor_name":"Nadia","amount":%d,"payment_method":"qris"}`, amount)))
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Accept", "application/json")
+
+		response, err := env.Test(request)
+		assert.Nil(t, err)
+
+		bytes, err := io.ReadAll(response.Body)
+		assert.Nil(t, err)
+
+		responseBody := new(model.WebResponse[model.DonationResponse])
+		err = json.Unmarshal(bytes, responseBody)
+		assert.Nil(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+		assert.Equal(t, model.ErrCodeAmountOutOfRange, responseBody.Code)
+		assert.Equal(t, "amount", responseBody.Fields[0].Field)
+	}
+}
+
+func TestCreateDonationWrongCurrency(t *testing.T) {
+	env := NewEnv(t)
+	CreateChannel(t, env)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/channels/mousetri-live/donations", strings.NewReader(`{"donor_name":"Nadia","amount":25000,"currency":"usd","payment_method":"qris"}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[model.DonationResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, model.ErrCodeCurrency, responseBody.Code)
+}
+
+func TestListDonations(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+
+	for i := 1; i <= 12; i++ {
+		status := entity.DonationStatusPaid
+		if i%3 == 0 {
+			status = entity.DonationStatusPending
+		}
+		_, err := env.Application.DonationUseCase.Import(context.Background(), &model.ImportDonationRequest{
+			ID:        fmt.Sprintf("donation-%02d", i),
+			Slug:      "mousetri-live",
+			DonorName: "Nadia",
+			Amount:    int64(i) * 1000,
+			Status:    status,
+			CreatedAt: int64(i),
+		})
+		assert.Nil(t, err)
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/api/users/_current/channel/donations?page=2&size=5", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", user.Token)
+
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[[]model.DonationResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Len(t, responseBody.Data, 5)
+	assert.Equal(t, "donation-07", responseBody.Data[0].ID)
+	assert.Equal(t, 2, responseBody.Paging.Page)
+	assert.Equal(t, int64(12), responseBody.Paging.TotalItem)
+	assert.Equal(t, int64(3), responseBody.Paging.TotalPage)
+
+	request = httptest.NewRequest(http.MethodGet, "/api/users/_current/channel/donations?status=pending", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", user.Token)
+
+	response, err = env.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err = io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody = new(model.WebResponse[[]model.DonationResponse])
+	err = json.Unmarshal(bytes, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, int64(4), responseBody.Paging.TotalItem)
+}
+
+func TestListDonationsWithoutChannel(t *testing.T) {
+	env := NewEnv(t)
+	LoginUser(t, env)
+
+	user := new(entity.User)
+	err := env.DB.Where("id = ?", "Mousetri").First(user).Error
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/users/_current/channel/donations", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", user.Token)
+
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
@@ -0,0 +1,177 @@
+package test
+
+import (
+	"context"
+	"streamhelper-backend/internal/event"
+	"streamhelper-backend/internal/model"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// newHubs returns n hubs sharing one Redis, like n instances of the app.
+func newHubs(t *testing.T, n int) []*event.Hub {
+	t.Parallel()
+
+	log := logrus.New()
+	log.SetLevel(logrus.PanicLevel)
+	server := miniredis.RunT(t)
+
+	hubs := make([]*event.Hub, n)
+	for i := range hubs {
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		hubs[i] = event.NewHub(client, log, 100)
+		t.Cleanup(func() {
+			_ = hubs[i].Close()
+			_ = client.Close()
+		})
+	}
+	return hubs
+}
+
+func publishDonation(t *testing.T, hub *event.Hub, id string) *event.Event {
+	published, err := hub.Publish(context.Background(), "channel-1", model.EventTypeDonation, &model.DonationEvent{ID: id})
+	assert.Nil(t, err)
+	return published
+}
+
+func TestHubDeliversAcrossInstances(t *testing.T) {
+	hubs := newHubs(t, 2)
+
+	subscription, err := hubs[1].Subscribe(context.Background(), "channel-1", "", 8)
+	assert.Nil(t, err)
+	defer subscription.Close()
+
+	published := publishDonation(t, hubs[0], "donation-1")
+
+	select {
+	case received := <-subscription.Events():
+		assert.Equal(t, published.ID, received.ID)
+		assert.JSONEq(t, string(published.Data), string(received.Data))
+	case <-time.After(5 * time.Second):
+		t.Fatal("event not delivered")
+	}
+}
+
+func TestHubDropsSlowSubscriber(t *testing.T) {
+	hubs := newHubs(t, 1)
+
+	slow, err := hubs[0].Subscribe(context.Background(), "channel-1", "", 1)
+	assert.Nil(t, err)
+	fast, err := hubs[0].Subscribe(context.Background(), "channel-1", "", 8)
+	assert.Nil(t, err)
+	defer fast.Close()
+
+	publishDonation(t, hubs[0], "donation-1")
+	publishDonation(t, hubs[0], "donation-2")
+
+	assert.Eventually(t, func() bool { return len(fast.Events()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	<-slow.Events()
+	_, open := <-slow.Events()
+	assert.False(t, open)
+	assert.ErrorIs(t, slow.Err(), event.ErrSlowSubscriber)
+}
+
+func TestHubClose(t *testing.T) {
+	hubs := newHubs(t, 1)
+
+	subscription, err := hubs[0].Subscribe(context.Background(), "channel-1", "", 8)
+	assert.Nil(t, err)
+	assert.Nil(t, hubs[0].Close())
+
+	_, open := <-subscription.Events()
+	assert.False(t, open)
+	assert.ErrorIs(t, subscription.Err(), event.ErrHubClosed)
+
+	_, err = hubs[0].Subscribe(context.Background(), "channel-1", "", 8)
+	assert.ErrorIs(t, err, event.ErrHubClosed)
+}
+
+func TestHubRevoke(t *testing.T) {
+	hubs := newHubs(t, 2)
+
+	revoked, err := hubs[1].Subscribe(context.Background(), "channel-1", "old", 8)
+	assert.Nil(t, err)
+	kept, err := hubs[1].Subscribe(context.Background(), "channel-1", "", 8)
+	assert.Nil(t, err)
+	defer kept.Close()
+
+	assert.Nil(t, hubs[0].Revoke(context.Background(), "channel-1", "old"))
+
+	select {
+	case _, open := <-revoked.Events():
+		assert.False(t, open)
+		assert.ErrorIs(t, revoked.Err(), event.ErrRevoked)
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not revoked")
+	}
+
+	published := publishDonation(t, hubs[0], "donation-1")
+	select {
+	case received := <-kept.Events():
+		assert.Equal(t, published.ID, received.ID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event not delivered")
+	}
+}
+
+func TestHubSinceGap(t *testing.T) {
+	hubs := newHubs(t, 1)
+
+	ids := make([]string, 0, 3)
+	for _, id := range []string{"donation-1", "donation-2", "donation-3"} {
+		ids = append(ids, publishDonation(t, hubs[0], id).ID)
+	}
+
+	// the newest events are replayed, the client is told it missed the rest
+	events, gap, err := hubs[0].Since(context.Background(), "channel-1", "0-0", 2)
+	assert.Nil(t, err)
+	assert.True(t, gap)
+	assert.Len(t, events, 2)
+	assert.Equal(t, ids[1], events[0].ID)
+	assert.Equal(t, ids[2], events[1].ID)
+
+	events, gap, err = hubs[0].Since(context.Background(), "channel-1", "0-0", 3)
+	assert.Nil(t, err)
+	assert.False(t, gap)
+	assert.Len(t, events, 3)
+}
+
+func TestHubSinceAndAck(t *testing.T) {
+	hubs := newHubs(t, 1)
+	ctx := context.Background()
+
+	first := publishDonation(t, hubs[0], "donation-1")
+	second := publishDonation(t, hubs[0], "donation-2")
+	assert.True(t, event.After(second.ID, first.ID))
+
+	events, gap, err := hubs[0].Since(ctx, "channel-1", first.ID, 10)
+	assert.Nil(t, err)
+	assert.False(t, gap)
+	assert.Len(t, events, 1)
+	assert.Equal(t, second.ID, events[0].ID)
+	assert.Equal(t, model.EventTypeDonation, events[0].Type)
+
+	events, _, err = hubs[0].Since(ctx, "channel-1", "", 10)
+	assert.Nil(t, err)
+	assert.Empty(t, events)
+
+	_, _, err = hubs[0].Since(ctx, "channel-1", "yesterday", 10)
+	assert.ErrorIs(t, err, event.ErrInvalidID)
+
+	assert.Nil(t, hubs[0].Ack(ctx, "channel-1", "obs", second.ID))
+	// an acknowledgement arriving late must not move the client back
+	assert.Nil(t, hubs[0].Ack(ctx, "channel-1", "obs", first.ID))
+
+	lastAck, err := hubs[0].LastAck(ctx, "channel-1", "obs")
+	assert.Nil(t, err)
+	assert.Equal(t, second.ID, lastAck)
+
+	lastAck, err = hubs[0].LastAck(ctx, "channel-1", "browser")
+	assert.Nil(t, err)
+	assert.Empty(t, lastAck)
+}
@@ -0,0 +1,170 @@
+package test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/event"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/payment"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// streamMessage is one message of a text/event-stream response.
+type streamMessage struct {
+	ID    string
+	Event string
+	Data  string
+}
+
+// OpenStream sends a GET to a listening env and returns the response, whose
+// body is read by readStream.
+func OpenStream(t *testing.T, addr string, path string, header http.Header) *http.Response {
+	request, err := http.NewRequest(http.MethodGet, "http://"+addr+path, nil)
+	assert.Nil(t, err)
+	request.Header = header.Clone()
+	request.Header.Set("Accept", "text/event-stream")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("Failed to open stream : %+v", err)
+	}
+	t.Cleanup(func() { _ = response.Body.Close() })
+	return response
+}
+
+// readStream returns the next message, skipping comments.
+func readStream(t *testing.T, reader *bufio.Reader) *streamMessage {
+	done := make(chan *streamMessage, 1)
+	go func() {
+		defer close(done)
+		message := new(streamMessage)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimSuffix(line, "\n")
+			if line == "" {
+				if message.Event != "" || message.Data != "" {
+					done <- message
+					return
+				}
+				continue
+			}
+			field, value, _ := strings.Cut(line, ": ")
+			switch field {
+			case "id":
+				message.ID = value
+			case "event":
+				message.Event = value
+			case "data":
+				message.Data = value
+			}
+		}
+	}()
+
+	select {
+	case message, ok := <-done:
+		if !ok {
+			t.Fatal("stream ended")
+		}
+		return message
+	case <-time.After(5 * time.Second):
+		t.Fatal("no stream message")
+		return nil
+	}
+}
+
+func readStreamDonation(t *testing.T, reader *bufio.Reader) (*streamMessage, *model.DonationEvent) {
+	message := readStream(t, reader)
+	assert.Equal(t, model.EventTypeDonation, message.Event)
+
+	received := new(event.Event)
+	assert.Nil(t, json.Unmarshal([]byte(message.Data), received))
+	assert.Equal(t, message.ID, received.ID)
+
+	donation := new(model.DonationEvent)
+	assert.Nil(t, json.Unmarshal(received.Data, donation))
+	return message, donation
+}
+
+func TestOverlayStream(t *testing.T) {
+	env := NewEnv(t)
+	user, first := CreateDonation(t, env)
+	overlay := getOverlay(t, env, user, http.MethodGet, "/api/users/_current/channel/overlay")
+	addr := env.Listen(t)
+	path := "/api/overlay/events?" + url.Values{"key": {overlay.Key}}.Encode()
+
+	response := OpenStream(t, addr, path, http.Header{})
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+	reader := bufio.NewReader(response.Body)
+
+	hello := readStream(t, reader)
+	assert.Equal(t, model.OverlayOpHello, hello.Event)
+	assert.Contains(t, hello.Data, overlay.ChannelID)
+
+	SendSimulatorNotification(t, env, first.ID, payment.StatusPaid)
+	received, paid := readStreamDonation(t, reader)
+	assert.Equal(t, first.ID, paid.ID)
+	_ = response.Body.Close()
+
+	second := Donate(t, env)
+	SendSimulatorNotification(t, env, second.ID, payment.StatusPaid)
+
+	// EventSource reconnects with the id of the last event it saw
+	response = OpenStream(t, addr, path, http.Header{"Last-Event-ID": {received.ID}})
+	reader = bufio.NewReader(response.Body)
+	hello = readStream(t, reader)
+	assert.Contains(t, hello.Data, received.ID)
+
+	_, replayed := readStreamDonation(t, reader)
+	assert.Equal(t, second.ID, replayed.ID)
+}
+
+func TestOverlayStreamInvalidKey(t *testing.T) {
+	env := NewEnv(t)
+	addr := env.Listen(t)
+
+	response := OpenStream(t, addr, "/api/overlay/events?key=wrong", http.Header{})
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+}
+
+func TestEventFeed(t *testing.T) {
+	env := NewEnv(t)
+	user, donation := CreateDonation(t, env)
+	addr := env.Listen(t)
+
+	response := OpenStream(t, addr, "/api/users/_current/channel/events", http.Header{})
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	response = OpenStream(t, addr, "/api/users/_current/channel/events", http.Header{"Authorization": {user.Token}})
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	reader := bufio.NewReader(response.Body)
+	assert.Equal(t, model.OverlayOpHello, readStream(t, reader).Event)
+
+	SendSimulatorNotification(t, env, donation.ID, payment.StatusPaid)
+	_, paid := readStreamDonation(t, reader)
+	assert.Equal(t, donation.ID, paid.ID)
+	assert.Equal(t, "Nadia", paid.DonorName)
+}
+
+func TestEventFeedWithoutChannel(t *testing.T) {
+	env := NewEnv(t)
+	LoginUser(t, env)
+
+	user := new(entity.User)
+	err := env.DB.Where("id = ?", "Mousetri").First(user).Error
+	assert.Nil(t, err)
+	addr := env.Listen(t)
+
+	response := OpenStream(t, addr, "/api/users/_current/channel/events", http.Header{"Authorization": {user.Token}})
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
@@ -0,0 +1,91 @@
+package test
+
+import (
+	"context"
+	"io/fs"
+	"net/http"
+	"streamhelper-backend/db"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/fixture"
+	"streamhelper-backend/internal/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func demoFixture(t *testing.T) *fixture.Fixture {
+	fixtures, err := fs.Sub(db.Fixtures, "fixtures")
+	assert.Nil(t, err)
+
+	demo, err := fixture.ReadDataset(fixtures, "demo")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, demo.Users)
+	return demo
+}
+
+func TestParseFixtureRejectsUnknownFields(t *testing.T) {
+	_, err := fixture.Parse("bad.yaml", []byte("users:\n  - id: a\n    nmae: typo\n"))
+	assert.NotNil(t, err)
+
+	_, err = fixture.Parse("bad.json", []byte(`{"users":[{"id":"a","nmae":"typo"}]}`))
+	assert.NotNil(t, err)
+
+	_, err = fixture.Parse("bad.toml", []byte(""))
+	assert.NotNil(t, err)
+}
+
+func TestLoadDemoFixture(t *testing.T) {
+	env := NewEnv(t)
+	demo := demoFixture(t)
+	loader := fixture.NewLoader(env.Log, env.Application.UserUseCase, env.Application.ChannelUseCase, env.Application.DonationUseCase,
+		env.Application.GoalUseCase, env.Application.LeaderboardUseCase)
+
+	result, err := loader.Load(context.Background(), demo)
+	assert.Nil(t, err)
+	assert.Equal(t, len(demo.Users)+len(demo.Channels)+len(demo.Donations), result.Created)
+	assert.Equal(t, 0, result.Skipped)
+
+	admin := new(entity.User)
+	assert.Nil(t, env.DB.First(admin, "id = ?", "demo-admin").Error)
+	assert.Equal(t, entity.RoleAdmin, admin.Role)
+
+	banned := new(entity.User)
+	assert.Nil(t, env.DB.First(banned, "id = ?", "banned-viewer").Error)
+	assert.True(t, banned.Disabled())
+
+	assertDemoLeaderboard(t, env)
+}
+
+// assertDemoLeaderboard checks the paid donations seeded for kopi-senja are
+// ranked, each counted once.
+func assertDemoLeaderboard(t *testing.T, env *Env) {
+	response, ranking := sendJSON[*model.LeaderboardResponse](t, env, nil, http.MethodGet, "/api/channels/kopi-senja/leaderboards/all_time", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, []model.LeaderboardEntryResponse{
+		{Rank: 1, DonorName: "Nadia Putri", Amount: 275000},
+		{Rank: 2, DonorName: "Sarah Lim", Amount: 100000},
+		{Rank: 3, DonorName: "Budi Santoso", Amount: 50000},
+		{Rank: 4, DonorName: "Dimas", Amount: 20000},
+	}, ranking.Data.Entries)
+}
+
+func TestLoadDemoFixtureTwice(t *testing.T) {
+	env := NewEnv(t)
+	demo := demoFixture(t)
+	loader := fixture.NewLoader(env.Log, env.Application.UserUseCase, env.Application.ChannelUseCase, env.Application.DonationUseCase,
+		env.Application.GoalUseCase, env.Application.LeaderboardUseCase)
+
+	_, err := loader.Load(context.Background(), demo)
+	assert.Nil(t, err)
+
+	result, err := loader.Load(context.Background(), demo)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, result.Created)
+	assert.Equal(t, len(demo.Users)+len(demo.Channels)+len(demo.Donations), result.Skipped)
+
+	var count int64
+	assert.Nil(t, env.DB.Model(&entity.User{}).Count(&count).Error)
+	assert.Equal(t, int64(len(demo.Users)), count)
+
+	assertDemoLeaderboard(t, env)
+}
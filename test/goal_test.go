@@ -0,0 +1,238 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/repository"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createGoal(t *testing.T, env *Env, user *entity.User, body string) *model.GoalResponse {
+	response, goal := sendJSON[*model.GoalResponse](t, env, user, http.MethodPost, "/api/users/_current/channel/goals", body)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	return goal.Data
+}
+
+func TestGoalProgress(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+	goal := createGoal(t, env, user, `{"title":"Kursi baru","target_amount":100000}`)
+	assert.Equal(t, "Kursi baru", goal.Title)
+	assert.Equal(t, entity.DefaultCurrency, goal.Currency)
+	assert.Equal(t, entity.GoalStatusActive, goal.Status)
+	assert.Equal(t, int64(0), goal.Amount)
+	assert.Empty(t, goal.Sources)
+
+	PayDonation(t, env)
+	// a pending donation counts for nothing
+	Donate(t, env)
+
+	response, found := sendJSON[*model.GoalResponse](t, env, user, http.MethodGet, "/api/users/_current/channel/goals/"+goal.ID, "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, int64(25000), found.Data.Amount)
+	assert.Equal(t, int64(25), found.Data.Percent)
+
+	response, goals := sendJSON[[]model.GoalResponse](t, env, user, http.MethodGet, "/api/users/_current/channel/goals", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Len(t, goals.Data, 1)
+	assert.Equal(t, goal.ID, goals.Data[0].ID)
+}
+
+func TestGoalSources(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+	wallets := createGoal(t, env, user, `{"title":"Mic","target_amount":100000,"sources":["gopay","shopeepay","gopay"]}`)
+	assert.Equal(t, []string{"gopay", "shopeepay"}, wallets.Sources)
+	qris := createGoal(t, env, user, `{"title":"Kamera","target_amount":100000,"sources":["qris"]}`)
+
+	PayDonation(t, env)
+
+	_, found := sendJSON[*model.GoalResponse](t, env, user, http.MethodGet, "/api/users/_current/channel/goals/"+wallets.ID, "")
+	assert.Equal(t, int64(0), found.Data.Amount)
+	_, found = sendJSON[*model.GoalResponse](t, env, user, http.MethodGet, "/api/users/_current/channel/goals/"+qris.ID, "")
+	assert.Equal(t, int64(25000), found.Data.Amount)
+
+	// counting QRIS as well takes in what was already paid
+	response, updated := sendJSON[*model.GoalResponse](t, env, user, http.MethodPatch, "/api/users/_current/channel/goals/"+wallets.ID, `{"sources":["gopay","qris"]}`)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, int64(25000), updated.Data.Amount)
+
+	response, _ = sendJSON[*model.GoalResponse](t, env, user, http.MethodPatch, "/api/users/_current/channel/goals/"+wallets.ID, `{"sources":["cash"]}`)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestGoalCompleted(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+	goal := createGoal(t, env, user, `{"title":"Kursi baru","target_amount":50000}`)
+
+	PayDonation(t, env)
+	PayDonation(t, env)
+	PayDonation(t, env)
+
+	_, found := sendJSON[*model.GoalResponse](t, env, user, http.MethodGet, "/api/users/_current/channel/goals/"+goal.ID, "")
+	assert.Equal(t, entity.GoalStatusCompleted, found.Data.Status)
+	assert.Equal(t, int64(75000), found.Data.Amount)
+	assert.Equal(t, int64(150), found.Data.Percent)
+	assert.NotZero(t, found.Data.CompletedAt)
+
+	overlay := getOverlay(t, env, user, http.MethodGet, "/api/users/_current/channel/overlay")
+	events, _, err := env.Application.EventHub.Since(context.Background(), overlay.ChannelID, "0-0", 100)
+	assert.Nil(t, err)
+
+	var types []string
+	for _, event := range events {
+		if event.Type != model.EventTypeDonation && event.Type != model.EventTypeAlert {
+			types = append(types, event.Type)
+		}
+	}
+	// completion is announced once, progress goes on past the target
+	assert.Equal(t, []string{
+		model.EventTypeGoalProgress,
+		model.EventTypeGoalProgress,
+		model.EventTypeGoalProgress,
+		model.EventTypeGoalCompleted,
+		model.EventTypeGoalProgress,
+	}, types)
+}
+
+func TestGoalProgressKeepsConcurrentChanges(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+	created := createGoal(t, env, user, `{"title":"Kursi baru","target_amount":50000}`)
+	goalRepository := repository.NewGoalRepository(env.Log)
+
+	// two donations read the goal before either writes its progress, while
+	// the streamer renames it
+	first, second := new(entity.Goal), new(entity.Goal)
+	assert.Nil(t, env.DB.Take(first, "id = ?", created.ID).Error)
+	assert.Nil(t, env.DB.Take(second, "id = ?", created.ID).Error)
+	response, _ := sendJSON[*model.GoalResponse](t, env, user, http.MethodPatch, "/api/users/_current/channel/goals/"+created.ID, `{"title":"Kursi gaming"}`)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	first.Amount, first.CompletedAt = 50000, time.Now().UnixMilli()
+	completed, err := goalRepository.UpdateProgress(env.DB, first)
+	assert.Nil(t, err)
+	assert.True(t, completed)
+
+	second.Amount, second.CompletedAt = 75000, time.Now().UnixMilli()+1
+	completed, err = goalRepository.UpdateProgress(env.DB, second)
+	assert.Nil(t, err)
+	assert.False(t, completed)
+
+	found := new(entity.Goal)
+	assert.Nil(t, env.DB.Take(found, "id = ?", created.ID).Error)
+	assert.Equal(t, "Kursi gaming", found.Title)
+	assert.Equal(t, int64(75000), found.Amount)
+	assert.Equal(t, first.CompletedAt, found.CompletedAt)
+}
+
+func TestGoalRefund(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+	goal := createGoal(t, env, user, `{"title":"Kursi baru","target_amount":100000}`)
+	donation := PayDonation(t, env)
+	PayDonation(t, env)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/users/_current/channel/donations/"+donation.ID+"/_refund", strings.NewReader(`{"reason":"salah nominal"}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", user.Token)
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	_, found := sendJSON[*model.GoalResponse](t, env, user, http.MethodGet, "/api/users/_current/channel/goals/"+goal.ID, "")
+	assert.Equal(t, int64(25000), found.Data.Amount)
+}
+
+func TestGoalHistory(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+	goal := createGoal(t, env, user, `{"title":"Kursi baru","target_amount":100000}`)
+	PayDonation(t, env)
+
+	response, ended := sendJSON[*model.GoalResponse](t, env, user, http.MethodPost, "/api/users/_current/channel/goals/"+goal.ID+"/_end", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, entity.GoalStatusEnded, ended.Data.Status)
+
+	// an ended goal keeps its total and takes in nothing more
+	PayDonation(t, env)
+
+	response, goals := sendJSON[[]model.GoalResponse](t, env, user, http.MethodGet, "/api/users/_current/channel/goals", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Empty(t, goals.Data)
+
+	response, history := sendJSON[[]model.GoalResponse](t, env, user, http.MethodGet, "/api/users/_current/channel/goals/history?page=1&size=5", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Len(t, history.Data, 1)
+	assert.Equal(t, goal.ID, history.Data[0].ID)
+	assert.Equal(t, int64(25000), history.Data[0].Amount)
+	assert.Equal(t, int64(1), history.Paging.TotalItem)
+
+	response, failed := sendJSON[*model.GoalResponse](t, env, user, http.MethodPatch, "/api/users/_current/channel/goals/"+goal.ID, `{"title":"Kursi"}`)
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+	assert.Equal(t, model.ErrCodeGoalEnded, failed.Code)
+
+	response, deleted := sendJSON[bool](t, env, user, http.MethodDelete, "/api/users/_current/channel/goals/"+goal.ID, "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.True(t, deleted.Data)
+
+	response, _ = sendJSON[*model.GoalResponse](t, env, user, http.MethodGet, "/api/users/_current/channel/goals/"+goal.ID, "")
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+func TestGoalInvalid(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+	now := time.Now().UnixMilli()
+
+	response, failed := sendJSON[*model.GoalResponse](t, env, user, http.MethodPost, "/api/users/_current/channel/goals", fmt.Sprintf(`{"title":"Mic","target_amount":100000,"starts_at":%d,"ends_at":%d}`, now, now-1000))
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, model.ErrCodeGoalDates, failed.Code)
+
+	response, _ = sendJSON[*model.GoalResponse](t, env, user, http.MethodPost, "/api/users/_current/channel/goals", `{"title":"Mic","target_amount":100000,"currency":"USD"}`)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	response, _ = sendJSON[*model.GoalResponse](t, env, user, http.MethodPost, "/api/users/_current/channel/goals", `{"title":"Mic","target_amount":0}`)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	for i := range entity.MaxOpenGoals {
+		createGoal(t, env, user, fmt.Sprintf(`{"title":"Goal %d","target_amount":100000}`, i))
+	}
+	response, failed = sendJSON[*model.GoalResponse](t, env, user, http.MethodPost, "/api/users/_current/channel/goals", `{"title":"Mic","target_amount":100000}`)
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+	assert.Equal(t, model.ErrCodeGoalLimit, failed.Code)
+}
+
+func TestListChannelGoals(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+	running := createGoal(t, env, user, `{"title":"Kursi baru","target_amount":100000}`)
+	scheduled := createGoal(t, env, user, fmt.Sprintf(`{"title":"Mic","target_amount":100000,"starts_at":%d}`, time.Now().Add(time.Hour).UnixMilli()))
+	assert.Equal(t, entity.GoalStatusScheduled, scheduled.Status)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/channels/mousetri-live/goals", nil)
+	request.Header.Set("Accept", "application/json")
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	body, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	goals := new(model.WebResponse[[]model.GoalResponse])
+	assert.Nil(t, json.Unmarshal(body, goals))
+	// a scheduled goal is not shown before it starts
+	assert.Len(t, goals.Data, 1)
+	assert.Equal(t, running.ID, goals.Data[0].ID)
+}
@@ -0,0 +1,38 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/usecase"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadinessHidesCheckError(t *testing.T) {
+	env := NewEnv(t)
+	env.Application.HealthUseCase.SetReady(true)
+	env.Application.HealthUseCase.Register("search", func(ctx context.Context) error {
+		return errors.New("dial tcp 10.0.0.7:9200: connection refused")
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+
+	body, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(body), "10.0.0.7")
+
+	responseBody := new(model.WebResponse[*model.HealthResponse])
+	assert.Nil(t, json.Unmarshal(body, responseBody))
+	assert.Equal(t, usecase.HealthStatusDown, responseBody.Data.Status)
+	assert.Equal(t, usecase.HealthStatusDown, responseBody.Data.Checks["search"].Status)
+	assert.Equal(t, usecase.HealthStatusUp, responseBody.Data.Checks["redis"].Status)
+}
@@ -1,26 +1,44 @@
 package test
 
 import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
-func ClearAll() {
-	ClearUsers()
+func GetFirstUser(t *testing.T, env *Env) *entity.User {
+	user := new(entity.User)
+	err := env.DB.First(user).Error
+	assert.Nil(t, err)
+	return user
 }
 
-func ClearUsers() {
-	err := DB.Where("id is not null").Delete(&entity.User{}).Error
-	if err != nil {
-		Log.Fatalf("Failed clear user data : %+v", err)
+// sendJSON sends body to path as user, anonymously when user is nil, and
+// decodes the response.
+func sendJSON[T any](t *testing.T, env *Env, user *entity.User, method string, path string, body string) (*http.Response, *model.WebResponse[T]) {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	if user != nil {
+		request.Header.Set("Authorization", user.Token)
 	}
-}
 
-func GetFirstUser(t *testing.T) *entity.User {
-	user := new(entity.User)
-	err := DB.First(user).Error
+	response, err := env.Test(request)
 	assert.Nil(t, err)
-	return user
-}
\ No newline at end of file
+
+	responseBody, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	decoded := new(model.WebResponse[T])
+	err = json.Unmarshal(responseBody, decoded)
+	assert.Nil(t, err)
+
+	return response, decoded
+}
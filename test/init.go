@@ -1,37 +1,216 @@
 package test
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
 	"streamhelper-backend/internal/config"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/i18n"
+	"strings"
+	"sync"
+	"testing"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/glebarez/sqlite"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
+	"github.com/valyala/fasthttp"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-var App *fiber.App
+// DatabaseDSNEnv points the suite at a real Postgres. Every test then gets a
+// schema of its own, migrated with the SQL migrations. Without it tests run
+// against an in-memory SQLite database whose tables come from the entities,
+// which CI never does so the migrations are what gets tested there.
+const DatabaseDSNEnv = "TEST_DATABASE_DSN"
 
-var DB *gorm.DB
+// entities are created with AutoMigrate on SQLite, keep it in sync with
+// db/migrations.
+var entities = []any{
+	&entity.User{},
+	&entity.Channel{},
+	&entity.Donation{},
+	&entity.Payment{},
+	&entity.AlertTemplate{},
+	&entity.BlockedTerm{},
+	&entity.FilteredMessage{},
+	&entity.Goal{},
+}
 
-var ViperConfig *viper.Viper
+var (
+	baseOnce   sync.Once
+	baseViper  *viper.Viper
+	baseConfig *config.AppConfig
+)
+
+// Env is one fully bootstrapped application with its own database and Redis.
+// Nothing is shared between two Envs, so tests using it may run in parallel.
+type Env struct {
+	App         *fiber.App
+	DB          *gorm.DB
+	Redis       *redis.Client
+	RedisServer *miniredis.Miniredis
+	Config      *config.AppConfig
+	Log         *logrus.Logger
+	Validate    *validator.Validate
+	Translator  *i18n.Translator
+	Application *config.Application
+}
+
+// NewEnv builds an isolated application for t and marks t as parallel. All
+// resources are released when t finishes.
+func NewEnv(t *testing.T) *Env {
+	t.Helper()
+	t.Parallel()
+
+	baseOnce.Do(func() {
+		baseViper = config.NewViper()
+		baseConfig = config.NewAppConfig(baseViper, config.NewValidator(baseViper))
+	})
+
+	appConfig := *baseConfig
+	appConfig.Speech.Dir = t.TempDir()
+	env := &Env{
+		Config:   &appConfig,
+		Validate: config.NewValidator(baseViper),
+	}
+	env.Log = config.NewLogger(env.Config)
+	env.Translator = config.NewTranslator(env.Config, env.Validate, env.Log)
+	env.App = config.NewFiber(env.Config, env.Translator)
+
+	env.RedisServer = miniredis.RunT(t)
+	env.Redis = redis.NewClient(&redis.Options{Addr: env.RedisServer.Addr()})
+	t.Cleanup(func() { _ = env.Redis.Close() })
+
+	if dsn := os.Getenv(DatabaseDSNEnv); dsn != "" {
+		env.DB = newPostgresDatabase(t, env.Log, dsn)
+	} else if os.Getenv("CI") != "" {
+		t.Fatalf("%s must be set in CI, the SQLite fallback skips the migrations", DatabaseDSNEnv)
+	} else {
+		env.DB = newSQLiteDatabase(t, env.Log)
+	}
+
+	env.Application = config.Bootstrap(&config.BootstrapConfig{
+		DB:         env.DB,
+		Redis:      env.Redis,
+		App:        env.App,
+		Log:        env.Log,
+		Validate:   env.Validate,
+		Translator: env.Translator,
+		Config:     env.Config,
+	})
+	t.Cleanup(func() { _ = env.Application.EventHub.Close() })
+
+	return env
+}
+
+// Listen serves the app on a random local port and returns its address, for
+// clients that need a real connection such as WebSockets.
+func (e *Env) Listen(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen : %+v", err)
+	}
+
+	server := &fasthttp.Server{Handler: e.App.Handler()}
+	go func() { _ = server.Serve(listener) }()
+	// like the serve command, end the event streams before draining
+	t.Cleanup(func() {
+		_ = e.Application.EventHub.Close()
+		_ = server.Shutdown()
+	})
+
+	return listener.Addr().String()
+}
+
+// Test sends request to the app without fiber's one second default timeout,
+// which parallel tests hashing passwords under -race easily exceed.
+func (e *Env) Test(request *http.Request) (*http.Response, error) {
+	return e.App.Test(request, -1)
+}
+
+func newSQLiteDatabase(t *testing.T, log *logrus.Logger) *gorm.DB {
+	t.Helper()
+
+	// a named shared-cache database lives as long as one connection is open
+	// and is invisible to every other test
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared&_pragma=busy_timeout(5000)", randomName())
+	db, err := gorm.Open(sqlite.Open(dsn), config.NewGormConfig(log))
+	if err != nil {
+		t.Fatalf("Failed to open sqlite : %+v", err)
+	}
+	closeOnCleanup(t, db)
+
+	if err := db.AutoMigrate(entities...); err != nil {
+		t.Fatalf("Failed to create tables : %+v", err)
+	}
+
+	return db
+}
 
-var Log *logrus.Logger
+func newPostgresDatabase(t *testing.T, log *logrus.Logger, dsn string) *gorm.DB {
+	t.Helper()
 
-var Validate *validator.Validate
+	admin, err := gorm.Open(postgres.Open(dsn), config.NewGormConfig(log))
+	if err != nil {
+		t.Fatalf("Failed to connect to %s : %+v", DatabaseDSNEnv, err)
+	}
+	closeOnCleanup(t, admin)
 
-func init(){
-	ViperConfig = config.NewViper()
-	Log = config.NewLogger(ViperConfig)
-	Validate = config.NewValidator(ViperConfig)
-	App = config.NewFiber(ViperConfig)
-	DB = config.NewDatabase(ViperConfig, Log)
-	
-	config.Bootstrap(&config.BootstrapConfig{
-		DB:       DB,
-		App:      App,
-		Log:      Log,
-		Validate: Validate,
-		Config:   ViperConfig,
+	schema := randomName()
+	if err := admin.Exec(fmt.Sprintf("CREATE SCHEMA %s", schema)).Error; err != nil {
+		t.Fatalf("Failed to create schema : %+v", err)
+	}
+	t.Cleanup(func() {
+		if err := admin.Exec(fmt.Sprintf("DROP SCHEMA %s CASCADE", schema)).Error; err != nil {
+			t.Logf("Failed to drop schema %s : %+v", schema, err)
+		}
 	})
-}
\ No newline at end of file
+
+	db, err := gorm.Open(postgres.Open(withSearchPath(dsn, schema)), config.NewGormConfig(log))
+	if err != nil {
+		t.Fatalf("Failed to connect to schema %s : %+v", schema, err)
+	}
+	closeOnCleanup(t, db)
+
+	if _, err := config.NewMigrator(db, log).Up(context.Background(), 0); err != nil {
+		t.Fatalf("Failed to migrate schema %s : %+v", schema, err)
+	}
+
+	return db
+}
+
+func withSearchPath(dsn string, schema string) string {
+	if !strings.Contains(dsn, "://") {
+		return dsn + " search_path=" + schema
+	}
+	if strings.Contains(dsn, "?") {
+		return dsn + "&search_path=" + schema
+	}
+	return dsn + "?search_path=" + schema
+}
+
+func closeOnCleanup(t *testing.T, db *gorm.DB) {
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	})
+}
+
+func randomName() string {
+	buffer := make([]byte, 8)
+	_, _ = rand.Read(buffer)
+	return "test_" + hex.EncodeToString(buffer)
+}
@@ -0,0 +1,237 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/leaderboard"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/payment"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getLeaderboard(t *testing.T, env *Env, user *entity.User, board string) *model.LeaderboardResponse {
+	response, ranking := sendJSON[*model.LeaderboardResponse](t, env, user, http.MethodGet, "/api/users/_current/channel/leaderboards/"+board, "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	return ranking.Data
+}
+
+// payDonationAs pays a QRIS donation to the channel created by
+// CreateChannel, body holds the donor fields.
+func payDonationAs(t *testing.T, env *Env, body string) *model.DonationResponse {
+	request := httptest.NewRequest(http.MethodPost, "/api/channels/mousetri-live/donations", strings.NewReader(`{"payment_method":"qris",`+body+`}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	responseBody, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	donation := new(model.WebResponse[*model.DonationResponse])
+	assert.Nil(t, json.Unmarshal(responseBody, donation))
+
+	response = SendSimulatorNotification(t, env, donation.Data.ID, payment.StatusPaid)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	return donation.Data
+}
+
+func TestLeaderboardRanking(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+	payDonationAs(t, env, `"donor_name":"Nadia","amount":25000`)
+	payDonationAs(t, env, `"donor_name":"Budi","amount":50000`)
+	payDonationAs(t, env, `"donor_name":"nadia","amount":10000`)
+	// anonymous donors are never ranked
+	payDonationAs(t, env, `"donor_name":"Rina","amount":100000,"anonymous":true`)
+	// neither are donations that were never paid
+	Donate(t, env)
+
+	for _, board := range []string{leaderboard.KindAllTime, leaderboard.KindMonthly, leaderboard.KindWeekly} {
+		ranking := getLeaderboard(t, env, user, board)
+		assert.Equal(t, board, ranking.Board)
+		assert.Equal(t, entity.DefaultCurrency, ranking.Currency)
+		assert.Equal(t, []model.LeaderboardEntryResponse{
+			{Rank: 1, DonorName: "Budi", Amount: 50000},
+			{Rank: 2, DonorName: "nadia", Amount: 35000},
+		}, ranking.Entries)
+	}
+
+	weekly := getLeaderboard(t, env, user, leaderboard.KindWeekly)
+	assert.Equal(t, 7*24*time.Hour, time.UnixMilli(weekly.EndsAt).Sub(time.UnixMilli(weekly.StartsAt)))
+	assert.True(t, weekly.StartsAt <= time.Now().UnixMilli())
+
+	response, page := sendJSON[*model.LeaderboardResponse](t, env, nil, http.MethodGet, "/api/channels/mousetri-live/leaderboards/all_time?page=2&size=1", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, []model.LeaderboardEntryResponse{{Rank: 2, DonorName: "nadia", Amount: 35000}}, page.Data.Entries)
+	assert.Equal(t, int64(2), page.Paging.TotalItem)
+	assert.Equal(t, int64(2), page.Paging.TotalPage)
+
+	response, _ = sendJSON[*model.LeaderboardResponse](t, env, nil, http.MethodGet, "/api/channels/mousetri-live/leaderboards/daily", "")
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestLeaderboardRefund(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+	payDonationAs(t, env, `"donor_name":"Nadia","amount":25000`)
+	donation := payDonationAs(t, env, `"donor_name":"Budi","amount":50000`)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/users/_current/channel/donations/"+donation.ID+"/_refund", strings.NewReader(`{"reason":"salah nominal"}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", user.Token)
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	ranking := getLeaderboard(t, env, user, leaderboard.KindAllTime)
+	assert.Equal(t, []model.LeaderboardEntryResponse{{Rank: 1, DonorName: "Nadia", Amount: 25000}}, ranking.Entries)
+}
+
+func TestLeaderboardStream(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+	payDonationAs(t, env, `"donor_name":"Nadia","amount":25000`)
+
+	// no stream was started yet
+	assert.Empty(t, getLeaderboard(t, env, user, leaderboard.KindStream).Entries)
+
+	response, started := sendJSON[*model.LeaderboardResponse](t, env, user, http.MethodPost, "/api/users/_current/channel/leaderboards/stream/_start", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, leaderboard.KindStream, started.Data.Board)
+	assert.NotZero(t, started.Data.StartsAt)
+	assert.Empty(t, started.Data.Entries)
+
+	payDonationAs(t, env, `"donor_name":"Budi","amount":50000`)
+
+	stream := getLeaderboard(t, env, user, leaderboard.KindStream)
+	assert.Equal(t, started.Data.Period, stream.Period)
+	assert.Equal(t, []model.LeaderboardEntryResponse{{Rank: 1, DonorName: "Budi", Amount: 50000}}, stream.Entries)
+	assert.Len(t, getLeaderboard(t, env, user, leaderboard.KindAllTime).Entries, 2)
+}
+
+func TestLeaderboardEvents(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+	// rankings are only sent to overlays that show them
+	payDonationAs(t, env, `"donor_name":"Nadia","amount":25000`)
+
+	request := httptest.NewRequest(http.MethodPatch, "/api/users/_current/channel/leaderboards/_settings", strings.NewReader(`{"overlay":true}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", user.Token)
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	payDonationAs(t, env, `"donor_name":"Rina","amount":100000,"anonymous":true`)
+	payDonationAs(t, env, `"donor_name":"Budi","amount":50000`)
+
+	overlay := getOverlay(t, env, user, http.MethodGet, "/api/users/_current/channel/overlay")
+	events, _, err := env.Application.EventHub.Since(context.Background(), overlay.ChannelID, "0-0", 100)
+	assert.Nil(t, err)
+
+	var boards []*model.LeaderboardResponse
+	for _, event := range events {
+		if event.Type != model.EventTypeLeaderboard {
+			continue
+		}
+		board := new(model.LeaderboardResponse)
+		assert.Nil(t, json.Unmarshal(event.Data, board))
+		boards = append(boards, board)
+	}
+	// every board changed once, the anonymous donation changed nothing
+	assert.Len(t, boards, 3)
+	last := boards[len(boards)-1]
+	assert.Equal(t, leaderboard.KindWeekly, last.Board)
+	assert.Equal(t, []model.LeaderboardEntryResponse{
+		{Rank: 1, DonorName: "Budi", Amount: 50000},
+		{Rank: 2, DonorName: "Nadia", Amount: 25000},
+	}, last.Entries)
+}
+
+func TestLeaderboardCountsDonationOnce(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+	nadia := payDonationAs(t, env, `"donor_name":"Nadia","amount":25000`)
+	payDonationAs(t, env, `"donor_name":"Budi","amount":50000`)
+
+	record := &model.RecordLeaderboardRequest{DonationID: nadia.ID}
+	assert.Nil(t, env.Application.LeaderboardUseCase.Record(context.Background(), record))
+	assert.Nil(t, env.Application.LeaderboardUseCase.Record(context.Background(), record))
+	assert.Equal(t, []model.LeaderboardEntryResponse{
+		{Rank: 1, DonorName: "Budi", Amount: 50000},
+		{Rank: 2, DonorName: "Nadia", Amount: 25000},
+	}, getLeaderboard(t, env, user, leaderboard.KindAllTime).Entries)
+
+	assert.Nil(t, env.DB.Model(new(entity.Donation)).Where("id = ?", nadia.ID).
+		Update("status", entity.DonationStatusRefunded).Error)
+	assert.Nil(t, env.Application.LeaderboardUseCase.Record(context.Background(), record))
+	assert.Nil(t, env.Application.LeaderboardUseCase.Record(context.Background(), record))
+	assert.Equal(t, []model.LeaderboardEntryResponse{
+		{Rank: 1, DonorName: "Budi", Amount: 50000},
+	}, getLeaderboard(t, env, user, leaderboard.KindAllTime).Entries)
+}
+
+func TestLeaderboardRebuild(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+	nadia := payDonationAs(t, env, `"donor_name":"Nadia","amount":25000`)
+	payDonationAs(t, env, `"donor_name":"Budi","amount":50000`)
+	payDonationAs(t, env, `"donor_name":"Rina","amount":100000,"anonymous":true`)
+
+	for _, key := range env.RedisServer.Keys() {
+		if strings.HasPrefix(key, "leaderboard:") {
+			env.RedisServer.Del(key)
+		}
+	}
+	assert.Empty(t, getLeaderboard(t, env, user, leaderboard.KindAllTime).Entries)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/users/_current/channel/leaderboards/_rebuild", nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", user.Token)
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	expected := []model.LeaderboardEntryResponse{
+		{Rank: 1, DonorName: "Budi", Amount: 50000},
+		{Rank: 2, DonorName: "Nadia", Amount: 25000},
+	}
+	assert.Equal(t, expected, getLeaderboard(t, env, user, leaderboard.KindAllTime).Entries)
+	assert.Equal(t, expected, getLeaderboard(t, env, user, leaderboard.KindWeekly).Entries)
+
+	// the rebuilt boards remember which donations they counted
+	assert.Nil(t, env.Application.LeaderboardUseCase.Record(context.Background(), &model.RecordLeaderboardRequest{DonationID: nadia.ID}))
+	assert.Equal(t, expected, getLeaderboard(t, env, user, leaderboard.KindAllTime).Entries)
+}
+
+func TestLeaderboardPeriods(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	// Sunday 18:00 UTC is already Monday in Jakarta
+	t1 := time.Date(2026, time.March, 1, 18, 0, 0, 0, time.UTC)
+
+	weekly := leaderboard.Current(leaderboard.KindWeekly, t1, jakarta, 0)
+	assert.Equal(t, "2026-W10", weekly.Period)
+	assert.Equal(t, time.Date(2026, time.March, 2, 0, 0, 0, 0, jakarta), weekly.Start)
+	assert.Equal(t, time.Date(2026, time.March, 9, 0, 0, 0, 0, jakarta), weekly.End)
+
+	monthly := leaderboard.Current(leaderboard.KindMonthly, t1, jakarta, 0)
+	assert.Equal(t, "2026-03", monthly.Period)
+	assert.True(t, monthly.Contains(t1))
+	assert.False(t, monthly.Contains(time.Date(2026, time.February, 28, 16, 59, 0, 0, time.UTC)))
+
+	streamStartedAt := t1.UnixMilli()
+	assert.Len(t, leaderboard.Boards(t1.Add(-time.Minute), jakarta, streamStartedAt), 3)
+	assert.Len(t, leaderboard.Boards(t1, jakarta, streamStartedAt), 4)
+	assert.Len(t, leaderboard.Boards(t1, jakarta, 0), 3)
+}
@@ -0,0 +1,205 @@
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"streamhelper-backend/internal/alert"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/moderation"
+	"streamhelper-backend/internal/payment"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// DonateMessage donates to the channel created by CreateChannel with a
+// message, returning the response as is.
+func DonateMessage(t *testing.T, env *Env, message string) (*http.Response, *model.WebResponse[*model.DonationResponse]) {
+	body, err := json.Marshal(map[string]any{"donor_name": "Nadia", "amount": 25000, "payment_method": "qris", "message": message})
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/channels/mousetri-live/donations", strings.NewReader(string(body)))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+
+	responseBody, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	decoded := new(model.WebResponse[*model.DonationResponse])
+	err = json.Unmarshal(responseBody, decoded)
+	assert.Nil(t, err)
+
+	return response, decoded
+}
+
+func TestModerationFilter(t *testing.T) {
+	filter := moderation.NewFilter(moderation.DefaultWords, nil)
+
+	masked := map[string]string{
+		"dasar anjing":           "dasar ******",
+		"ANJING!":                "******!",
+		"4nj1ng lu":              "****** lu",
+		"a.n.j.i.n.g":            "***********",
+		"a n j i n g banget":     "* * * * * * banget",
+		"anjiiiing":              "*********",
+		"bangsatnya kabur":       "********** kabur",
+		"what the fuuuck":        "what the ******",
+		"stop fucking around":    "stop ******* around",
+		"b@ngs@t, semangat ya!!": "*******, semangat ya!!",
+		"dasar asu":              "dasar ***",
+		"dasar asunya":           "dasar ******",
+		"fucked up":              "****** up",
+	}
+	for text, expected := range masked {
+		result := filter.Check(text)
+		assert.True(t, result.Blocked(), text)
+		assert.Equal(t, expected, result.Text, text)
+	}
+
+	// whole words only, and letters may be stretched but not shortened
+	for _, text := range []string{"class assessment", "as soon as", "scunthorpe", "semangat kak", "asupan gizi", "tololan"} {
+		result := filter.Check(text)
+		assert.False(t, result.Blocked(), text)
+		assert.Equal(t, text, result.Text)
+	}
+
+	// the plural "s" is not stripped down to short words
+	for _, text := range []string{"Asus", "laptop ASUS baru", "Asus ROG"} {
+		result := filter.Check(text)
+		assert.False(t, result.Blocked(), text)
+		assert.Equal(t, text, result.Text)
+	}
+
+	rule, err := moderation.CompileRule(`judi\s*online`)
+	assert.Nil(t, err)
+	result := moderation.NewFilter(nil, []*regexp.Regexp{rule}).Check("main JUDI online yuk")
+	assert.Equal(t, "main **** ****** yuk", result.Text)
+	assert.Equal(t, []string{"JUDI online"}, result.Matches)
+
+	for _, pattern := range []string{"(", ".*", "a?", "", strings.Repeat("a", moderation.MaxRuleLength+1)} {
+		_, err := moderation.CompileRule(pattern)
+		assert.ErrorIs(t, err, moderation.ErrInvalidRule, pattern)
+	}
+}
+
+func TestMessageFilterMask(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+
+	response, settings := sendJSON[*model.MessageFilterSettingsResponse](t, env, user, http.MethodGet, "/api/users/_current/channel/filter", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, entity.FilterActionMask, settings.Data.Action)
+
+	response, donation := DonateMessage(t, env, "semangat b4ngsat")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "semangat *******", donation.Data.Message)
+	assert.False(t, donation.Data.Held)
+
+	// a clean message is left alone and not audited
+	response, _ = DonateMessage(t, env, "semangat kak")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	response, audit := sendJSON[[]model.FilteredMessageResponse](t, env, user, http.MethodGet, "/api/users/_current/channel/filter/audit", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, int64(1), audit.Paging.TotalItem)
+	assert.Equal(t, donation.Data.ID, audit.Data[0].DonationID)
+	assert.Equal(t, entity.FilterActionMask, audit.Data[0].Action)
+	assert.Equal(t, "semangat b4ngsat", audit.Data[0].Original)
+	assert.Equal(t, "semangat *******", audit.Data[0].Filtered)
+	assert.Equal(t, []string{"b4ngsat"}, audit.Data[0].Matches)
+}
+
+func TestMessageFilterReject(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+
+	response, settings := sendJSON[*model.MessageFilterSettingsResponse](t, env, user, http.MethodPatch, "/api/users/_current/channel/filter", `{"action":"reject"}`)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, entity.FilterActionReject, settings.Data.Action)
+
+	response, rejected := DonateMessage(t, env, "kontol")
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assert.Equal(t, model.ErrCodeMessageRejected, rejected.Code)
+	assert.Equal(t, "message", rejected.Fields[0].Field)
+
+	var total int64
+	assert.Nil(t, env.DB.Model(new(entity.Donation)).Count(&total).Error)
+	assert.Equal(t, int64(0), total)
+
+	response, audit := sendJSON[[]model.FilteredMessageResponse](t, env, user, http.MethodGet, "/api/users/_current/channel/filter/audit?action=reject", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Len(t, audit.Data, 1)
+	assert.Empty(t, audit.Data[0].DonationID)
+
+	response, _ = sendJSON[*model.MessageFilterSettingsResponse](t, env, user, http.MethodPatch, "/api/users/_current/channel/filter", `{"action":"delete"}`)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestMessageFilterHold(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+
+	response, _ := sendJSON[*model.MessageFilterSettingsResponse](t, env, user, http.MethodPatch, "/api/users/_current/channel/filter", `{"action":"hold"}`)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	response, donation := DonateMessage(t, env, "goblok")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.True(t, donation.Data.Held)
+
+	response = SendSimulatorNotification(t, env, donation.Data.ID, payment.StatusPaid)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	queue := getAlertQueue(t, env, user)
+	assert.Empty(t, queue.Queue)
+	assert.Len(t, queue.Review, 1)
+	assert.Equal(t, alert.StatusReview, queue.Review[0].Status)
+}
+
+func TestMessageFilterTerms(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+
+	response, word := sendJSON[*model.BlockedTermResponse](t, env, user, http.MethodPost, "/api/users/_current/channel/filter/terms", `{"kind":"word","pattern":" Kampang "}`)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "kampang", word.Data.Pattern)
+
+	response, _ = sendJSON[*model.BlockedTermResponse](t, env, user, http.MethodPost, "/api/users/_current/channel/filter/terms", `{"kind":"regex","pattern":"judi\\s*online"}`)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	response, _ = sendJSON[*model.BlockedTermResponse](t, env, user, http.MethodPost, "/api/users/_current/channel/filter/terms", `{"kind":"word","pattern":"kampang"}`)
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+
+	for _, body := range []string{`{"kind":"regex","pattern":"(judi"}`, `{"kind":"regex","pattern":".*"}`, `{"kind":"word","pattern":"two words"}`, `{"kind":"word","pattern":"!!!"}`, `{"kind":"phrase","pattern":"x"}`} {
+		response, _ = sendJSON[*model.BlockedTermResponse](t, env, user, http.MethodPost, "/api/users/_current/channel/filter/terms", body)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode, body)
+	}
+
+	response, terms := sendJSON[[]model.BlockedTermResponse](t, env, user, http.MethodGet, "/api/users/_current/channel/filter/terms", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Len(t, terms.Data, 2)
+
+	response, checked := sendJSON[*model.FilterMessageResponse](t, env, user, http.MethodPost, "/api/users/_current/channel/filter/_check", `{"text":"k4mpang, ayo judi online"}`)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, entity.FilterActionMask, checked.Data.Action)
+	assert.Equal(t, "*******, ayo **** ******", checked.Data.Text)
+
+	response, deleted := sendJSON[bool](t, env, user, http.MethodDelete, "/api/users/_current/channel/filter/terms/"+word.Data.ID, "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.True(t, deleted.Data)
+
+	response, checked = sendJSON[*model.FilterMessageResponse](t, env, user, http.MethodPost, "/api/users/_current/channel/filter/_check", `{"text":"kampang"}`)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Empty(t, checked.Data.Action)
+	assert.Equal(t, "kampang", checked.Data.Text)
+
+	response, _ = sendJSON[bool](t, env, user, http.MethodDelete, "/api/users/_current/channel/filter/terms/"+word.Data.ID, "")
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
@@ -0,0 +1,52 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"streamhelper-backend/internal/config"
+	"streamhelper-backend/internal/delivery/http/middleware"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsNotPublic(t *testing.T) {
+	env := NewEnv(t)
+
+	response, err := env.Test(httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+func TestMetricsBearerToken(t *testing.T) {
+	env := NewEnv(t)
+	app := config.NewFiber(env.Config, env.Translator)
+	app.Get("/metrics", middleware.NewMetricsAuth("scrape-secret"), func(ctx *fiber.Ctx) error {
+		return ctx.SendString("ok")
+	})
+
+	for authorization, status := range map[string]int{
+		"":                     http.StatusUnauthorized,
+		"scrape-secret":        http.StatusUnauthorized,
+		"Bearer wrong":         http.StatusUnauthorized,
+		"Bearer scrape-secret": http.StatusOK,
+	} {
+		request := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if authorization != "" {
+			request.Header.Set("Authorization", authorization)
+		}
+		response, err := app.Test(request, -1)
+		assert.Nil(t, err)
+		assert.Equal(t, status, response.StatusCode, authorization)
+	}
+}
+
+func TestMetricsTokenMasked(t *testing.T) {
+	env := NewEnv(t)
+	appConfig := *env.Config
+	appConfig.Metrics.Token = "scrape-secret"
+
+	assert.NotEqual(t, "scrape-secret", appConfig.Masked().Metrics.Token)
+	assert.Equal(t, "scrape-secret", appConfig.Metrics.Token)
+}
@@ -0,0 +1,160 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"streamhelper-backend/internal/payment"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newMidtrans points a Midtrans client at handler instead of the API.
+func newMidtrans(t *testing.T, handler http.HandlerFunc) *payment.Midtrans {
+	t.Parallel()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return payment.NewMidtrans("SB-Mid-server-test", server.URL, 5*time.Second)
+}
+
+func TestMidtransChargeBankTransfer(t *testing.T) {
+	var charged map[string]any
+	midtrans := newMidtrans(t, func(writer http.ResponseWriter, request *http.Request) {
+		username, _, _ := request.BasicAuth()
+		assert.Equal(t, "SB-Mid-server-test", username)
+		assert.Equal(t, "/v2/charge", request.URL.Path)
+		assert.Nil(t, json.NewDecoder(request.Body).Decode(&charged))
+
+		_, _ = writer.Write([]byte(`{"status_code":"201","transaction_id":"trx-1","order_id":"donation-1","gross_amount":"25000.00","payment_type":"bank_transfer","transaction_status":"pending","expiry_time":"2026-10-20 12:00:00","va_numbers":[{"bank":"bni","va_number":"9881234567890123"}]}`))
+	})
+
+	charge, err := midtrans.CreateCharge(context.Background(), &payment.ChargeRequest{
+		OrderID:  "donation-1",
+		Currency: "IDR",
+		Amount:   25000,
+		Method:   payment.MethodBankTransfer,
+		Bank:     payment.BankBNI,
+		Expiry:   15 * time.Minute,
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, "bank_transfer", charged["payment_type"])
+	assert.Equal(t, map[string]any{"bank": "bni"}, charged["bank_transfer"])
+	assert.Equal(t, map[string]any{"expiry_duration": float64(15), "unit": "minute"}, charged["custom_expiry"])
+
+	assert.Equal(t, "trx-1", charge.Reference)
+	assert.Equal(t, payment.StatusPending, charge.Status)
+	assert.Equal(t, "9881234567890123", charge.VANumber)
+	assert.Equal(t, time.Date(2026, 10, 20, 5, 0, 0, 0, time.UTC).UnixMilli(), charge.ExpiresAt)
+}
+
+func TestMidtransChargeQRIS(t *testing.T) {
+	midtrans := newMidtrans(t, func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(`{"status_code":"201","transaction_id":"trx-2","order_id":"donation-2","gross_amount":"10000.00","transaction_status":"pending","qr_string":"00020101021126...","actions":[{"name":"generate-qr-code","url":"https://api.sandbox.midtrans.com/v2/qris/trx-2/qr-code"}]}`))
+	})
+
+	charge, err := midtrans.CreateCharge(context.Background(), &payment.ChargeRequest{
+		OrderID:  "donation-2",
+		Currency: "IDR",
+		Amount:   10000,
+		Method:   payment.MethodQRIS,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "00020101021126...", charge.QRString)
+	assert.Equal(t, "https://api.sandbox.midtrans.com/v2/qris/trx-2/qr-code", charge.RedirectURL)
+}
+
+func TestMidtransChargeUnsupportedBank(t *testing.T) {
+	midtrans := newMidtrans(t, func(writer http.ResponseWriter, request *http.Request) {
+		t.Error("unexpected request")
+	})
+
+	_, err := midtrans.CreateCharge(context.Background(), &payment.ChargeRequest{
+		OrderID:  "donation-3",
+		Currency: "IDR",
+		Amount:   10000,
+		Method:   payment.MethodBankTransfer,
+		Bank:     "permata",
+	})
+	assert.ErrorIs(t, err, payment.ErrUnsupportedMethod)
+}
+
+func TestMidtransChargeUnsupportedCurrency(t *testing.T) {
+	midtrans := newMidtrans(t, func(writer http.ResponseWriter, request *http.Request) {
+		t.Error("unexpected request")
+	})
+
+	_, err := midtrans.CreateCharge(context.Background(), &payment.ChargeRequest{
+		OrderID:  "donation-4",
+		Amount:   10,
+		Currency: "USD",
+		Method:   payment.MethodQRIS,
+	})
+	assert.ErrorIs(t, err, payment.ErrUnsupportedCurrency)
+}
+
+func TestMidtransVerifyNotification(t *testing.T) {
+	midtrans := newMidtrans(t, func(writer http.ResponseWriter, request *http.Request) {})
+
+	notification := map[string]string{
+		"order_id":           "donation-1",
+		"status_code":        "200",
+		"gross_amount":       "25000.00",
+		"transaction_id":     "trx-1",
+		"transaction_status": "settlement",
+		"signature_key":      midtrans.Signature("donation-1", "200", "25000.00"),
+	}
+	body, err := json.Marshal(notification)
+	assert.Nil(t, err)
+
+	verified, err := midtrans.VerifyNotification(context.Background(), http.Header{}, body)
+	assert.Nil(t, err)
+	assert.Equal(t, "donation-1", verified.OrderID)
+	assert.Equal(t, payment.StatusPaid, verified.Status)
+	assert.Equal(t, int64(25000), verified.Amount)
+
+	// the signature covers the amount, changing it must be detected
+	notification["gross_amount"] = "2500000.00"
+	body, err = json.Marshal(notification)
+	assert.Nil(t, err)
+
+	_, err = midtrans.VerifyNotification(context.Background(), http.Header{}, body)
+	assert.ErrorIs(t, err, payment.ErrInvalidSignature)
+}
+
+func TestMidtransStatus(t *testing.T) {
+	statuses := map[string]string{
+		`"transaction_status":"capture","fraud_status":"accept"`:    payment.StatusPaid,
+		`"transaction_status":"capture","fraud_status":"challenge"`: payment.StatusPending,
+		`"transaction_status":"deny"`:                               payment.StatusFailed,
+		`"transaction_status":"expire"`:                             payment.StatusExpired,
+		`"transaction_status":"partial_refund"`:                     payment.StatusRefunded,
+	}
+
+	var current string
+	midtrans := newMidtrans(t, func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "/v2/donation-1/status", request.URL.Path)
+		_, _ = writer.Write([]byte(`{"status_code":"200","order_id":"donation-1","gross_amount":"25000.00",` + current + `}`))
+	})
+
+	for fields, expected := range statuses {
+		current = fields
+		notification, err := midtrans.Status(context.Background(), "donation-1")
+		assert.Nil(t, err)
+		assert.Equal(t, expected, notification.Status, fields)
+	}
+}
+
+func TestMidtransStatusNotFound(t *testing.T) {
+	midtrans := newMidtrans(t, func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(`{"status_code":"404","status_message":"Transaction doesn't exist."}`))
+	})
+
+	_, err := midtrans.Status(context.Background(), "donation-404")
+	assert.ErrorIs(t, err, payment.ErrChargeNotFound)
+}
@@ -0,0 +1,206 @@
+package test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/event"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/payment"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func getOverlay(t *testing.T, env *Env, user *entity.User, method string, path string) *model.OverlayResponse {
+	request := httptest.NewRequest(method, path, nil)
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", user.Token)
+
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+
+	body, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[*model.OverlayResponse])
+	err = json.Unmarshal(body, responseBody)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	return responseBody.Data
+}
+
+// DialOverlay connects to the overlay WebSocket of a listening env and reads
+// the hello message.
+func DialOverlay(t *testing.T, addr string, query url.Values) (*websocket.Conn, *model.OverlayMessage) {
+	conn, _, err := websocket.DefaultDialer.Dial("ws://"+addr+"/api/overlay/ws?"+query.Encode(), nil)
+	if err != nil {
+		t.Fatalf("Failed to dial overlay : %+v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	hello := readOverlay(t, conn)
+	assert.Equal(t, model.OverlayOpHello, hello.Op)
+	return conn, hello
+}
+
+// readOverlay returns the next message that is not a heartbeat.
+func readOverlay(t *testing.T, conn *websocket.Conn) *model.OverlayMessage {
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		message := new(model.OverlayMessage)
+		if err := conn.ReadJSON(message); err != nil {
+			t.Fatalf("Failed to read overlay message : %+v", err)
+		}
+		if message.Op != model.OverlayOpHeartbeat {
+			return message
+		}
+	}
+}
+
+func readDonationEvent(t *testing.T, conn *websocket.Conn) (*event.Event, *model.DonationEvent) {
+	message := readOverlay(t, conn)
+	assert.Equal(t, model.OverlayOpEvent, message.Op)
+
+	received := new(event.Event)
+	assert.Nil(t, json.Unmarshal(message.Event, received))
+	assert.Equal(t, message.ID, received.ID)
+	assert.Equal(t, model.EventTypeDonation, received.Type)
+
+	donation := new(model.DonationEvent)
+	assert.Nil(t, json.Unmarshal(received.Data, donation))
+	return received, donation
+}
+
+func TestGetOverlay(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+
+	overlay := getOverlay(t, env, user, http.MethodGet, "/api/users/_current/channel/overlay")
+	assert.NotEmpty(t, overlay.ChannelID)
+	assert.Len(t, overlay.Key, 32)
+
+	rotated := getOverlay(t, env, user, http.MethodPost, "/api/users/_current/channel/overlay/_rotate")
+	assert.Equal(t, overlay.ChannelID, rotated.ChannelID)
+	assert.NotEqual(t, overlay.Key, rotated.Key)
+
+	found := getOverlay(t, env, user, http.MethodGet, "/api/users/_current/channel/overlay")
+	assert.Equal(t, rotated.Key, found.Key)
+}
+
+func TestOverlayConnectInvalidKey(t *testing.T) {
+	env := NewEnv(t)
+	addr := env.Listen(t)
+
+	_, response, err := websocket.DefaultDialer.Dial("ws://"+addr+"/api/overlay/ws?key=wrong", nil)
+	assert.ErrorIs(t, err, websocket.ErrBadHandshake)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+}
+
+func TestOverlayConnectWithoutUpgrade(t *testing.T) {
+	env := NewEnv(t)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/overlay/ws?key=wrong", nil)
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusUpgradeRequired, response.StatusCode)
+}
+
+func TestOverlayReceivesDonation(t *testing.T) {
+	env := NewEnv(t)
+	user, donation := CreateDonation(t, env)
+	overlay := getOverlay(t, env, user, http.MethodGet, "/api/users/_current/channel/overlay")
+	addr := env.Listen(t)
+
+	conn, hello := DialOverlay(t, addr, url.Values{"key": {overlay.Key}})
+	assert.Equal(t, overlay.ChannelID, hello.ChannelID)
+	assert.Empty(t, hello.LastEventID)
+	assert.Equal(t, int64(env.Config.Overlay.HeartbeatInterval*1000), hello.HeartbeatInterval)
+
+	response := SendSimulatorNotification(t, env, donation.ID, payment.StatusPaid)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	received, paid := readDonationEvent(t, conn)
+	assert.Equal(t, overlay.ChannelID, received.ChannelID)
+	assert.Equal(t, donation.ID, paid.ID)
+	assert.Equal(t, "Nadia", paid.DonorName)
+	assert.Equal(t, int64(25000), paid.Amount)
+}
+
+func TestOverlayReplay(t *testing.T) {
+	env := NewEnv(t)
+	user, first := CreateDonation(t, env)
+	overlay := getOverlay(t, env, user, http.MethodGet, "/api/users/_current/channel/overlay")
+	addr := env.Listen(t)
+	query := url.Values{"key": {overlay.Key}, "client": {"obs"}}
+
+	conn, _ := DialOverlay(t, addr, query)
+	SendSimulatorNotification(t, env, first.ID, payment.StatusPaid)
+	acked, _ := readDonationEvent(t, conn)
+
+	err := conn.WriteJSON(&model.OverlayMessage{Op: model.OverlayOpAck, ID: acked.ID})
+	assert.Nil(t, err)
+	assert.Eventually(t, func() bool {
+		lastAck, err := env.Application.EventHub.LastAck(t.Context(), overlay.ChannelID, "obs")
+		return err == nil && lastAck == acked.ID
+	}, 5*time.Second, 10*time.Millisecond)
+	_ = conn.Close()
+
+	// two donations are paid while the overlay is offline
+	missed := make([]string, 0, 2)
+	for i := 0; i < 2; i++ {
+		donation := Donate(t, env)
+		SendSimulatorNotification(t, env, donation.ID, payment.StatusPaid)
+		missed = append(missed, donation.ID)
+	}
+
+	conn, hello := DialOverlay(t, addr, query)
+	assert.Equal(t, acked.ID, hello.LastEventID)
+	for _, id := range missed {
+		_, replayed := readDonationEvent(t, conn)
+		assert.Equal(t, id, replayed.ID)
+	}
+
+	// an explicit last_event_id wins over the acknowledgement
+	query.Set("last_event_id", "0-0")
+	conn, _ = DialOverlay(t, addr, query)
+	_, replayed := readDonationEvent(t, conn)
+	assert.Equal(t, first.ID, replayed.ID)
+}
+
+func TestOverlayConnectInvalidEventID(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+	overlay := getOverlay(t, env, user, http.MethodGet, "/api/users/_current/channel/overlay")
+	addr := env.Listen(t)
+
+	query := url.Values{"key": {overlay.Key}, "last_event_id": {"yesterday"}}
+	_, response, err := websocket.DefaultDialer.Dial("ws://"+addr+"/api/overlay/ws?"+query.Encode(), nil)
+	assert.ErrorIs(t, err, websocket.ErrBadHandshake)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestOverlayRotateKeyDisconnects(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+	overlay := getOverlay(t, env, user, http.MethodGet, "/api/users/_current/channel/overlay")
+	addr := env.Listen(t)
+
+	conn, _ := DialOverlay(t, addr, url.Values{"key": {overlay.Key}})
+	getOverlay(t, env, user, http.MethodPost, "/api/users/_current/channel/overlay/_rotate")
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, _, err := conn.ReadMessage()
+		if err != nil {
+			assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation), err.Error())
+			break
+		}
+	}
+}
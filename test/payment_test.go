@@ -0,0 +1,201 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"streamhelper-backend/internal/config"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/payment"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// CreateDonation creates a channel and a pending QRIS donation to it, and
+// returns the channel owner and the donation.
+func CreateDonation(t *testing.T, env *Env) (*entity.User, *model.DonationResponse) {
+	user := CreateChannel(t, env)
+	return user, Donate(t, env)
+}
+
+// Donate creates another pending QRIS donation to the channel created by
+// CreateChannel.
+func Donate(t *testing.T, env *Env) *model.DonationResponse {
+	request := httptest.NewRequest(http.MethodPost, "/api/channels/mousetri-live/donations", strings.NewReader(`{"donor_name":"Nadia","amount":25000,"payment_method":"qris"}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+
+	body, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[*model.DonationResponse])
+	err = json.Unmarshal(body, responseBody)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	return responseBody.Data
+}
+
+// SendSimulatorNotification moves the simulated charge of donationId to
+// status and posts the signed callback, like "streamhelp payment simulate".
+func SendSimulatorNotification(t *testing.T, env *Env, donationId string, status string) *http.Response {
+	simulator := config.NewPaymentSimulator(env.Config, env.Redis, env.Log)
+	body, signature, err := simulator.Notify(context.Background(), donationId, status)
+	assert.Nil(t, err)
+
+	return sendNotification(t, env, "simulator", body, signature)
+}
+
+func sendNotification(t *testing.T, env *Env, provider string, body []byte, signature string) *http.Response {
+	request := httptest.NewRequest(http.MethodPost, "/api/payments/"+provider+"/notifications", bytes.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set(payment.SimulatorSignatureHeader, signature)
+
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+	return response
+}
+
+func getDonationPayment(t *testing.T, env *Env, donationId string) *model.DonationResponse {
+	request := httptest.NewRequest(http.MethodGet, "/api/donations/"+donationId+"/payment", nil)
+	request.Header.Set("Accept", "application/json")
+
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+
+	body, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[*model.DonationResponse])
+	err = json.Unmarshal(body, responseBody)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	return responseBody.Data
+}
+
+func TestPaymentNotificationPaid(t *testing.T) {
+	env := NewEnv(t)
+	_, donation := CreateDonation(t, env)
+
+	response := SendSimulatorNotification(t, env, donation.ID, payment.StatusPaid)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	found := getDonationPayment(t, env, donation.ID)
+	assert.Equal(t, entity.DonationStatusPaid, found.Status)
+	assert.NotZero(t, found.PaidAt)
+	assert.Equal(t, payment.StatusPaid, found.Payment.Status)
+
+	// providers retry callbacks, a repeated one is acknowledged again
+	response = SendSimulatorNotification(t, env, donation.ID, payment.StatusPaid)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
+
+func TestPaymentNotificationLateExpiryIgnored(t *testing.T) {
+	env := NewEnv(t)
+	_, donation := CreateDonation(t, env)
+
+	response := SendSimulatorNotification(t, env, donation.ID, payment.StatusPaid)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	response = SendSimulatorNotification(t, env, donation.ID, payment.StatusExpired)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	stored := new(entity.Donation)
+	err := env.DB.Where("id = ?", donation.ID).Take(stored).Error
+	assert.Nil(t, err)
+	assert.Equal(t, entity.DonationStatusPaid, stored.Status)
+}
+
+func TestPaymentNotificationInvalidSignature(t *testing.T) {
+	env := NewEnv(t)
+	_, donation := CreateDonation(t, env)
+
+	simulator := config.NewPaymentSimulator(env.Config, env.Redis, env.Log)
+	body, _, err := simulator.Notify(context.Background(), donation.ID, payment.StatusPaid)
+	assert.Nil(t, err)
+
+	forged := payment.NewSimulator("not the secret", env.Redis).Signature(body)
+	response := sendNotification(t, env, "simulator", body, forged)
+
+	responseBody, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	errorBody := new(model.WebResponse[bool])
+	err = json.Unmarshal(responseBody, errorBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	assert.Equal(t, model.ErrCodeInvalidSignature, errorBody.Code)
+
+	stored := new(entity.Donation)
+	err = env.DB.Where("id = ?", donation.ID).Take(stored).Error
+	assert.Nil(t, err)
+	assert.Equal(t, entity.DonationStatusPending, stored.Status)
+}
+
+func TestPaymentNotificationUnknownProvider(t *testing.T) {
+	env := NewEnv(t)
+
+	response := sendNotification(t, env, "midtrans", []byte(`{}`), "")
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+func TestPaymentStatusPolling(t *testing.T) {
+	env := NewEnv(t)
+	_, donation := CreateDonation(t, env)
+
+	// the donor paid but the callback never arrived
+	simulator := config.NewPaymentSimulator(env.Config, env.Redis, env.Log)
+	_, _, err := simulator.Notify(context.Background(), donation.ID, payment.StatusPaid)
+	assert.Nil(t, err)
+
+	found := getDonationPayment(t, env, donation.ID)
+	assert.Equal(t, entity.DonationStatusPaid, found.Status)
+}
+
+func TestRefundDonation(t *testing.T) {
+	env := NewEnv(t)
+	user, donation := CreateDonation(t, env)
+
+	request := httptest.NewRequest(http.MethodPost, "/api/users/_current/channel/donations/"+donation.ID+"/_refund", strings.NewReader(`{"reason":"salah nominal"}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", user.Token)
+
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusConflict, response.StatusCode)
+
+	response = SendSimulatorNotification(t, env, donation.ID, payment.StatusPaid)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	request = httptest.NewRequest(http.MethodPost, "/api/users/_current/channel/donations/"+donation.ID+"/_refund", strings.NewReader(`{"reason":"salah nominal"}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("Authorization", user.Token)
+
+	response, err = env.Test(request)
+	assert.Nil(t, err)
+
+	body, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+
+	responseBody := new(model.WebResponse[*model.DonationResponse])
+	err = json.Unmarshal(body, responseBody)
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, entity.DonationStatusRefunded, responseBody.Data.Status)
+	assert.Nil(t, responseBody.Data.Payment)
+}
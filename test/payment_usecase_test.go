@@ -0,0 +1,297 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"streamhelper-backend/internal/config"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/fake"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/payment"
+	"streamhelper-backend/internal/usecase"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakePaymentUseCase wires the donation and payment use cases to in-memory
+// fakes around one channel, "mousetri-live" owned by "Mousetri", which holds
+// messages with "anjing" for review.
+type fakePaymentUseCase struct {
+	Donations *usecase.DonationUseCase
+	UseCase   *usecase.PaymentUseCase
+	Stored    *fake.DonationRepository
+	Provider  *fake.PaymentProvider
+	Events    *fake.EventPublisher
+	Alerts    *fake.AlertEnqueuer
+	Speech    *fake.SpeechSubmitter
+	Goals     *fake.GoalTracker
+	Ranks     *fake.LeaderboardRecorder
+	Filtered  *fake.FilteredMessageRepository
+	TxManager *fake.TransactionManager
+}
+
+func newFakePaymentUseCase(t *testing.T) *fakePaymentUseCase {
+	t.Parallel()
+
+	log := logrus.New()
+	log.SetLevel(logrus.PanicLevel)
+	validate := config.NewValidator(nil)
+
+	channels := fake.NewChannelRepository(&entity.Channel{
+		ID:           "channel-1",
+		UserID:       "Mousetri",
+		Slug:         "mousetri-live",
+		Currency:     entity.DefaultCurrency,
+		MinDonation:  entity.DefaultMinDonation,
+		FilterAction: entity.FilterActionHold,
+	})
+	donations := fake.NewDonationRepository()
+	payments := fake.NewPaymentRepository()
+
+	f := &fakePaymentUseCase{
+		Stored:    donations,
+		Provider:  fake.NewPaymentProvider(),
+		Events:    fake.NewEventPublisher(),
+		Alerts:    fake.NewAlertEnqueuer(),
+		Speech:    fake.NewSpeechSubmitter(),
+		Goals:     fake.NewGoalTracker(),
+		Ranks:     fake.NewLeaderboardRecorder(),
+		Filtered:  fake.NewFilteredMessageRepository(),
+		TxManager: fake.NewTransactionManager(),
+	}
+	f.Donations = usecase.NewDonationUseCase(f.TxManager, log, validate, channels, donations, payments, f.Filtered, f.Provider,
+		fake.NewMessageFilter("anjing"), 15*time.Minute)
+	f.UseCase = usecase.NewPaymentUseCase(f.TxManager, log, validate, channels, donations, payments, f.Provider, f.Events, f.Alerts,
+		f.Speech, f.Goals, f.Ranks, nil)
+	return f
+}
+
+func (f *fakePaymentUseCase) donate(t *testing.T) *model.DonationResponse {
+	response, err := f.Donations.Create(context.Background(), &model.CreateDonationRequest{
+		Slug:          "mousetri-live",
+		DonorName:     "Nadia",
+		Amount:        25000,
+		PaymentMethod: payment.MethodGoPay,
+	})
+	assert.Nil(t, err)
+	return response
+}
+
+func (f *fakePaymentUseCase) notify(t *testing.T, notification *payment.Notification) error {
+	body, err := json.Marshal(notification)
+	assert.Nil(t, err)
+
+	_, err = f.UseCase.Notify(context.Background(), &model.PaymentNotificationRequest{
+		Provider: "fake",
+		Header:   http.Header{fake.FakeSignatureHeader: []string{"valid"}},
+		Body:     body,
+	})
+	return err
+}
+
+func TestUseCaseCreateDonationProviderDown(t *testing.T) {
+	f := newFakePaymentUseCase(t)
+	f.Provider.Err = errors.New("connection refused")
+
+	_, err := f.Donations.Create(context.Background(), &model.CreateDonationRequest{
+		Slug:          "mousetri-live",
+		DonorName:     "Nadia",
+		Amount:        25000,
+		PaymentMethod: payment.MethodQRIS,
+	})
+	assert.ErrorIs(t, err, model.ErrPaymentProvider)
+
+	// the donation was kept before the charge and failed with it
+	donations, total, err := f.Stored.Search(nil, "channel-1", &model.SearchDonationRequest{Page: 1, Size: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, entity.DonationStatusFailed, donations[0].Status)
+	assert.Equal(t, 2, f.TxManager.Committed)
+}
+
+func TestUseCaseNotifyPublishesDonation(t *testing.T) {
+	f := newFakePaymentUseCase(t)
+	donation := f.donate(t)
+
+	err := f.notify(t, &payment.Notification{OrderID: donation.ID, Status: payment.StatusPaid, Amount: 25000})
+	assert.Nil(t, err)
+
+	// a repeated callback changes nothing and publishes nothing
+	err = f.notify(t, &payment.Notification{OrderID: donation.ID, Status: payment.StatusPaid, Amount: 25000})
+	assert.Nil(t, err)
+
+	events := f.Events.Events()
+	assert.Len(t, events, 1)
+	assert.Equal(t, "channel-1", events[0].ChannelID)
+	assert.Equal(t, model.EventTypeDonation, events[0].Type)
+
+	published := new(model.DonationEvent)
+	assert.Nil(t, json.Unmarshal(events[0].Data, published))
+	assert.Equal(t, donation.ID, published.ID)
+	assert.Equal(t, "Nadia", published.DonorName)
+	assert.Equal(t, int64(25000), published.Amount)
+
+	alerts := f.Alerts.Requests()
+	assert.Len(t, alerts, 1)
+	assert.Equal(t, "channel-1", alerts[0].ChannelID)
+	assert.Equal(t, donation.ID, alerts[0].Data.GetId())
+}
+
+func TestUseCaseNotifyHeldDonation(t *testing.T) {
+	f := newFakePaymentUseCase(t)
+	donation, err := f.Donations.Create(context.Background(), &model.CreateDonationRequest{
+		Slug:          "mousetri-live",
+		DonorName:     "Nadia",
+		Message:       "dasar 4njiing",
+		Amount:        25000,
+		PaymentMethod: payment.MethodGoPay,
+	})
+	assert.Nil(t, err)
+	assert.True(t, donation.Held)
+	assert.Equal(t, "dasar 4njiing", donation.Message)
+
+	audit, total, err := f.Filtered.Search(nil, "channel-1", &model.SearchFilteredMessageRequest{Page: 1, Size: 10})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, entity.FilterActionHold, audit[0].Action)
+	assert.Equal(t, donation.ID, audit[0].DonationID)
+	assert.Equal(t, "dasar *******", audit[0].Filtered)
+
+	err = f.notify(t, &payment.Notification{OrderID: donation.ID, Status: payment.StatusPaid, Amount: 25000})
+	assert.Nil(t, err)
+
+	// the stream never sees the message, the alert waits for review with it
+	published := new(model.DonationEvent)
+	assert.Nil(t, json.Unmarshal(f.Events.Events()[0].Data, published))
+	assert.Empty(t, published.Message)
+
+	alerts := f.Alerts.Requests()
+	assert.Len(t, alerts, 1)
+	assert.True(t, alerts[0].Hold)
+	assert.Equal(t, "dasar 4njiing", alerts[0].Data.(*model.DonationEvent).Message)
+}
+
+func TestUseCaseNotifyAmountMismatch(t *testing.T) {
+	f := newFakePaymentUseCase(t)
+	donation := f.donate(t)
+
+	err := f.notify(t, &payment.Notification{OrderID: donation.ID, Status: payment.StatusPaid, Amount: 1000})
+	assert.NotNil(t, err)
+
+	response, err := f.UseCase.Get(context.Background(), &model.GetDonationPaymentRequest{DonationID: donation.ID})
+	assert.Nil(t, err)
+	assert.Equal(t, entity.DonationStatusPending, response.Status)
+}
+
+func TestUseCaseNotifyUnknownDonation(t *testing.T) {
+	f := newFakePaymentUseCase(t)
+
+	err := f.notify(t, &payment.Notification{OrderID: "missing", Status: payment.StatusPaid, Amount: 25000})
+	assert.ErrorIs(t, err, model.ErrDonationNotFound)
+}
+
+func TestUseCaseGetPaymentProviderDown(t *testing.T) {
+	f := newFakePaymentUseCase(t)
+	donation := f.donate(t)
+	f.Provider.Err = errors.New("connection refused")
+
+	response, err := f.UseCase.Get(context.Background(), &model.GetDonationPaymentRequest{DonationID: donation.ID})
+	assert.Nil(t, err)
+	assert.Equal(t, entity.DonationStatusPending, response.Status)
+	assert.Equal(t, payment.MethodGoPay, response.Payment.Method)
+}
+
+func TestUseCaseRefundOtherChannel(t *testing.T) {
+	f := newFakePaymentUseCase(t)
+	donation := f.donate(t)
+	f.Provider.SetStatus(donation.ID, payment.StatusPaid)
+
+	_, err := f.UseCase.Refund(context.Background(), &model.RefundDonationRequest{
+		UserID:     "Someone",
+		DonationID: donation.ID,
+	})
+	assert.ErrorIs(t, err, model.ErrChannelNotFound)
+}
+
+func TestUseCaseNotifySubmitsSpeech(t *testing.T) {
+	f := newFakePaymentUseCase(t)
+	f.Speech.Enabled = true
+
+	spoken, err := f.Donations.Create(context.Background(), &model.CreateDonationRequest{
+		Slug:          "mousetri-live",
+		DonorName:     "Nadia",
+		Message:       "semangat kak",
+		Amount:        25000,
+		PaymentMethod: payment.MethodGoPay,
+	})
+	assert.Nil(t, err)
+	silent := f.donate(t)
+
+	assert.Nil(t, f.notify(t, &payment.Notification{OrderID: spoken.ID, Status: payment.StatusPaid, Amount: 25000}))
+	assert.Nil(t, f.notify(t, &payment.Notification{OrderID: silent.ID, Status: payment.StatusPaid, Amount: 25000}))
+
+	// the speech job queues the alert of the message, nothing is read of
+	// a donation without one
+	requests := f.Speech.Requests()
+	assert.Len(t, requests, 1)
+	assert.Equal(t, spoken.ID, requests[0].DonationID)
+	assert.Equal(t, "channel-1", requests[0].ChannelID)
+
+	alerts := f.Alerts.Requests()
+	assert.Len(t, alerts, 1)
+	assert.Equal(t, silent.ID, alerts[0].Data.GetId())
+	assert.Len(t, f.Events.Events(), 2)
+}
+
+func TestUseCaseNotifyTracksGoalsAndLeaderboards(t *testing.T) {
+	f := newFakePaymentUseCase(t)
+	paid := f.donate(t)
+	failed := f.donate(t)
+
+	assert.Nil(t, f.notify(t, &payment.Notification{OrderID: paid.ID, Status: payment.StatusPaid, Amount: 25000}))
+	assert.Nil(t, f.notify(t, &payment.Notification{OrderID: failed.ID, Status: payment.StatusFailed, Amount: 25000}))
+	f.Provider.SetStatus(paid.ID, payment.StatusPaid)
+	_, err := f.UseCase.Refund(context.Background(), &model.RefundDonationRequest{
+		UserID:     "Mousetri",
+		DonationID: paid.ID,
+	})
+	assert.Nil(t, err)
+
+	// goals and leaderboards count the payment and drop it again on refund,
+	// a failed donation never counted
+	requests := f.Goals.Requests()
+	assert.Len(t, requests, 2)
+	assert.Equal(t, paid.ID, requests[0].DonationID)
+	assert.Equal(t, paid.ID, requests[1].DonationID)
+
+	ranks := f.Ranks.Requests()
+	assert.Len(t, ranks, 2)
+	assert.Equal(t, paid.ID, ranks[0].DonationID)
+	assert.Equal(t, paid.ID, ranks[1].DonationID)
+}
+
+func TestUseCaseNotifyRacesPoll(t *testing.T) {
+	f := newFakePaymentUseCase(t)
+	donation := f.donate(t)
+	f.Provider.SetStatus(donation.ID, payment.StatusPaid)
+
+	// the callback arrives while the tipping page polls, after the poll
+	// read the donation as pending
+	f.Provider.OnStatus = func(orderID string) {
+		assert.Nil(t, f.notify(t, &payment.Notification{OrderID: orderID, Status: payment.StatusPaid, Amount: 25000}))
+	}
+	response, err := f.UseCase.Get(context.Background(), &model.GetDonationPaymentRequest{DonationID: donation.ID})
+	assert.Nil(t, err)
+	assert.Equal(t, entity.DonationStatusPaid, response.Status)
+
+	// only one of them reports the payment
+	assert.Len(t, f.Alerts.Requests(), 1)
+	assert.Len(t, f.Ranks.Requests(), 1)
+	assert.Len(t, f.Goals.Requests(), 1)
+	assert.Len(t, f.Events.Events(), 1)
+}
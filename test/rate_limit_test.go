@@ -0,0 +1,50 @@
+package test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"streamhelper-backend/internal/config"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitSkipsProbes(t *testing.T) {
+	env := NewEnv(t)
+	rule := env.Config.RateLimit.Default
+
+	for i := 0; i <= rule.Anonymous; i++ {
+		response, err := env.Test(httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Empty(t, response.Header.Get("RateLimit-Limit"))
+	}
+}
+
+func TestProxyHeaderFromTrustedProxy(t *testing.T) {
+	env := NewEnv(t)
+
+	clientIP := func(trusted []string) string {
+		appConfig := *env.Config
+		appConfig.Web.ProxyHeader = "X-Real-IP"
+		appConfig.Web.TrustedProxies = trusted
+		app := config.NewFiber(&appConfig, env.Translator)
+		app.Get("/ip", func(ctx *fiber.Ctx) error {
+			return ctx.SendString(ctx.IP())
+		})
+
+		request := httptest.NewRequest(http.MethodGet, "/ip", nil)
+		request.Header.Set("X-Real-IP", "203.0.113.7")
+		response, err := app.Test(request, -1)
+		assert.Nil(t, err)
+		body, err := io.ReadAll(response.Body)
+		assert.Nil(t, err)
+		return string(body)
+	}
+
+	// app.Test connects from 0.0.0.0
+	assert.Equal(t, "203.0.113.7", clientIP([]string{"0.0.0.0"}))
+	assert.Equal(t, "0.0.0.0", clientIP([]string{"10.0.0.0/8"}))
+}
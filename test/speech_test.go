@@ -0,0 +1,154 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/fake"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/payment"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// paySpokenDonation enables text-to-speech and pays a donation with message.
+func paySpokenDonation(t *testing.T, env *Env, user *entity.User, message string) *model.DonationResponse {
+	response, _ := sendJSON[*model.SpeechSettingsResponse](t, env, user, http.MethodPatch, "/api/users/_current/channel/tts", `{"enabled":true}`)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	response, donation := DonateMessage(t, env, message)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	response = SendSimulatorNotification(t, env, donation.Data.ID, payment.StatusPaid)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	return donation.Data
+}
+
+func tickSpeech(t *testing.T, env *Env) {
+	err := env.Application.SpeechUseCase.Tick(context.Background(), time.Now())
+	assert.Nil(t, err)
+}
+
+func TestSpeechSettings(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+
+	response, settings := sendJSON[*model.SpeechSettingsResponse](t, env, user, http.MethodGet, "/api/users/_current/channel/tts", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.False(t, settings.Data.Enabled)
+	assert.Equal(t, entity.DefaultTTSLanguage, settings.Data.Language)
+	assert.Equal(t, entity.DefaultTTSMaxLength, settings.Data.MaxLength)
+
+	// a language brings its first voice, a voice brings its language
+	response, settings = sendJSON[*model.SpeechSettingsResponse](t, env, user, http.MethodPatch, "/api/users/_current/channel/tts", `{"enabled":true,"language":"en-US","max_length":120}`)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.True(t, settings.Data.Enabled)
+	assert.Equal(t, "en-US-stub-female", settings.Data.Voice)
+	assert.Equal(t, 120, settings.Data.MaxLength)
+
+	response, settings = sendJSON[*model.SpeechSettingsResponse](t, env, user, http.MethodPatch, "/api/users/_current/channel/tts", `{"voice":"id-ID-stub-male"}`)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "id-ID", settings.Data.Language)
+	assert.True(t, settings.Data.Enabled)
+
+	for _, body := range []string{`{"voice":"robot"}`, `{"language":"jv-ID"}`, `{"language":"en-US","voice":"id-ID-stub-male"}`} {
+		response, rejected := sendJSON[*model.SpeechSettingsResponse](t, env, user, http.MethodPatch, "/api/users/_current/channel/tts", body)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode, body)
+		assert.Equal(t, model.ErrCodeSpeechVoice, rejected.Code, body)
+	}
+
+	response, _ = sendJSON[*model.SpeechSettingsResponse](t, env, user, http.MethodPatch, "/api/users/_current/channel/tts", `{"max_length":5}`)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	response, voices := sendJSON[[]model.SpeechVoiceResponse](t, env, user, http.MethodGet, "/api/users/_current/channel/tts/voices", "")
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Len(t, voices.Data, 3)
+}
+
+func TestSpeechAlert(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+	message := strings.TrimSpace(strings.Repeat("semangat ", 12))
+	donation := paySpokenDonation(t, env, user, message)
+
+	// the alert waits for its audio
+	assert.Empty(t, getAlertQueue(t, env, user).Queue)
+
+	tickSpeech(t, env)
+	queue := getAlertQueue(t, env, user)
+	assert.Len(t, queue.Queue, 1)
+	assert.Equal(t, donation.ID, queue.Queue[0].ID)
+	assert.Equal(t, env.Config.Speech.BaseURL+"/"+donation.ID+".wav", queue.Queue[0].Display.SpeechURL)
+	// the stub takes 60ms per character, longer than the minimum duration
+	assert.Equal(t, int64(len(message)*60), queue.Queue[0].Duration)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/speech/"+donation.ID+".wav", nil)
+	response, err := env.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "audio/wav", response.Header.Get("Content-Type"))
+	body, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, "RIFF", string(body[:4]))
+
+	// the job is done, nothing is read twice
+	tickSpeech(t, env)
+	assert.Len(t, getAlertQueue(t, env, user).Queue, 1)
+}
+
+func TestSpeechMaxLength(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+
+	response, _ := sendJSON[*model.SpeechSettingsResponse](t, env, user, http.MethodPatch, "/api/users/_current/channel/tts", `{"max_length":20}`)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	synthesizer := fake.NewSynthesizer(nil)
+	env.Application.SpeechUseCase.Synthesizer = synthesizer
+
+	paySpokenDonation(t, env, user, strings.Repeat("semangat ", 12))
+	tickSpeech(t, env)
+
+	queue := getAlertQueue(t, env, user)
+	assert.NotEmpty(t, queue.Queue[0].Display.SpeechURL)
+	// "semangat semangat" is read, in less than the minimum duration
+	assert.Equal(t, int64(entity.DefaultAlertDuration*1000), queue.Queue[0].Duration)
+	assert.Equal(t, 1, synthesizer.Calls())
+}
+
+func TestSpeechFailure(t *testing.T) {
+	env := NewEnv(t)
+	user := CreateChannel(t, env)
+	synthesizer := fake.NewSynthesizer(errors.New("engine down"))
+	env.Application.SpeechUseCase.Synthesizer = synthesizer
+
+	donation := paySpokenDonation(t, env, user, "semangat kak")
+	for i := 1; i < env.Config.Speech.Attempts; i++ {
+		tickSpeech(t, env)
+		assert.Empty(t, getAlertQueue(t, env, user).Queue)
+	}
+
+	// after the last attempt the alert is shown without audio
+	tickSpeech(t, env)
+	queue := getAlertQueue(t, env, user)
+	assert.Len(t, queue.Queue, 1)
+	assert.Equal(t, donation.ID, queue.Queue[0].ID)
+	assert.Empty(t, queue.Queue[0].Display.SpeechURL)
+	assert.Equal(t, env.Config.Speech.Attempts, synthesizer.Calls())
+}
+
+func TestSpeechAudioNotFound(t *testing.T) {
+	env := NewEnv(t)
+
+	for _, name := range []string{"missing.wav", "..%2Fconfig.json", ".upload-1"} {
+		request := httptest.NewRequest(http.MethodGet, "/api/speech/"+name, nil)
+		response, err := env.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNotFound, response.StatusCode, name)
+	}
+}
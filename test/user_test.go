@@ -15,7 +15,11 @@ import (
 )
 
 func TestRegister(t *testing.T){
-	ClearAll()
+	RegisterUser(t, NewEnv(t))
+}
+
+// RegisterUser registers Mousetri through the API.
+func RegisterUser(t *testing.T, env *Env){
 	requestBody := model.RegisterUserRequest{
 		ID: "Mousetri",
 		Password: "hayolo",
@@ -29,7 +33,7 @@ func TestRegister(t *testing.T){
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("Accept", "application/json")
 	
-	response, err := App.Test(request)
+	response, err := env.Test(request)
 	assert.Nil(t, err)
 
 	bytes, err := io.ReadAll(response.Body)
@@ -49,7 +53,7 @@ func TestRegister(t *testing.T){
 }
 
 func TestRegisterError(t *testing.T){
-	ClearAll()
+	env := NewEnv(t)
 	requestBody := model.RegisterUserRequest{
 		ID: "",
 		Password: "",
@@ -63,7 +67,7 @@ func TestRegisterError(t *testing.T){
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("Accept", "application/json")
 
-	response, err := App.Test(request)
+	response, err := env.Test(request)
 	assert.Nil(t, err)
 
 	bytes, err := io.ReadAll(response.Body)
@@ -78,8 +82,8 @@ func TestRegisterError(t *testing.T){
 }
 
 func TestRegisterDuplicate(t *testing.T){
-	ClearAll()
-	TestRegister(t)
+	env := NewEnv(t)
+	RegisterUser(t, env)
 
 	requestBody := model.RegisterUserRequest{
 		ID: "Mousetri",
@@ -95,7 +99,7 @@ func TestRegisterDuplicate(t *testing.T){
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("Accept", "application/json")
 	
-	response, err := App.Test(request)
+	response, err := env.Test(request)
 	assert.Nil(t, err)
 
 	bytes, err := io.ReadAll(response.Body)
@@ -111,7 +115,12 @@ func TestRegisterDuplicate(t *testing.T){
 }
 
 func TestLogin(t *testing.T){
-	TestRegister(t)
+	LoginUser(t, NewEnv(t))
+}
+
+// LoginUser registers Mousetri and logs in, leaving the token in the database.
+func LoginUser(t *testing.T, env *Env){
+	RegisterUser(t, env)
 
 	requestBody := model.LoginUserRequest{
 		ID: "Mousetri",
@@ -125,7 +134,7 @@ func TestLogin(t *testing.T){
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("Accept", "application/json")
 
-	response, err := App.Test(request)
+	response, err := env.Test(request)
 	assert.Nil(t, err)
 
 	bytes, err := io.ReadAll(response.Body)
@@ -139,14 +148,14 @@ func TestLogin(t *testing.T){
 	assert.NotNil(t, responseBody.Data.Token)
 
 	user := new(entity.User)
-	err = DB.Where("id = ? ", requestBody.ID).First(user).Error
+	err = env.DB.Where("id = ? ", requestBody.ID).First(user).Error
 	assert.Nil(t, err)
 	assert.Equal(t, user.Token, responseBody.Data.Token)
 }
 
 func TestLoginWrongUsername(t *testing.T){
-	ClearAll()
-	TestRegister(t)
+	env := NewEnv(t)
+	RegisterUser(t, env)
 
 	requestBody := model.LoginUserRequest{
 		ID: "Mouse",
@@ -160,7 +169,7 @@ func TestLoginWrongUsername(t *testing.T){
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("Accept", "application/json")
 
-	response, err := App.Test(request)
+	response, err := env.Test(request)
 	assert.Nil(t, err)
 
 	bytes, err := io.ReadAll(response.Body)
@@ -175,8 +184,8 @@ func TestLoginWrongUsername(t *testing.T){
 }
 
 func TestLoginWrongPassword(t *testing.T){
-	ClearAll()
-	TestRegister(t)
+	env := NewEnv(t)
+	RegisterUser(t, env)
 
 	requestBody := model.LoginUserRequest{
 		ID: "Mousetri",
@@ -190,7 +199,7 @@ func TestLoginWrongPassword(t *testing.T){
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("Accept", "application/json")
 
-	response, err := App.Test(request)
+	response, err := env.Test(request)
 	assert.Nil(t, err)
 
 	bytes, err := io.ReadAll(response.Body)
@@ -205,11 +214,11 @@ func TestLoginWrongPassword(t *testing.T){
 }
 
 func TestLogout(t *testing.T){
-	ClearAll()
-	TestLogin(t)
+	env := NewEnv(t)
+	LoginUser(t, env)
 
 	user := new(entity.User)
-	err := DB.Where("id = ?", "Mousetri").First(user).Error
+	err := env.DB.Where("id = ?", "Mousetri").First(user).Error
 	assert.Nil(t, err)
 
 	request := httptest.NewRequest(http.MethodDelete, "/api/users", nil)
@@ -217,7 +226,7 @@ func TestLogout(t *testing.T){
 	request.Header.Set("Accept", "application/json")
 	request.Header.Set("Authorization", user.Token)
 
-	response , err := App.Test(request)
+	response , err := env.Test(request)
 	assert.Nil(t, err)
 
 	bytes, err := io.ReadAll(response.Body)
@@ -232,15 +241,15 @@ func TestLogout(t *testing.T){
 }
 
 func TestLogoutWrongAuthorization(t *testing.T){
-	ClearAll()
-	TestLogin(t)
+	env := NewEnv(t)
+	LoginUser(t, env)
 
 	request := httptest.NewRequest(http.MethodDelete, "/api/users", nil)
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("Accept", "application/json")
 	request.Header.Set("Authorization", "wrong")
 
-	response, err := App.Test(request)
+	response, err := env.Test(request)
 	assert.Nil(t, err)
 
 	bytes, err := io.ReadAll(response.Body)
@@ -256,11 +265,11 @@ func TestLogoutWrongAuthorization(t *testing.T){
 
 
 func TestGetCurrentUser(t *testing.T){
-	ClearAll()
-	TestLogin(t)
+	env := NewEnv(t)
+	LoginUser(t, env)
 
 	user := new(entity.User)
-	err := DB.Where("id = ?", "Mousetri").First(user).Error
+	err := env.DB.Where("id = ?", "Mousetri").First(user).Error
 	assert.Nil(t, err)
 
 	request := httptest.NewRequest(http.MethodGet, "/api/users/_current", nil)
@@ -268,7 +277,7 @@ func TestGetCurrentUser(t *testing.T){
 	request.Header.Set("Accept", "application/json")
 	request.Header.Set("Authorization", user.Token)
 
-	response, err := App.Test(request)
+	response, err := env.Test(request)
 	assert.Nil(t, err)
 
 	bytes, err := io.ReadAll(response.Body)
@@ -287,15 +296,15 @@ func TestGetCurrentUser(t *testing.T){
 }
 
 func TestGetCurrentUserFailed(t *testing.T){
-	ClearAll()
-	TestLogin(t)
+	env := NewEnv(t)
+	LoginUser(t, env)
 
 	request := httptest.NewRequest(http.MethodGet, "/api/users/_current", nil)
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("Accept", "application/json")
 	request.Header.Set("Authorization", "wrong")
 
-	response, err := App.Test(request)
+	response, err := env.Test(request)
 	assert.Nil(t, err)
 
 	bytes, err := io.ReadAll(response.Body)
@@ -310,11 +319,11 @@ func TestGetCurrentUserFailed(t *testing.T){
 }
 
 func TestUpdateUserName(t *testing.T){
-	ClearAll()
-	TestLogin(t)
+	env := NewEnv(t)
+	LoginUser(t, env)
 
 	user := new(entity.User)
-	err := DB.Where("id = ?", "Mousetri").First(user).Error
+	err := env.DB.Where("id = ?", "Mousetri").First(user).Error
 	assert.Nil(t, err)
 
 	requestBody := model.UpdateUserRequest{
@@ -329,7 +338,7 @@ func TestUpdateUserName(t *testing.T){
 	request.Header.Set("Accept", "application/json")
 	request.Header.Set("Authorization", user.Token)
 
-	response, err := App.Test(request)
+	response, err := env.Test(request)
 	assert.Nil(t, err)
 
 	bytes, err := io.ReadAll(response.Body)
@@ -347,11 +356,11 @@ func TestUpdateUserName(t *testing.T){
 }
 
 func TestUpdateUserPassword(t *testing.T){
-	ClearAll()
-	TestLogin(t)
+	env := NewEnv(t)
+	LoginUser(t, env)
 
 	user := new(entity.User)
-	err := DB.Where("id = ?", "Mousetri").First(user).Error
+	err := env.DB.Where("id = ?", "Mousetri").First(user).Error
 	assert.Nil(t, err)
 
 	requestBody := model.UpdateUserRequest{
@@ -366,7 +375,7 @@ func TestUpdateUserPassword(t *testing.T){
 	request.Header.Set("Accept", "application/json")
 	request.Header.Set("Authorization", user.Token)
 
-	response, err := App.Test(request)
+	response, err := env.Test(request)
 	assert.Nil(t, err)
 
 	bytes, err := io.ReadAll(response.Body)
@@ -382,7 +391,7 @@ func TestUpdateUserPassword(t *testing.T){
 	assert.NotNil(t, responseBody.Data.UpdatedAt)
 
 	user = new(entity.User)
-	err = DB.Where("id = ? ", "Mousetri").First(user).Error
+	err = env.DB.Where("id = ? ", "Mousetri").First(user).Error
 	assert.Nil(t, err)
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(requestBody.Password))
 	assert.Nil(t, err)
@@ -390,8 +399,8 @@ func TestUpdateUserPassword(t *testing.T){
 
 
 func TestUpdateFailed(t *testing.T) {
-	ClearAll()
-	TestLogin(t)
+	env := NewEnv(t)
+	LoginUser(t, env)
 
 	requestBody := model.UpdateUserRequest{
 		Password: "rahasialagi",
@@ -405,7 +414,7 @@ func TestUpdateFailed(t *testing.T) {
 	request.Header.Set("Accept", "application/json")
 	request.Header.Set("Authorization", "wrong")
 
-	response, err := App.Test(request)
+	response, err := env.Test(request)
 	assert.Nil(t, err)
 
 	bytes, err := io.ReadAll(response.Body)
@@ -0,0 +1,149 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"streamhelper-backend/internal/config"
+	"streamhelper-backend/internal/entity"
+	"streamhelper-backend/internal/fake"
+	"streamhelper-backend/internal/model"
+	"streamhelper-backend/internal/usecase"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeUserUseCase wires UserUseCase to in-memory fakes, no database or
+// Redis involved.
+type fakeUserUseCase struct {
+	UseCase   *usecase.UserUseCase
+	Users     *fake.UserRepository
+	Tokens    *fake.TokenService
+	TxManager *fake.TransactionManager
+}
+
+func newFakeUserUseCase(t *testing.T, users ...*entity.User) *fakeUserUseCase {
+	t.Parallel()
+
+	log := logrus.New()
+	log.SetLevel(logrus.PanicLevel)
+
+	f := &fakeUserUseCase{
+		Users:     fake.NewUserRepository(users...),
+		Tokens:    fake.NewTokenService(),
+		TxManager: fake.NewTransactionManager(),
+	}
+	f.UseCase = usecase.NewUserUserCase(f.TxManager, log, config.NewValidator(nil), f.Users, f.Tokens, fake.NewPasswordHasher(), nil)
+	return f
+}
+
+func TestUseCaseCreateUser(t *testing.T) {
+	f := newFakeUserUseCase(t)
+
+	response, err := f.UseCase.Create(context.Background(), &model.RegisterUserRequest{
+		ID:       "Mousetri",
+		Password: "hayolo",
+		Name:     "Mousetri janedy",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "Mousetri", response.ID)
+	assert.Equal(t, 1, f.TxManager.Committed)
+
+	user, ok := f.Users.Get("Mousetri")
+	assert.True(t, ok)
+	assert.Equal(t, "hashed:hayolo", user.Password)
+}
+
+func TestUseCaseCreateDuplicateUser(t *testing.T) {
+	f := newFakeUserUseCase(t, &entity.User{ID: "Mousetri"})
+
+	_, err := f.UseCase.Create(context.Background(), &model.RegisterUserRequest{
+		ID:       "Mousetri",
+		Password: "hayolo",
+		Name:     "Mousetri janedy",
+	})
+	assert.ErrorIs(t, err, model.ErrUserAlreadyExists)
+	assert.Equal(t, 0, f.TxManager.Committed)
+	assert.Equal(t, 1, f.TxManager.RolledBack)
+}
+
+func TestUseCaseCreateInvalidUser(t *testing.T) {
+	f := newFakeUserUseCase(t)
+
+	_, err := f.UseCase.Create(context.Background(), &model.RegisterUserRequest{})
+
+	appError := new(model.AppError)
+	assert.True(t, errors.As(err, &appError))
+	assert.Equal(t, model.ErrCodeValidation, appError.Code)
+	assert.NotEmpty(t, appError.Fields)
+}
+
+func TestUseCaseLogin(t *testing.T) {
+	f := newFakeUserUseCase(t, &entity.User{ID: "Mousetri", Password: "hashed:hayolo"})
+
+	response, err := f.UseCase.Login(context.Background(), &model.LoginUserRequest{ID: "Mousetri", Password: "hayolo"})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, response.Token)
+
+	auth, err := f.Tokens.ParseToken(context.Background(), response.Token)
+	assert.Nil(t, err)
+	assert.Equal(t, "Mousetri", auth.ID)
+}
+
+func TestUseCaseLoginWrongPassword(t *testing.T) {
+	f := newFakeUserUseCase(t, &entity.User{ID: "Mousetri", Password: "hashed:hayolo"})
+
+	_, err := f.UseCase.Login(context.Background(), &model.LoginUserRequest{ID: "Mousetri", Password: "hayo"})
+	assert.ErrorIs(t, err, model.ErrInvalidCredentials)
+}
+
+func TestUseCaseLoginDisabledUser(t *testing.T) {
+	f := newFakeUserUseCase(t, &entity.User{ID: "Mousetri", Password: "hashed:hayolo", DisabledAt: 1})
+
+	_, err := f.UseCase.Login(context.Background(), &model.LoginUserRequest{ID: "Mousetri", Password: "hayolo"})
+	assert.ErrorIs(t, err, model.ErrUserDisabled)
+}
+
+func TestUseCaseDisableRevokesTokens(t *testing.T) {
+	f := newFakeUserUseCase(t, &entity.User{ID: "Mousetri", Password: "hashed:hayolo"})
+
+	response, err := f.UseCase.Login(context.Background(), &model.LoginUserRequest{ID: "Mousetri", Password: "hayolo"})
+	assert.Nil(t, err)
+
+	_, err = f.UseCase.Disable(context.Background(), &model.DisableUserRequest{ID: "Mousetri"})
+	assert.Nil(t, err)
+
+	_, err = f.Tokens.ParseToken(context.Background(), response.Token)
+	assert.NotNil(t, err)
+
+	user, _ := f.Users.Get("Mousetri")
+	assert.True(t, user.Disabled())
+}
+
+func TestUseCaseDisableKeepsTokensWhenCommitFails(t *testing.T) {
+	f := newFakeUserUseCase(t, &entity.User{ID: "Mousetri", Password: "hashed:hayolo"})
+
+	response, err := f.UseCase.Login(context.Background(), &model.LoginUserRequest{ID: "Mousetri", Password: "hayolo"})
+	assert.Nil(t, err)
+
+	f.TxManager.CommitErr = errors.New("connection lost")
+	_, err = f.UseCase.Disable(context.Background(), &model.DisableUserRequest{ID: "Mousetri"})
+	assert.NotNil(t, err)
+
+	_, err = f.UseCase.ResetPassword(context.Background(), &model.ResetPasswordRequest{ID: "Mousetri", Password: "rahasia123"})
+	assert.NotNil(t, err)
+
+	_, err = f.Tokens.ParseToken(context.Background(), response.Token)
+	assert.Nil(t, err)
+}
+
+func TestUseCaseUpdatePassword(t *testing.T) {
+	f := newFakeUserUseCase(t, &entity.User{ID: "Mousetri", Password: "hashed:hayolo"})
+
+	_, err := f.UseCase.Update(context.Background(), &model.UpdateUserRequest{ID: "Mousetri", Password: "rahasia"})
+	assert.Nil(t, err)
+
+	user, _ := f.Users.Get("Mousetri")
+	assert.Equal(t, "hashed:rahasia", user.Password)
+}
@@ -0,0 +1,25 @@
+package test
+
+import (
+	"streamhelper-backend/internal/config"
+	"streamhelper-backend/internal/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidationFieldMessages(t *testing.T) {
+	t.Parallel()
+
+	request := struct {
+		Name   string   `validate:"min=3"`
+		Amount int64    `validate:"max=100"`
+		Tags   []string `validate:"max=2"`
+	}{Name: "ab", Amount: 500, Tags: []string{"a", "b", "c"}}
+
+	appError := model.NewValidationError(config.NewValidator(nil).Struct(request))
+	assert.Len(t, appError.Fields, 3)
+	assert.Equal(t, "Name must be at least 3 characters", appError.Fields[0].Message)
+	assert.Equal(t, "Amount must be at most 100", appError.Fields[1].Message)
+	assert.Equal(t, "Tags must be at most 2 items", appError.Fields[2].Message)
+}